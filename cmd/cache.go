@@ -0,0 +1,81 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/konveyor/move2kube/environment"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// allFlag is the name of the flag that tells us to remove everything in the cache
+	allFlag = "all"
+	// unusedForFlag is the name of the flag that contains the duration for which the cached items have to be unused to be removed
+	unusedForFlag = "unused-for"
+)
+
+type cachePruneFlags struct {
+	// all removes all the cached items
+	all bool
+	// unusedFor is the duration for which the cached items have to be unused to be removed
+	unusedFor time.Duration
+}
+
+func cachePruneHandler(flags cachePruneFlags) {
+	unusedFor := flags.unusedFor
+	if flags.all {
+		unusedFor = 0
+	} else if unusedFor <= 0 {
+		logrus.Fatalf("The duration given using --%s must be positive. Use --%s to remove all the cached items.", unusedForFlag, allFlag)
+	}
+	removedImages, err := environment.PruneTransformerImages(unusedFor)
+	for _, image := range removedImages {
+		logrus.Infof("Removed the transformer image %s", image)
+	}
+	if err != nil {
+		logrus.Fatalf("Failed to prune the transformer images. Error: %q", err)
+	}
+	logrus.Infof("Removed %d transformer images", len(removedImages))
+}
+
+// GetCacheCommand returns a command to work with the items cached across runs
+func GetCacheCommand() *cobra.Command {
+	viper.AutomaticEnv()
+
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Work with the items cached across runs",
+		Long:  "Work with the items cached across runs, like the transformer images built from build contexts",
+	}
+
+	flags := cachePruneFlags{}
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the cached transformer images",
+		Long:  "Remove the transformer images built from build contexts which were not used recently",
+		Run:   func(*cobra.Command, []string) { cachePruneHandler(flags) },
+	}
+	pruneCmd.Flags().BoolVar(&flags.all, allFlag, false, "Remove all the cached transformer images.")
+	pruneCmd.Flags().DurationVar(&flags.unusedFor, unusedForFlag, 7*24*time.Hour, "Remove the cached transformer images which were not used for this duration.")
+
+	cacheCmd.AddCommand(pruneCmd)
+	return cacheCmd
+}
@@ -0,0 +1,79 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type configShowFlags struct {
+	// configs contains a list of config files
+	configs []string
+	// setconfigs contains a list of key-value configs
+	setconfigs []string
+	// preSets contains a list of preset configurations
+	preSets []string
+	// profile contains the name of the config profile
+	profile string
+	// customizationsPath contains the path to the customizations directory
+	customizationsPath string
+}
+
+func configShowHandler(flags configShowFlags) {
+	config, err := qaengine.GetEffectiveConfig(flags.setconfigs, flags.configs, flags.preSets, flags.profile, flags.customizationsPath)
+	if err != nil {
+		logrus.Fatalf("Failed to get the effective config. Error: %q", err)
+	}
+	configBytes, err := common.ObjectToYamlBytes(config)
+	if err != nil {
+		logrus.Fatalf("Failed to convert the effective config to yaml. Error: %q", err)
+	}
+	fmt.Print(string(configBytes))
+}
+
+// GetConfigCommand returns a command to work with the configs used to answer questions
+func GetConfigCommand() *cobra.Command {
+	viper.AutomaticEnv()
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Work with the configs used to answer questions",
+		Long:  "Work with the presets, config profiles, config files and config strings used to answer questions",
+	}
+
+	flags := configShowFlags{}
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective config",
+		Long:  "Show the config that results from merging the presets, the config profile along with the profiles it extends, the config files and the config strings",
+		Run:   func(*cobra.Command, []string) { configShowHandler(flags) },
+	}
+	showCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations.")
+	showCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
+	showCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
+	showCmd.Flags().StringVar(&flags.profile, profileFlag, "", "Specify the config profile to use. The profiles it extends are also used.")
+	showCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored.")
+
+	configCmd.AddCommand(showCmd)
+	return configCmd
+}
@@ -16,6 +16,8 @@
 
 package cmd
 
+import "time"
+
 const (
 	// sourceFlag is the name of the flag that contains path to the source folder
 	sourceFlag = "source"
@@ -41,12 +43,16 @@ const (
 	setConfigFlag = "set-config"
 	// preSetFlag is the name of the flag that contains list of preset configurations to use
 	preSetFlag = "preset"
+	// profileFlag is the name of the flag that contains the name of the config profile to use
+	profileFlag = "profile"
 	// overwriteFlag is the name of the flag that lets you overwrite the output directory if it exists
 	overwriteFlag = "overwrite"
 	// customizationsFlag is the path to customizations directory
 	customizationsFlag      = "customizations"
 	qadisablecliFlag        = "qa-disable-cli"
 	qaportFlag              = "qa-port"
+	qagrpcFlag              = "qa-grpc"
+	qadeadlineFlag          = "qa-deadline"
 	planProgressPortFlag    = "plan-progress-port"
 	transformerSelectorFlag = "transformer-selector"
 )
@@ -54,6 +60,10 @@ const (
 type qaflags struct {
 	qadisablecli bool
 	qaport       int
+	// qagrpc serves the questions over GRPC instead of REST when the CLI is disabled
+	qagrpc bool
+	// qadeadline is the time to wait for a remote answer before using the default
+	qadeadline time.Duration
 	// configOut contains the location to output the config
 	configOut string
 	// qaCacheOut contains the location to output the cache
@@ -66,6 +76,8 @@ type qaflags struct {
 	qaskip bool
 	// preSets contains a list of preset configurations
 	preSets []string
+	// profile contains the name of the config profile
+	profile string
 	// persistPasswords sets whether to persist the password or not
 	persistPasswords bool
 }
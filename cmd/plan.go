@@ -33,25 +33,32 @@ import (
 )
 
 type planFlags struct {
-	progressServerPort    int
-	planfile              string
-	srcpath               string
-	name                  string
-	customizationsPath    string
-	transformerSelector   string
-	disableLocalExecution bool
+	progressServerPort       int
+	planfile                 string
+	srcpath                  string
+	name                     string
+	customizationsPath       string
+	transformerSelector      string
+	disableLocalExecution    bool
+	sandboxLocalExecution    bool
+	rebuildTransformerImages bool
+	recordEnvironments       string
+	replayEnvironments       string
 	//Configs contains a list of config files
 	configs []string
 	//Configs contains a list of key-value configs
 	setconfigs []string
 	//PreSets contains a list of preset configurations
 	preSets []string
+	//Profile contains the name of the config profile
+	profile string
 }
 
 func planHandler(cmd *cobra.Command, flags planFlags) {
 	ctx, cancel := context.WithCancel(cmd.Context())
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
+	logrus.AddHook(common.NewRedactHook())
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
 	go func() {
 		<-ctx.Done()
@@ -69,6 +76,9 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 
 	// Global settings
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.SandboxLocalExecution = flags.sandboxLocalExecution
+	common.RebuildTransformerImages = flags.rebuildTransformerImages
+	setEnvironmentRecordingPaths(flags.recordEnvironments, flags.replayEnvironments)
 	// Global settings
 
 	planfile, err = filepath.Abs(planfile)
@@ -98,8 +108,8 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	} else if fi.IsDir() {
 		planfile = filepath.Join(planfile, common.DefaultPlanFile)
 	}
-	qaengine.StartEngine(true, 0, true)
-	qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, false)
+	qaengine.StartEngine(true, 0, true, false, 0)
+	qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, false)
 	if flags.progressServerPort != 0 {
 		startPlanProgressServer(flags.progressServerPort)
 	}
@@ -135,9 +145,14 @@ func GetPlanCommand() *cobra.Command {
 	planCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations.")
 	planCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
 	planCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
+	planCmd.Flags().StringVar(&flags.profile, profileFlag, "", "Specify the config profile to use. The profiles it extends are also used.")
 	planCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
 	planCmd.Flags().IntVar(&flags.progressServerPort, planProgressPortFlag, 0, "Port for the plan progress server. If not provided, the server won't be started.")
 	planCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	planCmd.Flags().BoolVar(&flags.sandboxLocalExecution, common.SandboxLocalExecutionFlag, false, "Run the files executed locally in a sandbox without network access and with read only access to the source. Only supported on Linux.")
+	planCmd.Flags().BoolVar(&flags.rebuildTransformerImages, common.RebuildTransformerImagesFlag, false, "Rebuild the images of the transformers which specify a build context instead of using the images built in earlier runs.")
+	planCmd.Flags().StringVar(&flags.recordEnvironments, common.RecordEnvironmentsFlag, "", "Record the commands run by the transformers, their outputs and the files they produce into this directory.")
+	planCmd.Flags().StringVar(&flags.replayEnvironments, common.ReplayEnvironmentsFlag, "", "Replay the commands recorded using --"+common.RecordEnvironmentsFlag+" from this directory instead of running them.")
 
 	must(planCmd.MarkFlagRequired(sourceFlag))
 	must(planCmd.Flags().MarkHidden(planProgressPortFlag))
@@ -65,6 +65,8 @@ func GetRootCmd() *cobra.Command {
 	rootCmd.AddCommand(GetCollectCommand())
 	rootCmd.AddCommand(GetPlanCommand())
 	rootCmd.AddCommand(GetTransformCommand())
+	rootCmd.AddCommand(GetConfigCommand())
+	rootCmd.AddCommand(GetCacheCommand())
 	rootCmd.AddCommand(GetGenerateDocsCommand())
 	return rootCmd
 }
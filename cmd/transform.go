@@ -21,9 +21,12 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
 	"github.com/konveyor/move2kube/types/plan"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -36,6 +39,18 @@ type transformFlags struct {
 	ignoreEnv bool
 	// disableLocalExecution disables execution of executables locally
 	disableLocalExecution bool
+	// sandboxLocalExecution runs the local executables in a sandbox
+	sandboxLocalExecution bool
+	// recordEnvironments is the directory into which the interactions with the environments are recorded
+	recordEnvironments string
+	// replayEnvironments is the directory from which the recorded interactions with the environments are replayed
+	replayEnvironments string
+	// rebuildTransformerImages rebuilds the transformer images instead of using the cached images
+	rebuildTransformerImages bool
+	// resourceProfile is the profile used for the default resources of the containers
+	resourceProfile string
+	// podSecurity is the pod security level the pods should comply with
+	podSecurity string
 	// planfile is contains the path to the plan file
 	planfile string
 	// outpath contains the path to the output folder
@@ -55,6 +70,7 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	ctx, cancel := context.WithCancel(cmd.Context())
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
+	logrus.AddHook(common.NewRedactHook())
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
 	go func() {
 		<-ctx.Done()
@@ -80,6 +96,17 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	// Global settings
 	common.IgnoreEnvironment = flags.ignoreEnv
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.SandboxLocalExecution = flags.sandboxLocalExecution
+	common.RebuildTransformerImages = flags.rebuildTransformerImages
+	if flags.resourceProfile != "" && !common.IsStringPresent(irpreprocessor.ResourceProfiles, flags.resourceProfile) {
+		logrus.Fatalf("Invalid resource profile %s. Valid profiles are %s", flags.resourceProfile, strings.Join(irpreprocessor.ResourceProfiles, ", "))
+	}
+	common.ResourceProfile = flags.resourceProfile
+	if flags.podSecurity != "" && !common.IsStringPresent(common.PodSecurityLevels, flags.podSecurity) {
+		logrus.Fatalf("Invalid pod security level %s. Valid levels are %s", flags.podSecurity, strings.Join(common.PodSecurityLevels, ", "))
+	}
+	common.PodSecurity = flags.podSecurity
+	setEnvironmentRecordingPaths(flags.recordEnvironments, flags.replayEnvironments)
 	// Global settings
 
 	// Parameter cleaning and curate plan
@@ -108,7 +135,8 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		if err := os.MkdirAll(flags.outpath, common.DefaultDirectoryPermission); err != nil {
 			logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
 		}
-		startQA(flags.qaflags)
+		startQA(flags.qaflags, flags.customizationsPath)
+		qaengine.SetupAuditFile(filepath.Join(flags.outpath, common.QAAuditFile))
 		logrus.Debugf("Creating a new plan.")
 		p = lib.CreatePlan(ctx, flags.srcpath, flags.outpath, flags.customizationsPath, flags.transformerSelector, flags.name)
 	} else {
@@ -146,7 +174,8 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		if err := os.MkdirAll(flags.outpath, common.DefaultDirectoryPermission); err != nil {
 			logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
 		}
-		startQA(flags.qaflags)
+		startQA(flags.qaflags, p.Spec.CustomizationsDir)
+		qaengine.SetupAuditFile(filepath.Join(flags.outpath, common.QAAuditFile))
 	}
 	lib.Transform(ctx, p, flags.outpath, flags.transformerSelector)
 	logrus.Infof("Transformed target artifacts can be found at [%s].", flags.outpath)
@@ -180,6 +209,7 @@ func GetTransformCommand() *cobra.Command {
 	transformCmd.Flags().StringVar(&flags.qaCacheOut, qaCacheOutFlag, ".", "Specify cache file output location.")
 	transformCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations.")
 	transformCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
+	transformCmd.Flags().StringVar(&flags.profile, profileFlag, "", "Specify the config profile to use. The profiles it extends are also used.")
 	transformCmd.Flags().BoolVar(&flags.persistPasswords, qaPersistPasswords, false, "Stores passwords too in the config.")
 	transformCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
 	transformCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored.")
@@ -189,13 +219,23 @@ func GetTransformCommand() *cobra.Command {
 	// Advanced options
 	transformCmd.Flags().BoolVar(&flags.ignoreEnv, ignoreEnvFlag, false, "Ignore data from local machine.")
 	transformCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
+	transformCmd.Flags().BoolVar(&flags.sandboxLocalExecution, common.SandboxLocalExecutionFlag, false, "Run the files executed locally in a sandbox without network access and with read only access to the source. Only supported on Linux.")
+	transformCmd.Flags().BoolVar(&flags.rebuildTransformerImages, common.RebuildTransformerImagesFlag, false, "Rebuild the images of the transformers which specify a build context instead of using the images built in earlier runs.")
+	transformCmd.Flags().StringVar(&flags.resourceProfile, common.ResourceProfileFlag, "", "Specify the profile used for the default resource requests and limits of the containers. Valid profiles are "+strings.Join(irpreprocessor.ResourceProfiles, ", ")+". By default only the resources found in the source are used.")
+	transformCmd.Flags().StringVar(&flags.podSecurity, common.PodSecurityFlag, "", "Specify the pod security level the pods should comply with. Valid levels are "+strings.Join(common.PodSecurityLevels, ", ")+". The pods are hardened for the level and the violations are reported. By default the pods are not validated.")
+	transformCmd.Flags().StringVar(&flags.recordEnvironments, common.RecordEnvironmentsFlag, "", "Record the commands run by the transformers, their outputs and the files they produce into this directory.")
+	transformCmd.Flags().StringVar(&flags.replayEnvironments, common.ReplayEnvironmentsFlag, "", "Replay the commands recorded using --"+common.RecordEnvironmentsFlag+" from this directory instead of running them.")
 
 	// Hidden options
 	transformCmd.Flags().BoolVar(&flags.qadisablecli, qadisablecliFlag, false, "Enable/disable the QA Cli sub-system. Without this system, you will have to use the REST API to interact.")
 	transformCmd.Flags().IntVar(&flags.qaport, qaportFlag, 0, "Port for the QA service. By default it chooses a random free port.")
+	transformCmd.Flags().BoolVar(&flags.qagrpc, qagrpcFlag, false, "Stream the questions to a remote client over GRPC instead of using the REST API. Requires the QA Cli sub-system to be disabled.")
+	transformCmd.Flags().DurationVar(&flags.qadeadline, qadeadlineFlag, 0, "Time to wait for a remote client to answer a question before using the default answer. By default it waits forever.")
 
 	must(transformCmd.Flags().MarkHidden(qadisablecliFlag))
 	must(transformCmd.Flags().MarkHidden(qaportFlag))
+	must(transformCmd.Flags().MarkHidden(qagrpcFlag))
+	must(transformCmd.Flags().MarkHidden(qadeadlineFlag))
 
 	return transformCmd
 }
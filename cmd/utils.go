@@ -51,6 +51,24 @@ func checkSourcePath(srcpath string) {
 	}
 }
 
+// setEnvironmentRecordingPaths sets the directories used to record and replay the interactions with the environments
+func setEnvironmentRecordingPaths(recordPath, replayPath string) {
+	if recordPath != "" && replayPath != "" {
+		logrus.Fatalf("The flags %s and %s cannot be used together.", common.RecordEnvironmentsFlag, common.ReplayEnvironmentsFlag)
+	}
+	var err error
+	if recordPath != "" {
+		if common.RecordEnvironmentsPath, err = filepath.Abs(recordPath); err != nil {
+			logrus.Fatalf("Failed to make the recording directory path %q absolute. Error: %q", recordPath, err)
+		}
+	}
+	if replayPath != "" {
+		if common.ReplayEnvironmentsPath, err = filepath.Abs(replayPath); err != nil {
+			logrus.Fatalf("Failed to make the replay directory path %q absolute. Error: %q", replayPath, err)
+		}
+	}
+}
+
 // checkOutputPath checks if the output path is already in use.
 func checkOutputPath(outpath string, overwrite bool) {
 	fi, err := os.Stat(outpath)
@@ -77,25 +95,25 @@ func checkOutputPath(outpath string, overwrite bool) {
 	logrus.Infof("Output directory %s exists. The contents might get overwritten.", outpath)
 }
 
-func startQA(flags qaflags) {
-	qaengine.StartEngine(flags.qaskip, flags.qaport, flags.qadisablecli)
+func startQA(flags qaflags, customizationsPath string) {
+	qaengine.StartEngine(flags.qaskip, flags.qaport, flags.qadisablecli, flags.qagrpc, flags.qadeadline)
 	if flags.configOut == "" {
-		qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+		qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, flags.persistPasswords)
 	} else {
 		if flags.configOut == "." {
-			qaengine.SetupConfigFile(common.ConfigFile, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+			qaengine.SetupConfigFile(common.ConfigFile, flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, flags.persistPasswords)
 		} else if fi, err := os.Stat(flags.configOut); err == nil {
 			if fi.IsDir() {
-				qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+				qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, flags.persistPasswords)
 			} else {
-				qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+				qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, flags.persistPasswords)
 			}
 		} else if strings.Contains(filepath.Base(flags.configOut), ".") {
 			os.MkdirAll(filepath.Dir(flags.configOut), common.DefaultDirectoryPermission)
-			qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+			qaengine.SetupConfigFile(flags.configOut, flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, flags.persistPasswords)
 		} else {
 			os.MkdirAll(flags.configOut, common.DefaultDirectoryPermission)
-			qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
+			qaengine.SetupConfigFile(filepath.Join(flags.configOut, common.ConfigFile), flags.setconfigs, flags.configs, flags.preSets, flags.profile, customizationsPath, flags.persistPasswords)
 		}
 	}
 	if flags.qaCacheOut != "" {
@@ -17,6 +17,10 @@
 package collector
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -63,6 +67,11 @@ func (c *CfAppsCollector) Collect(inputPath string, outputPath string) error {
 	if err != nil {
 		logrus.Errorf("Unable to create outputPath %s : %s", outputPath, err)
 	}
+	schedulerURL, err := getCfSchedulerURL(client.Config.ApiAddress)
+	if err != nil {
+		logrus.Debugf("Unable to get the url of the scheduler of the cf instance : %s", err)
+	}
+	spaceTasks := map[string]map[string][]collecttypes.CfTask{} // [space guid][app guid]
 	cfinstanceapps := collecttypes.NewCfApps()
 	cfinstanceapps.Name = common.NormalizeForMetadataName(strings.TrimSpace(cfInfo.Name))
 	for _, app := range apps {
@@ -75,6 +84,15 @@ func (c *CfAppsCollector) Collect(inputPath string, outputPath string) error {
 		} else {
 			cfapp.Environment = appEnv
 		}
+		if schedulerURL != "" {
+			if _, ok := spaceTasks[app.SpaceGuid]; !ok {
+				spaceTasks[app.SpaceGuid], err = getCfSchedulerTasks(client.Config.HttpClient, schedulerURL, app.SpaceGuid)
+				if err != nil {
+					logrus.Debugf("Unable to get the scheduled tasks of the space %s : %s", app.SpaceGuid, err)
+				}
+			}
+			cfapp.Tasks = spaceTasks[app.SpaceGuid][app.Guid]
+		}
 		cfinstanceapps.Spec.CfApps = append(cfinstanceapps.Spec.CfApps, cfapp)
 	}
 	cfinstanceapps = collecttypes.FormatMapsWithInterface(cfinstanceapps)
@@ -90,3 +108,65 @@ func (c *CfAppsCollector) Collect(inputPath string, outputPath string) error {
 
 	return nil
 }
+
+// getCfSchedulerURL returns the url of the scheduler service, which runs on the system domain like the cloud controller
+func getCfSchedulerURL(apiAddress string) (string, error) {
+	u, err := url.Parse(apiAddress)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(u.Host, "api.") {
+		return "", fmt.Errorf("the api address %s does not start with api", apiAddress)
+	}
+	u.Host = "scheduler." + strings.TrimPrefix(u.Host, "api.")
+	u.Path = ""
+	return u.String(), nil
+}
+
+// getCfSchedulerTasks returns the jobs of the scheduler in the space along with their schedules, grouped by app guid
+func getCfSchedulerTasks(httpClient *http.Client, schedulerURL string, spaceGUID string) (map[string][]collecttypes.CfTask, error) {
+	jobs := struct {
+		Resources []struct {
+			GUID    string `json:"guid"`
+			Name    string `json:"name"`
+			Command string `json:"command"`
+			AppGUID string `json:"app_guid"`
+		} `json:"resources"`
+	}{}
+	if err := getCfSchedulerResource(httpClient, schedulerURL+"/jobs?space_guid="+url.QueryEscape(spaceGUID), &jobs); err != nil {
+		return nil, err
+	}
+	tasks := map[string][]collecttypes.CfTask{}
+	for _, job := range jobs.Resources {
+		schedules := struct {
+			Resources []struct {
+				Enabled        bool   `json:"enabled"`
+				Expression     string `json:"expression"`
+				ExpressionType string `json:"expression_type"`
+			} `json:"resources"`
+		}{}
+		if err := getCfSchedulerResource(httpClient, schedulerURL+"/jobs/"+job.GUID+"/schedules", &schedules); err != nil {
+			logrus.Errorf("Unable to get the schedules of the job %s : %s", job.Name, err)
+		}
+		task := collecttypes.CfTask{Name: job.Name, Command: job.Command}
+		for _, schedule := range schedules.Resources {
+			if schedule.Enabled && schedule.ExpressionType == "cron_expression" {
+				task.Schedules = append(task.Schedules, strings.TrimSpace(schedule.Expression))
+			}
+		}
+		tasks[job.AppGUID] = append(tasks[job.AppGUID], task)
+	}
+	return tasks, nil
+}
+
+func getCfSchedulerResource(httpClient *http.Client, resourceURL string, resource interface{}) error {
+	resp, err := httpClient.Get(resourceURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got the status %s for %s", resp.Status, resourceURL)
+	}
+	return json.NewDecoder(resp.Body).Decode(resource)
+}
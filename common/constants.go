@@ -27,6 +27,24 @@ import (
 const (
 	// DisableLocalExecutionFlag is the name of the flag that tells us whether to use allow execution of executables locally
 	DisableLocalExecutionFlag = "disable-local-execution"
+	// SandboxLocalExecutionFlag is the name of the flag that tells us whether to run the local executables in a sandbox
+	SandboxLocalExecutionFlag = "sandbox-local-execution"
+	// RecordEnvironmentsFlag is the name of the flag that tells us where to record the interactions with the environments
+	RecordEnvironmentsFlag = "record-environments"
+	// ReplayEnvironmentsFlag is the name of the flag that tells us where to replay the interactions with the environments from
+	ReplayEnvironmentsFlag = "replay-environments"
+	// RebuildTransformerImagesFlag is the name of the flag that tells us whether to rebuild the transformer images built from build contexts
+	RebuildTransformerImagesFlag = "rebuild-transformer-images"
+	// ResourceProfileFlag is the name of the flag that tells us which profile to use for the default resources of the containers
+	ResourceProfileFlag = "resource-profile"
+	// PodSecurityFlag is the name of the flag that tells us which pod security level the pods should comply with
+	PodSecurityFlag = "pod-security"
+	// PodSecurityPrivileged is the unrestricted pod security level
+	PodSecurityPrivileged = "privileged"
+	// PodSecurityBaseline is the pod security level which prevents known privilege escalations
+	PodSecurityBaseline = "baseline"
+	// PodSecurityRestricted is the pod security level which follows the pod hardening best practices
+	PodSecurityRestricted = "restricted"
 )
 
 const (
@@ -44,6 +62,8 @@ const (
 	QACacheFile = types.AppNameShort + "qacache.yaml"
 	// ConfigFile defines the location of the config file
 	ConfigFile = types.AppNameShort + "config.yaml"
+	// QAAuditFile defines the location of the QA audit file
+	QAAuditFile = types.AppNameShort + "qa-audit.yaml"
 	// IgnoreFilename is the name of the file containing the ignore rules and exceptions
 	IgnoreFilename = "." + types.AppNameShort + "ignore"
 	// WindowsAnnotation tag is used tag a service to run on windows nodes
@@ -83,6 +103,8 @@ const (
 	ConfigStoragesKey = BaseKey + d + "storages"
 	//ConfigMinReplicasKey represents Ingress host Key
 	ConfigMinReplicasKey = BaseKey + d + "minreplicas"
+	//ConfigResourceProfileKey represents the resources of the custom resource profile
+	ConfigResourceProfileKey = BaseKey + d + "resourceprofile"
 	//ConfigContainerRuntimeKey represents the container runtime to use
 	ConfigContainerRuntimeKey = BaseKey + d + "containerruntime"
 	//ConfigPortsForServiceKeySegment represents the ports used for service
@@ -101,8 +123,32 @@ const (
 	ConfigContainerizationOptionServiceKeySegment = "containerizationoption"
 	//ConfigApacheConfFileForServiceKeySegment represents the conf file used for service
 	ConfigApacheConfFileForServiceKeySegment = "apacheconfig"
+	//ConfigHPAForServiceKeySegment represents the horizontal pod autoscaler of the service
+	ConfigHPAForServiceKeySegment = "hpa"
+	//ConfigPDBForServiceKeySegment represents the pod disruption budget of the service
+	ConfigPDBForServiceKeySegment = "pdb"
+	//ConfigProbesForServiceKeySegment represents the probes of the service
+	ConfigProbesForServiceKeySegment = "probes"
+	//ConfigResourcesForServiceKeySegment represents the resource requests and limits of the service
+	ConfigResourcesForServiceKeySegment = "resources"
+	//ConfigTLSForServiceKeySegment represents the TLS configuration of the exposed service
+	ConfigTLSForServiceKeySegment = "tls"
+	//ConfigSecurityContextForServiceKeySegment represents the security context of the service
+	ConfigSecurityContextForServiceKeySegment = "securitycontext"
+	//ConfigScheduleForServiceKeySegment represents the cron schedule of the service
+	ConfigScheduleForServiceKeySegment = "schedule"
+	//ConfigCronJobForServiceKeySegment represents the concurrency and history options of the scheduled service
+	ConfigCronJobForServiceKeySegment = "cronjob"
+	//ConfigCrontabForServiceKeySegment represents the crontab files used for the service
+	ConfigCrontabForServiceKeySegment = "crontabs"
 	//ConfigSpawnContainersKey represents spwan containers option Key
 	ConfigSpawnContainersKey = BaseKey + d + "spawncontainers"
+	//ConfigContainerEngineKey represents the container engine used for spawning containers
+	ConfigContainerEngineKey = BaseKey + d + "containerengine"
+	//ConfigReuseContainersKey represents the key for reusing containers across transformers
+	ConfigReuseContainersKey = BaseKey + d + "reusecontainers"
+	//ConfigMaxContainersKey represents the key for the maximum number of containers running at the same time
+	ConfigMaxContainersKey = BaseKey + d + "maxcontainers"
 	//ConfigTransformersKey represents transformers Key
 	ConfigTransformersKey = BaseKey + d + "transformers"
 	//ConfigTargetKey represents Target Key
@@ -133,6 +179,28 @@ const (
 	ConfigIngressHostKey = ConfigIngressKey + d + "host"
 	//ConfigIngressTLSKey represents ingress tls Key
 	ConfigIngressTLSKey = ConfigIngressKey + d + "tls"
+	//ConfigIngressKindKey represents the key for the kind of resources used to expose the services
+	ConfigIngressKindKey = ConfigIngressKey + d + "kind"
+	//ConfigIngressGatewayClassKey represents the key for the gateway class of the gateway api gateway
+	ConfigIngressGatewayClassKey = ConfigIngressKey + d + "gatewayclass"
+	//ConfigSecretsKey represents the key for the secrets of the target
+	ConfigSecretsKey = ConfigTargetKey + d + "secrets"
+	//ConfigSecretsModeKey represents the key for how the content of the secrets is output
+	ConfigSecretsModeKey = ConfigSecretsKey + d + "mode"
+	//ConfigSecretStoreKey represents the key for the secret store used by the external secrets
+	ConfigSecretStoreKey = ConfigSecretsKey + d + "secretstore"
+	//ConfigSecretStoreKindKey represents the key for the kind of the secret store used by the external secrets
+	ConfigSecretStoreKindKey = ConfigSecretsKey + d + "secretstorekind"
+	//ConfigSealedSecretsCertKey represents the key for the public cert used to seal the secrets
+	ConfigSealedSecretsCertKey = ConfigSecretsKey + d + "sealedsecretscert"
+	//ConfigServiceMeshKey represents the key for the service mesh of the target cluster
+	ConfigServiceMeshKey = ConfigTargetKey + d + "servicemesh"
+	//ConfigServiceMeshTypeKey represents the key for the service mesh the services are added to
+	ConfigServiceMeshTypeKey = ConfigServiceMeshKey + d + "type"
+	//ConfigServiceMeshMTLSModeKey represents the key for the mutual TLS mode of the service mesh
+	ConfigServiceMeshMTLSModeKey = ConfigServiceMeshKey + d + "mtlsmode"
+	//ConfigServiceMeshIngressGatewayPrincipalKey represents the key for the identity of the istio ingress gateway
+	ConfigServiceMeshIngressGatewayPrincipalKey = ConfigServiceMeshKey + d + "ingressgatewayprincipal"
 	//ConfigTargetClusterTypeKey represents target cluster type key
 	ConfigTargetClusterTypeKey = ConfigTargetKey + d + "clustertype"
 	//ConfigImageRegistryKey represents image registry Key
@@ -198,8 +266,24 @@ var (
 	IgnoreEnvironment = false
 	// DisableLocalExecution indicates whether to allow execution of local executables
 	DisableLocalExecution = false
+	// SandboxLocalExecution indicates whether to run the local executables in a sandbox
+	SandboxLocalExecution = false
+	// RecordEnvironmentsPath is the directory into which the interactions with the environments are recorded
+	RecordEnvironmentsPath = ""
+	// ReplayEnvironmentsPath is the directory from which the recorded interactions with the environments are replayed
+	ReplayEnvironmentsPath = ""
+	// RebuildTransformerImages indicates whether to rebuild the transformer images instead of using the cached images
+	RebuildTransformerImages = false
+	// ResourceProfile is the profile used for the default resources of the containers. No defaults are used if it is empty
+	ResourceProfile = ""
+	// PodSecurity is the pod security level the pods should comply with. The pods are not hardened or validated if it is empty
+	PodSecurity = ""
+	// PodSecurityLevels are the valid pod security levels
+	PodSecurityLevels = []string{PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted}
 	// DefaultIgnoreDirRegexps specifies directory name regexes that would be ignored
 	DefaultIgnoreDirRegexps = []*regexp.Regexp{regexp.MustCompile("^[.].*")}
+	// javaMaxHeapSizeRegex provides pattern for the max heap size flag of the JVM
+	javaMaxHeapSizeRegex = regexp.MustCompile(`-Xmx([0-9]+)([kKmMgGtT]?)\b`)
 	// disallowedDNSCharactersRegex provides pattern for characters not allowed in a DNS Name
 	disallowedDNSCharactersRegex = regexp.MustCompile(`[^a-z0-9\-]`)
 	// disallowedEnvironmentCharactersRegex provides pattern for characters not allowed in a DNS Name
@@ -18,6 +18,8 @@ package common
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -45,3 +47,60 @@ func (hook *CleanupHook) Levels() []logrus.Level {
 		logrus.FatalLevel,
 	}
 }
+
+const redactedSecret = "[REDACTED]"
+
+var (
+	secretsMutex sync.RWMutex
+	// secretsToRedact are the secrets which are removed from the logs
+	secretsToRedact = []string{}
+)
+
+// AddSecretToRedact adds a secret which has to be removed from the logs
+func AddSecretToRedact(secret string) {
+	if secret == "" {
+		return
+	}
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	secretsToRedact = append(secretsToRedact, secret)
+}
+
+// RedactSecrets replaces the secrets in the string
+func RedactSecrets(s string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	for _, secret := range secretsToRedact {
+		s = strings.ReplaceAll(s, secret, redactedSecret)
+	}
+	return s
+}
+
+// RedactHook removes the secrets from the log messages
+type RedactHook struct{}
+
+// NewRedactHook creates a redact hook
+func NewRedactHook() *RedactHook {
+	return &RedactHook{}
+}
+
+// Fire removes the secrets from the message and the fields of the entry
+func (hook *RedactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = RedactSecrets(entry.Message)
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case string:
+			entry.Data[k] = RedactSecrets(v)
+		case error:
+			if redacted := RedactSecrets(v.Error()); redacted != v.Error() {
+				entry.Data[k] = redacted
+			}
+		}
+	}
+	return nil
+}
+
+// Levels returns the levels on which the redact hook gets called
+func (hook *RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
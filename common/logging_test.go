@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactHook(t *testing.T) {
+	AddSecretToRedact("")
+	AddSecretToRedact("s3cr3t-value")
+	AddSecretToRedact("other-secret")
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+	logger.AddHook(NewRedactHook())
+
+	testcases := []struct {
+		name string
+		log  func()
+		want string
+	}{
+		{
+			name: "message",
+			log:  func() { logger.Infof("the password is s3cr3t-value and the token is other-secret") },
+			want: `msg="the password is [REDACTED] and the token is [REDACTED]"`,
+		},
+		{
+			name: "string field",
+			log:  func() { logger.WithField("password", "prefix-s3cr3t-value").Info("login") },
+			want: `password="prefix-[REDACTED]"`,
+		},
+		{
+			name: "error field",
+			log:  func() { logger.WithError(errors.New("unable to login with s3cr3t-value")).Error("login failed") },
+			want: `error="unable to login with [REDACTED]"`,
+		},
+		{
+			name: "other fields",
+			log:  func() { logger.WithField("count", 3).Info("no secrets") },
+			want: `msg="no secrets" count=3`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			out.Reset()
+			tc.log()
+			if !strings.Contains(out.String(), tc.want) {
+				t.Fatalf("the log is not redacted correctly. Expected it to contain: %s Actual: %s", tc.want, out.String())
+			}
+			for _, secret := range []string{"s3cr3t-value", "other-secret"} {
+				if strings.Contains(out.String(), secret) {
+					t.Fatalf("the secret %s was not redacted. Actual: %s", secret, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	AddSecretToRedact("hunter2")
+	testcases := []struct {
+		input string
+		want  string
+	}{
+		{input: "", want: ""},
+		{input: "nothing to redact", want: "nothing to redact"},
+		{input: "hunter2", want: "[REDACTED]"},
+		{input: "hunter2 and hunter2 again", want: "[REDACTED] and [REDACTED] again"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.input, func(t *testing.T) {
+			if got := RedactSecrets(tc.input); got != tc.want {
+				t.Fatalf("the secrets are not redacted correctly. Expected: %q Actual: %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -1281,3 +1281,28 @@ func ReplaceStartingTerminatingHyphens(str string, startReplaceStr, endReplaceSt
 	}
 	return str
 }
+
+// GetJavaMaxHeapSize returns the max heap size in bytes set by the last -Xmx flag in the string, or 0 if there is none
+func GetJavaMaxHeapSize(s string) int64 {
+	matches := javaMaxHeapSizeRegex.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	match := matches[len(matches)-1]
+	size, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		logrus.Debugf("Unable to parse the max heap size %s : %s", match[0], err)
+		return 0
+	}
+	switch strings.ToLower(match[2]) {
+	case "k":
+		size *= 1024
+	case "m":
+		size *= 1024 * 1024
+	case "g":
+		size *= 1024 * 1024 * 1024
+	case "t":
+		size *= 1024 * 1024 * 1024 * 1024
+	}
+	return size
+}
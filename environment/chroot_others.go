@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// setChroot returns an error since process shared containers are only supported on linux
+func setChroot(cmd *exec.Cmd, root string) error {
+	return fmt.Errorf("process shared containers are not supported on %s", runtime.GOOS)
+}
@@ -30,6 +30,8 @@ var (
 	inited        bool
 	disabled      bool
 	workingEngine ContainerEngine
+	// workingEngineName is the type of the working container engine
+	workingEngineName string
 )
 
 // ContainerEngine defines interface to manage containers
@@ -44,27 +46,54 @@ type ContainerEngine interface {
 	CopyDirsFromContainer(containerID string, paths map[string]string) (err error)
 	BuildImage(image, context, dockerfile string) (err error)
 	RemoveImage(image string) (err error)
-	CreateContainer(image string) (containerid string, err error)
+	// CreateContainer creates and starts a container with the image, resource limits and network mode of the container config
+	CreateContainer(container environmenttypes.Container) (containerid string, err error)
 	StopAndRemoveContainer(containerID string) (err error)
-	// RunContainer runs a container from an image
-	RunContainer(image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error)
+	// RunContainer runs a container from the image of the container config
+	RunContainer(container environmenttypes.Container, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error)
 }
 
+const (
+	// DockerEngineName is the name of the docker container engine
+	DockerEngineName = "docker"
+	// PodmanEngineName is the name of the podman container engine
+	PodmanEngineName = "podman"
+)
+
 func initContainerEngine() (err error) {
-	workingEngine, err = newDockerEngine()
+	engineName := qaengine.FetchSelectAnswer(common.ConfigContainerEngineKey, "Select the container engine to use for spawning containers :", []string{"Podman is accessed using the libpod REST API socket."}, DockerEngineName, []string{DockerEngineName, PodmanEngineName})
+	e, err := NewContainerEngine(engineName)
 	if err != nil {
-		logrus.Debugf("Unable to use docker : %s", err)
-		return err
-	}
-	//TODO: Add Support for podman
-	if workingEngine == nil {
-		err := fmt.Errorf("no working container runtime available")
-		logrus.Errorf("%s", err)
 		return err
 	}
+	workingEngine = e
+	workingEngineName = engineName
 	return nil
 }
 
+// NewContainerEngine creates a container engine of the given type
+func NewContainerEngine(engineName string) (ContainerEngine, error) {
+	switch engineName {
+	case PodmanEngineName:
+		e, err := newPodmanEngine()
+		if err != nil {
+			logrus.Debugf("Unable to use podman : %s", err)
+			return nil, err
+		}
+		return e, nil
+	case DockerEngineName:
+		e, err := newDockerEngine()
+		if err != nil {
+			logrus.Debugf("Unable to use docker : %s", err)
+			return nil, err
+		}
+		return e, nil
+	}
+	err := fmt.Errorf("unsupported container engine %s", engineName)
+	logrus.Errorf("%s", err)
+	return nil, err
+}
+
 // GetContainerEngine gets a working container engine
 func GetContainerEngine() ContainerEngine {
 	if !inited {
@@ -77,6 +106,12 @@ func GetContainerEngine() ContainerEngine {
 	return workingEngine
 }
 
+// GetContainerEngineName returns the type of the working container engine
+func GetContainerEngineName() string {
+	GetContainerEngine()
+	return workingEngineName
+}
+
 // IsDisabled returns whether the container environment is disabled
 func IsDisabled() bool {
 	return disabled
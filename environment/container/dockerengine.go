@@ -55,7 +55,7 @@ func newDockerEngine() (*dockerEngine, error) {
 		cli:             cli,
 		ctx:             ctx,
 	}
-	_, _, err = e.RunContainer(testimage, environmenttypes.Command{}, "", "")
+	_, _, err = e.RunContainer(environmenttypes.Container{Image: testimage}, environmenttypes.Command{}, "", "")
 	if err != nil {
 		logrus.Errorf("Unable to run test container : %s", err)
 		return nil, err
@@ -146,17 +146,42 @@ func (e *dockerEngine) InspectImage(image string) (types.ImageInspect, error) {
 	return inspectOutput, nil
 }
 
+// getHostConfig returns the host config with the resource limits and network mode of the container config
+func getHostConfig(c environmenttypes.Container) (*container.HostConfig, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	hostconfig := &container.HostConfig{}
+	milliCPUs, _ := c.Resources.GetMilliCPUs()
+	hostconfig.NanoCPUs = milliCPUs * 1000000
+	hostconfig.Memory, _ = c.Resources.GetMemoryBytes()
+	if c.Resources.PIDs > 0 {
+		pids := c.Resources.PIDs
+		hostconfig.PidsLimit = &pids
+	}
+	if c.Network != "" {
+		hostconfig.NetworkMode = container.NetworkMode(c.Network)
+	}
+	return hostconfig, nil
+}
+
 // CreateContainer creates a container
-func (e *dockerEngine) CreateContainer(image string) (containerid string, err error) {
+func (e *dockerEngine) CreateContainer(c environmenttypes.Container) (containerid string, err error) {
+	image := c.Image
 	if !e.pullImage(image) {
 		logrus.Debugf("Unable to pull image using docker : %s", image)
 		return "", fmt.Errorf("unable to pull image")
 	}
+	hostconfig, err := getHostConfig(c)
+	if err != nil {
+		logrus.Errorf("Invalid container config for image %s : %s", image, err)
+		return "", err
+	}
 	contconfig := &container.Config{
 		Image: image,
 		Cmd:   []string{"sh", "-c", "tail -f /dev/null"},
 	}
-	resp, err := e.cli.ContainerCreate(e.ctx, contconfig, nil, nil, nil, "")
+	resp, err := e.cli.ContainerCreate(e.ctx, contconfig, hostconfig, nil, nil, "")
 	if err != nil {
 		logrus.Debugf("Container creation failed with image %s with no volumes", image)
 		return "", err
@@ -186,7 +211,7 @@ func (e *dockerEngine) CopyDirsIntoImage(image, newImageName string, paths map[s
 		logrus.Debugf("Unable to pull image using docker : %s", image)
 		return fmt.Errorf("unable to pull image")
 	}
-	cid, err := e.CreateContainer(image)
+	cid, err := e.CreateContainer(environmenttypes.Container{Image: image})
 	if err != nil {
 		logrus.Errorf("Unable to create container with base image %s : %s", image, err)
 		return err
@@ -271,7 +296,8 @@ func (e *dockerEngine) RemoveImage(image string) (err error) {
 }
 
 // RunContainer executes a container
-func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+func (e *dockerEngine) RunContainer(c environmenttypes.Container, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+	image := c.Image
 	if !e.pullImage(image) {
 		logrus.Debugf("Unable to pull image using docker : %s", image)
 		return "", false, fmt.Errorf("unable to pull image")
@@ -288,7 +314,11 @@ func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command,
 	if (volsrc == "" && voldest != "") || (volsrc != "" && voldest == "") {
 		logrus.Warnf("Either volume source (%s) or destination (%s) is empty. Ingoring volume mount.", volsrc, voldest)
 	}
-	hostconfig := &container.HostConfig{}
+	hostconfig, err := getHostConfig(c)
+	if err != nil {
+		logrus.Errorf("Invalid container config for image %s : %s", image, err)
+		return "", false, err
+	}
 	if volsrc != "" && voldest != "" {
 		hostconfig.Mounts = []mount.Mount{
 			{
@@ -302,7 +332,8 @@ func (e *dockerEngine) RunContainer(image string, cmd environmenttypes.Command,
 	resp, err := cli.ContainerCreate(ctx, contconfig, hostconfig, nil, nil, "")
 	if err != nil {
 		logrus.Debugf("Error during container creation : %s", err)
-		resp, err = cli.ContainerCreate(ctx, contconfig, nil, nil, nil, "")
+		hostconfig.Mounts = nil
+		resp, err = cli.ContainerCreate(ctx, contconfig, hostconfig, nil, nil, "")
 		if err != nil {
 			logrus.Debugf("Container creation failed with image %s with no volumes", image)
 			return "", false, err
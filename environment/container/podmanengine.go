@@ -17,52 +17,302 @@
 package container
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/stdcopy"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
 )
 
-//TOFIX: Support the interface
+const (
+	// podmanHostEnvName is the environment variable podman uses to point to a remote service
+	podmanHostEnvName = "CONTAINER_HOST"
+	// podmanAPIVersion is the libpod API version used for all requests
+	podmanAPIVersion = "v3.0.0"
+	// podmanDummyHost is used in the request urls when talking over a unix socket
+	podmanDummyHost = "d"
+	// podmanContainerPathStatHeader contains the stat of the path returned by the archive endpoint
+	podmanContainerPathStatHeader = "X-Docker-Container-Path-Stat"
+	// podmanCPUPeriod is the cpu period in microseconds used for cpu limits
+	podmanCPUPeriod = 100000
+)
 
+// podmanEngine talks to the podman service using the libpod REST API
 type podmanEngine struct {
 	availableImages map[string]bool
+	cli             *http.Client
+	baseURL         string
+	ctx             context.Context
+}
+
+// podmanIDResponse is the response returned by the libpod create endpoints
+type podmanIDResponse struct {
+	ID string `json:"Id"`
+}
+
+// podmanErrorResponse is the error response returned by the libpod API
+type podmanErrorResponse struct {
+	Cause    string `json:"cause"`
+	Message  string `json:"message"`
+	Response int    `json:"response"`
+}
+
+// podmanStreamMessage is a single message in the stream returned by the pull and build endpoints
+type podmanStreamMessage struct {
+	Stream string `json:"stream,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// podmanMount is a mount in the libpod container spec
+type podmanMount struct {
+	Destination string   `json:"destination"`
+	Source      string   `json:"source"`
+	Type        string   `json:"type"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// podmanResourceLimits is the subset of the OCI linux resources used by move2kube
+type podmanResourceLimits struct {
+	CPU    *podmanCPULimits `json:"cpu,omitempty"`
+	Memory *podmanLimit     `json:"memory,omitempty"`
+	Pids   *podmanLimit     `json:"pids,omitempty"`
 }
 
-func newPodmanEngine() *podmanEngine {
-	return &podmanEngine{
+// podmanCPULimits limits the cpu time to quota microseconds in every period
+type podmanCPULimits struct {
+	Quota  int64  `json:"quota"`
+	Period uint64 `json:"period"`
+}
+
+// podmanLimit is a memory or pids limit
+type podmanLimit struct {
+	Limit int64 `json:"limit"`
+}
+
+// podmanNamespace is a namespace in the libpod container spec
+type podmanNamespace struct {
+	NSMode string `json:"nsmode"`
+}
+
+// podmanContainerSpec is the subset of the libpod container spec used by move2kube
+type podmanContainerSpec struct {
+	Image          string                `json:"image"`
+	Command        []string              `json:"command,omitempty"`
+	Mounts         []podmanMount         `json:"mounts,omitempty"`
+	ResourceLimits *podmanResourceLimits `json:"resource_limits,omitempty"`
+	NetNS          *podmanNamespace      `json:"netns,omitempty"`
+}
+
+// podmanExecConfig is the body of the exec create request
+type podmanExecConfig struct {
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Cmd          []string `json:"Cmd"`
+	WorkingDir   string   `json:"WorkingDir,omitempty"`
+	Env          []string `json:"Env,omitempty"`
+}
+
+// podmanExecInspect is the response of the exec inspect request
+type podmanExecInspect struct {
+	ExitCode int  `json:"ExitCode"`
+	Running  bool `json:"Running"`
+}
+
+// newPodmanEngine creates a new podman engine instance
+func newPodmanEngine() (*podmanEngine, error) {
+	e, err := newPodmanEngineWithHost(getPodmanHost())
+	if err != nil {
+		logrus.Debugf("Unable to create podman client : %s", err)
+		return nil, err
+	}
+	if err := e.ping(); err != nil {
+		logrus.Debugf("Unable to reach the podman service : %s", err)
+		return nil, err
+	}
+	_, _, err = e.RunContainer(environmenttypes.Container{Image: testimage}, environmenttypes.Command{}, "", "")
+	if err != nil {
+		logrus.Errorf("Unable to run test container : %s", err)
+		return nil, err
+	}
+	return e, nil
+}
+
+// newPodmanEngineWithHost creates a podman engine which talks to the service at the given host
+func newPodmanEngineWithHost(host string) (*podmanEngine, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the podman host %s . Error: %q", host, err)
+	}
+	e := &podmanEngine{
 		availableImages: map[string]bool{},
+		ctx:             context.Background(),
+	}
+	switch u.Scheme {
+	case "unix":
+		socketPath := u.Path
+		e.cli = &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+				},
+			},
+		}
+		e.baseURL = "http://" + podmanDummyHost
+	case "tcp", "http":
+		e.cli = &http.Client{}
+		e.baseURL = "http://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported scheme %s in the podman host %s", u.Scheme, host)
 	}
+	return e, nil
 }
 
-// InspectImage returns inspect output for an image using Podman
-func (e *podmanEngine) InspectImage(image string) (t types.ImageInspect, err error) {
-	inspectcmd := exec.Command("podman", "inspect", image)
-	logrus.Debugf("Inspecting image %s", image)
-	output, err := inspectcmd.CombinedOutput()
+// getPodmanHost returns the address of the podman service
+func getPodmanHost() string {
+	if host := os.Getenv(podmanHostEnvName); host != "" {
+		return host
+	}
+	if os.Geteuid() != 0 {
+		if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+			return "unix://" + filepath.Join(runtimeDir, "podman", "podman.sock")
+		}
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+func (e *podmanEngine) getURL(path string, query url.Values, libpod bool) string {
+	u := e.baseURL + "/" + podmanAPIVersion
+	if libpod {
+		u += "/libpod"
+	}
+	u += path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// do sends a request to the libpod API and returns the response if the status code is successful
+func (e *podmanEngine) do(method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	return e.doRequest(method, e.getURL(path, query, true), body, contentType)
+}
+
+func (e *podmanEngine) doRequest(method, u string, body io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(e.ctx, method, u, body)
 	if err != nil {
-		logrus.Debugf("Unable to inspect image %s : %s, %s", image, err, output)
-		return t, err
+		return nil, err
 	}
-	t = types.ImageInspect{}
-	err = json.Unmarshal(output, &t)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := e.cli.Do(req)
 	if err != nil {
-		logrus.Debugf("Error in unmarshalling json %s: %s.", output, err)
+		return nil, err
 	}
-	return t, err
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		respBytes, _ := io.ReadAll(resp.Body)
+		errResp := podmanErrorResponse{}
+		if err := json.Unmarshal(respBytes, &errResp); err == nil && errResp.Message != "" {
+			return nil, fmt.Errorf("podman returned status code %d : %s", resp.StatusCode, errResp.Message)
+		}
+		return nil, fmt.Errorf("podman returned status code %d : %s", resp.StatusCode, strings.TrimSpace(string(respBytes)))
+	}
+	return resp, nil
+}
+
+// doJSON sends a json body to the libpod API and decodes the json response into out
+func (e *podmanEngine) doJSON(method, path string, query url.Values, in interface{}, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		inBytes, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(inBytes)
+		contentType = "application/json"
+	}
+	resp, err := e.do(method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (e *podmanEngine) ping() error {
+	resp, err := e.do(http.MethodGet, "/_ping", nil, nil, "")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// readPodmanStream reads the json messages returned by the pull and build endpoints and returns the first error
+func readPodmanStream(r io.Reader) (string, error) {
+	output := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		msg := podmanStreamMessage{}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			output += string(line) + "\n"
+			continue
+		}
+		if msg.Error != "" {
+			return output, fmt.Errorf("%s", strings.TrimSpace(msg.Error))
+		}
+		output += msg.Stream
+	}
+	return output, scanner.Err()
 }
 
 func (e *podmanEngine) pullImage(image string) bool {
 	if a, ok := e.availableImages[image]; ok {
 		return a
 	}
-	pullcmd := exec.Command("podman", "pull", image)
-	logrus.Debugf("Pulling image %s", image)
-	output, err := pullcmd.CombinedOutput()
+	if resp, err := e.do(http.MethodGet, "/images/"+image+"/exists", nil, nil, ""); err == nil {
+		resp.Body.Close()
+		e.availableImages[image] = true
+		return true
+	}
+	logrus.Infof("Pulling container image %s. This could take a few mins.", image)
+	resp, err := e.do(http.MethodPost, "/images/pull", url.Values{"reference": []string{image}}, nil, "")
+	if err != nil {
+		logrus.Debugf("Unable to pull image %s : %s", image, err)
+		e.availableImages[image] = false
+		return false
+	}
+	defer resp.Body.Close()
+	output, err := readPodmanStream(resp.Body)
+	logrus.Debug(output)
 	if err != nil {
-		logrus.Warnf("Error while pulling builder %s : %s : %s", image, err, output)
+		logrus.Debugf("Unable to pull image %s : %s", image, err)
 		e.availableImages[image] = false
 		return false
 	}
@@ -70,26 +320,357 @@ func (e *podmanEngine) pullImage(image string) bool {
 	return true
 }
 
+// RunCmdInContainer executes a command in a running container
+func (e *podmanEngine) RunCmdInContainer(containerID string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitCode int, err error) {
+	execConfig := podmanExecConfig{
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+		WorkingDir:   workingdir,
+		Env:          env,
+	}
+	cresp := podmanIDResponse{}
+	if err = e.doJSON(http.MethodPost, "/containers/"+containerID+"/exec", nil, execConfig, &cresp); err != nil {
+		return
+	}
+	startBytes, err := json.Marshal(map[string]bool{"Detach": false, "Tty": false})
+	if err != nil {
+		return
+	}
+	aresp, err := e.do(http.MethodPost, "/exec/"+cresp.ID+"/start", nil, bytes.NewReader(startBytes), "application/json")
+	if err != nil {
+		return
+	}
+	defer aresp.Body.Close()
+	var outBuf, errBuf bytes.Buffer
+	if _, err = stdcopy.StdCopy(&outBuf, &errBuf, aresp.Body); err != nil {
+		return
+	}
+	res := podmanExecInspect{}
+	if err = e.doJSON(http.MethodGet, "/exec/"+cresp.ID+"/json", nil, nil, &res); err != nil {
+		return
+	}
+	return outBuf.String(), errBuf.String(), res.ExitCode, nil
+}
+
+// InspectImage returns inspect output for an image using Podman
+func (e *podmanEngine) InspectImage(image string) (t types.ImageInspect, err error) {
+	logrus.Debugf("Inspecting image %s", image)
+	// The docker compatible endpoint is used since the interface returns docker types
+	resp, err := e.doRequest(http.MethodGet, e.getURL("/images/"+image+"/json", nil, false), nil, "")
+	if err != nil {
+		logrus.Debugf("Unable to inspect image %s : %s", image, err)
+		return t, err
+	}
+	defer resp.Body.Close()
+	t = types.ImageInspect{}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		logrus.Debugf("Error in unmarshalling the inspect output of image %s : %s", image, err)
+	}
+	return t, err
+}
+
+func (e *podmanEngine) createContainer(spec podmanContainerSpec) (containerid string, err error) {
+	resp := podmanIDResponse{}
+	if err := e.doJSON(http.MethodPost, "/containers/create", nil, spec, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// getPodmanContainerSpec returns the container spec with the resource limits and network mode of the container config
+func getPodmanContainerSpec(c environmenttypes.Container, cmd []string) (podmanContainerSpec, error) {
+	spec := podmanContainerSpec{
+		Image:   c.Image,
+		Command: cmd,
+	}
+	if err := c.Validate(); err != nil {
+		return spec, err
+	}
+	milliCPUs, _ := c.Resources.GetMilliCPUs()
+	memory, _ := c.Resources.GetMemoryBytes()
+	if milliCPUs > 0 || memory > 0 || c.Resources.PIDs > 0 {
+		limits := &podmanResourceLimits{}
+		if milliCPUs > 0 {
+			limits.CPU = &podmanCPULimits{Quota: milliCPUs * podmanCPUPeriod / 1000, Period: podmanCPUPeriod}
+		}
+		if memory > 0 {
+			limits.Memory = &podmanLimit{Limit: memory}
+		}
+		if c.Resources.PIDs > 0 {
+			limits.Pids = &podmanLimit{Limit: c.Resources.PIDs}
+		}
+		spec.ResourceLimits = limits
+	}
+	if c.Network != "" {
+		spec.NetNS = &podmanNamespace{NSMode: string(c.Network)}
+	}
+	return spec, nil
+}
+
+// CreateContainer creates a container
+func (e *podmanEngine) CreateContainer(c environmenttypes.Container) (containerid string, err error) {
+	image := c.Image
+	if !e.pullImage(image) {
+		logrus.Debugf("Unable to pull image using podman : %s", image)
+		return "", fmt.Errorf("unable to pull image")
+	}
+	spec, err := getPodmanContainerSpec(c, []string{"sh", "-c", "tail -f /dev/null"})
+	if err != nil {
+		logrus.Errorf("Invalid container config for image %s : %s", image, err)
+		return "", err
+	}
+	cid, err := e.createContainer(spec)
+	if err != nil {
+		logrus.Debugf("Container creation failed with image %s with no volumes", image)
+		return "", err
+	}
+	if err := e.doJSON(http.MethodPost, "/containers/"+cid+"/start", nil, nil, nil); err != nil {
+		logrus.Debugf("Container creation failed with image %s with no volumes", image)
+		return "", err
+	}
+	logrus.Debugf("Container %s created with image %s", cid, image)
+	return cid, nil
+}
+
+// StopAndRemoveContainer stops and removes a container
+func (e *podmanEngine) StopAndRemoveContainer(containerID string) (err error) {
+	err = e.doJSON(http.MethodDelete, "/containers/"+containerID, url.Values{"force": []string{"true"}}, nil, nil)
+	if err != nil {
+		logrus.Errorf("Unable to delete container with containerid %s : %s", containerID, err)
+		return err
+	}
+	return nil
+}
+
+// CopyDirsIntoImage copies some directories into a container and commits it as a new image
+func (e *podmanEngine) CopyDirsIntoImage(image, newImageName string, paths map[string]string) (err error) {
+	if !e.pullImage(image) {
+		logrus.Debugf("Unable to pull image using podman : %s", image)
+		return fmt.Errorf("unable to pull image")
+	}
+	cid, err := e.CreateContainer(environmenttypes.Container{Image: image})
+	if err != nil {
+		logrus.Errorf("Unable to create container with base image %s : %s", image, err)
+		return err
+	}
+	defer func() {
+		if err := e.StopAndRemoveContainer(cid); err != nil {
+			logrus.Errorf("Unable to stop and remove container %s : %s", cid, err)
+		}
+	}()
+	if err := e.CopyDirsIntoContainer(cid, paths); err != nil {
+		return err
+	}
+	repo, tag := splitImageReference(newImageName)
+	query := url.Values{"container": []string{cid}, "repo": []string{repo}}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	if err := e.doJSON(http.MethodPost, "/commit", query, nil, nil); err != nil {
+		logrus.Errorf("Unable to commit container as image : %s", err)
+		return err
+	}
+	e.availableImages[newImageName] = true
+	return nil
+}
+
+// CopyDirsIntoContainer copies some directories into a container
+func (e *podmanEngine) CopyDirsIntoContainer(containerID string, paths map[string]string) (err error) {
+	for sp, dp := range paths {
+		err = e.copyDirToContainer(containerID, sp, dp)
+		if err != nil {
+			logrus.Debugf("Container data copy failed for image %s with volume %s:%s : %s", containerID, sp, dp, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *podmanEngine) copyDirToContainer(containerID, src, dst string) error {
+	reader := readDirAsTar(src, dst)
+	if reader == nil {
+		err := fmt.Errorf("error during create tar archive from '%s'", src)
+		logrus.Error(err)
+		return err
+	}
+	defer reader.Close()
+	resp, err := e.do(http.MethodPut, "/containers/"+containerID+"/archive", url.Values{"path": []string{"/"}}, reader, "application/x-tar")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// CopyDirsFromContainer copies some directories from a container
+func (e *podmanEngine) CopyDirsFromContainer(containerID string, paths map[string]string) (err error) {
+	for sp, dp := range paths {
+		err = e.copyFromContainer(containerID, sp, dp)
+		if err != nil {
+			logrus.Debugf("Container data copy failed for image %s with volume %s:%s : %s", containerID, sp, dp, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *podmanEngine) copyFromContainer(containerID, containerPath, destPath string) error {
+	resp, err := e.do(http.MethodGet, "/containers/"+containerID+"/archive", url.Values{"path": []string{containerPath}}, nil, "")
+	if err != nil {
+		logrus.Errorf("Unable to copy from container : %s", err)
+		return err
+	}
+	defer resp.Body.Close()
+	stat, err := getPodmanPathStat(resp.Header)
+	if err != nil {
+		logrus.Errorf("Unable to get the stat of path %s in container %s : %s", containerPath, containerID, err)
+		return err
+	}
+	copyInfo := archive.CopyInfo{
+		Path:   containerPath,
+		Exists: true,
+		IsDir:  stat.Mode.IsDir(),
+	}
+	_, srcBase := archive.SplitPathDirEntry(copyInfo.Path)
+	preArchive := archive.RebaseArchiveEntries(resp.Body, srcBase, "")
+	return archive.CopyTo(preArchive, copyInfo, destPath)
+}
+
+func getPodmanPathStat(header http.Header) (types.ContainerPathStat, error) {
+	stat := types.ContainerPathStat{}
+	encodedStat := header.Get(podmanContainerPathStatHeader)
+	if encodedStat == "" {
+		return stat, fmt.Errorf("the header %s is missing in the response", podmanContainerPathStatHeader)
+	}
+	statBytes, err := base64.StdEncoding.DecodeString(encodedStat)
+	if err != nil {
+		return stat, err
+	}
+	err = json.Unmarshal(statBytes, &stat)
+	return stat, err
+}
+
+// BuildImage builds a container image
+func (e *podmanEngine) BuildImage(image, context, dockerfile string) (err error) {
+	logrus.Infof("Building container image %s. This could take a few mins.", image)
+	reader := readDirAsTar(context, "")
+	if reader == nil {
+		err := fmt.Errorf("error during create tar archive from '%s'", context)
+		logrus.Error(err)
+		return err
+	}
+	defer reader.Close()
+	query := url.Values{"t": []string{image}}
+	if dockerfile != "" {
+		query.Set("dockerfile", dockerfile)
+	}
+	resp, err := e.do(http.MethodPost, "/build", query, reader, "application/x-tar")
+	if err != nil {
+		logrus.Infof("Image creation failed with image %s with no volumes : %s", image, err)
+		return err
+	}
+	defer resp.Body.Close()
+	output, err := readPodmanStream(resp.Body)
+	logrus.Debugf("%s", output)
+	if err != nil {
+		logrus.Errorf("Unable to build image %s : %s", image, err)
+		return err
+	}
+	e.availableImages[image] = true
+	logrus.Debugf("Built image %s", image)
+	return nil
+}
+
+// RemoveImage removes an image
+func (e *podmanEngine) RemoveImage(image string) (err error) {
+	err = e.doJSON(http.MethodDelete, "/images/"+image, url.Values{"force": []string{"true"}}, nil, nil)
+	if err != nil {
+		logrus.Debugf("Image deletion failed for image %s", image)
+		return err
+	}
+	delete(e.availableImages, image)
+	return nil
+}
+
 // RunContainer executes a container using podman
-func (e *podmanEngine) RunContainer(image string, cmd string, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+func (e *podmanEngine) RunContainer(c environmenttypes.Container, cmd environmenttypes.Command, volsrc string, voldest string) (output string, containerStarted bool, err error) {
+	image := c.Image
 	if !e.pullImage(image) {
 		logrus.Debugf("Unable to pull image using podman : %s", image)
 		return "", false, fmt.Errorf("unable to pull image")
 	}
-	args := []string{"run", "--rm"}
+	if (volsrc == "" && voldest != "") || (volsrc != "" && voldest == "") {
+		logrus.Warnf("Either volume source (%s) or destination (%s) is empty. Ingoring volume mount.", volsrc, voldest)
+	}
+	spec, err := getPodmanContainerSpec(c, cmd)
+	if err != nil {
+		logrus.Errorf("Invalid container config for image %s : %s", image, err)
+		return "", false, err
+	}
 	if volsrc != "" && voldest != "" {
-		args = append(args, "-v", volsrc+":"+voldest)
+		spec.Mounts = []podmanMount{{
+			Destination: voldest,
+			Source:      volsrc,
+			Type:        "bind",
+			Options:     []string{"ro"},
+		}}
+	}
+	cid, err := e.createContainer(spec)
+	if err != nil {
+		logrus.Debugf("Error during container creation : %s", err)
+		spec.Mounts = nil
+		cid, err = e.createContainer(spec)
+		if err != nil {
+			logrus.Debugf("Container creation failed with image %s with no volumes", image)
+			return "", false, err
+		}
+		logrus.Debugf("Container %s created with image %s with no volumes", cid, image)
+		if volsrc != "" && voldest != "" {
+			err = e.copyDirToContainer(cid, volsrc, voldest)
+			if err != nil {
+				logrus.Debugf("Container data copy failed for image %s with volume %s:%s : %s", image, volsrc, voldest, err)
+				e.StopAndRemoveContainer(cid)
+				return "", false, err
+			}
+			logrus.Debugf("Data copied from %s to %s in container %s with image %s", volsrc, voldest, cid, image)
+		}
+	}
+	logrus.Debugf("Container %s created with image %s", cid, image)
+	defer e.StopAndRemoveContainer(cid)
+	if err = e.doJSON(http.MethodPost, "/containers/"+cid+"/start", nil, nil, nil); err != nil {
+		logrus.Debugf("Error during container startup of container %s : %s", cid, err)
+		return "", false, err
 	}
-	args = append(args, image)
-	if cmd != "" {
-		args = append(args, cmd)
+	var statusCode int
+	if err = e.doJSON(http.MethodPost, "/containers/"+cid+"/wait", nil, nil, &statusCode); err != nil {
+		logrus.Debugf("Error during waiting for container : %s", err)
+		return "", false, err
 	}
-	detectcmd := exec.Command("podman", args...)
-	logrus.Debugf("Running detect on image %s", image)
-	o, err := detectcmd.CombinedOutput()
+	logrus.Debugf("Container exited with status code: %#+v", statusCode)
+	resp, err := e.do(http.MethodGet, "/containers/"+cid+"/logs", url.Values{"stdout": []string{"true"}}, nil, "")
 	if err != nil {
-		logrus.Debugf("Detect failed %s : %s : %s", image, err, output)
-		return string(o), false, err
+		logrus.Debugf("Error while getting container logs : %s", err)
+		return "", true, err
+	}
+	defer resp.Body.Close()
+	var outBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&outBuf, io.Discard, resp.Body); err != nil {
+		logrus.Debugf("Error while reading container logs : %s", err)
+	}
+	logs := cast.ToString(outBuf.Bytes())
+	if statusCode != 0 {
+		return logs, true, fmt.Errorf("container execution terminated with error code : %d", statusCode)
+	}
+	return logs, true, nil
+}
+
+// splitImageReference splits an image reference into the repository and the tag
+func splitImageReference(image string) (repo, tag string) {
+	lastSlash := strings.LastIndex(image, "/")
+	lastColon := strings.LastIndex(image, ":")
+	if lastColon > lastSlash {
+		return image[:lastColon], image[lastColon+1:]
 	}
-	return string(o), true, nil
+	return image, ""
 }
@@ -0,0 +1,175 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package container
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func newFakePodmanServer(t *testing.T) (*httptest.Server, map[string]bool, *podmanContainerSpec) {
+	containers := map[string]bool{}
+	lastSpec := &podmanContainerSpec{}
+	prefix := "/" + podmanAPIVersion + "/libpod"
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+"/images/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, prefix+"/images/existing/image") {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"cause":"no such image","message":"no such image","response":404}`))
+	})
+	mux.HandleFunc(prefix+"/images/pull", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("reference") == "missing/image" {
+			w.Write([]byte(`{"error":"manifest unknown"}` + "\n"))
+			return
+		}
+		w.Write([]byte(`{"stream":"pulled"}` + "\n"))
+	})
+	mux.HandleFunc(prefix+"/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		spec := podmanContainerSpec{}
+		if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+			t.Fatalf("Failed to decode the container spec. Error: %q", err)
+		}
+		*lastSpec = spec
+		containers["cid1"] = false
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"cid1"}`))
+	})
+	mux.HandleFunc(prefix+"/containers/cid1/start", func(w http.ResponseWriter, r *http.Request) {
+		containers["cid1"] = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc(prefix+"/containers/cid1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Query().Get("force") != "true" {
+			t.Fatalf("Expected a forced delete. Actual: %s %s", r.Method, r.URL)
+		}
+		delete(containers, "cid1")
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc(prefix+"/containers/cid1/exec", func(w http.ResponseWriter, r *http.Request) {
+		execConfig := podmanExecConfig{}
+		if err := json.NewDecoder(r.Body).Decode(&execConfig); err != nil {
+			t.Fatalf("Failed to decode the exec config. Error: %q", err)
+		}
+		if execConfig.WorkingDir != "/m2k" || len(execConfig.Env) != 1 {
+			t.Fatalf("Unexpected exec config %+v", execConfig)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"Id":"exec1"}`))
+	})
+	mux.HandleFunc(prefix+"/exec/exec1/start", func(w http.ResponseWriter, r *http.Request) {
+		stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte("hello\n"))
+		stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte("warning\n"))
+	})
+	mux.HandleFunc(prefix+"/exec/exec1/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ExitCode":3,"Running":false}`))
+	})
+	return httptest.NewServer(mux), containers, lastSpec
+}
+
+func TestPodmanEngine(t *testing.T) {
+	server, containers, lastSpec := newFakePodmanServer(t)
+	defer server.Close()
+	e, err := newPodmanEngineWithHost("tcp://" + server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to create the podman engine. Error: %q", err)
+	}
+
+	t.Run("pull existing, pullable and missing images", func(t *testing.T) {
+		if !e.pullImage("existing/image") {
+			t.Fatalf("Expected the existing image to be available")
+		}
+		if !e.pullImage("quay.io/konveyor/hello-world") {
+			t.Fatalf("Expected the image to be pulled")
+		}
+		if e.pullImage("missing/image") {
+			t.Fatalf("Expected the pull of a missing image to fail")
+		}
+		if e.availableImages["missing/image"] {
+			t.Fatalf("The missing image should not be marked as available")
+		}
+	})
+
+	t.Run("create, exec in and remove a container", func(t *testing.T) {
+		cid, err := e.CreateContainer(environmenttypes.Container{
+			Image:     "existing/image",
+			Resources: environmenttypes.Resources{CPU: "500m", Memory: "64Mi", PIDs: 100},
+			Network:   environmenttypes.NetworkModeNone,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create the container. Error: %q", err)
+		}
+		if !containers[cid] {
+			t.Fatalf("Expected the container %s to be started", cid)
+		}
+		limits := lastSpec.ResourceLimits
+		if limits == nil || limits.CPU == nil || limits.CPU.Quota != 50000 || limits.CPU.Period != 100000 || limits.Memory == nil || limits.Memory.Limit != 64*1024*1024 || limits.Pids == nil || limits.Pids.Limit != 100 {
+			t.Fatalf("Unexpected resource limits in the container spec. Actual: %+v", limits)
+		}
+		if lastSpec.NetNS == nil || lastSpec.NetNS.NSMode != "none" {
+			t.Fatalf("Expected the network to be disabled. Actual: %+v", lastSpec.NetNS)
+		}
+		stdout, stderr, exitCode, err := e.RunCmdInContainer(cid, environmenttypes.Command{"ls"}, "/m2k", []string{"A=B"})
+		if err != nil {
+			t.Fatalf("Failed to run the command in the container. Error: %q", err)
+		}
+		if stdout != "hello\n" || stderr != "warning\n" || exitCode != 3 {
+			t.Fatalf("Unexpected output. stdout: %q stderr: %q exit code: %d", stdout, stderr, exitCode)
+		}
+		if err := e.StopAndRemoveContainer(cid); err != nil {
+			t.Fatalf("Failed to remove the container. Error: %q", err)
+		}
+		if _, ok := containers[cid]; ok {
+			t.Fatalf("Expected the container %s to be removed", cid)
+		}
+	})
+
+	t.Run("errors from the service are returned", func(t *testing.T) {
+		if _, err := e.CreateContainer(environmenttypes.Container{Image: "existing/image", Network: "overlay"}); err == nil {
+			t.Fatalf("Expected an error when creating a container with an unsupported network mode")
+		}
+		if _, err := e.CreateContainer(environmenttypes.Container{Image: "missing/image"}); err == nil {
+			t.Fatalf("Expected an error when creating a container from a missing image")
+		}
+		if err := e.StopAndRemoveContainer("unknown"); err == nil {
+			t.Fatalf("Expected an error when removing an unknown container")
+		}
+	})
+}
+
+func TestSplitImageReference(t *testing.T) {
+	testcases := []struct{ image, repo, tag string }{
+		{"quay.io/konveyor/move2kube", "quay.io/konveyor/move2kube", ""},
+		{"quay.io/konveyor/move2kube:v1", "quay.io/konveyor/move2kube", "v1"},
+		{"localhost:5000/foo", "localhost:5000/foo", ""},
+		{"localhost:5000/foo:bar", "localhost:5000/foo", "bar"},
+	}
+	for _, tc := range testcases {
+		repo, tag := splitImageReference(tc.image)
+		if repo != tc.repo || tag != tc.tag {
+			t.Fatalf("Failed to split %s . Expected: %s %s Actual: %s %s", tc.image, tc.repo, tc.tag, repo, tag)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment/container"
+	"github.com/konveyor/move2kube/qaengine"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxContainers = 4
+)
+
+var (
+	pool     *containerPool
+	poolOnce sync.Once
+)
+
+// containerPool reuses one warm container per image, resource limits and network mode across environments
+type containerPool struct {
+	cengine       container.ContainerEngine
+	mutex         sync.Mutex
+	cond          *sync.Cond
+	maxContainers int
+	containers    map[string]*pooledContainer
+	// creating stores the keys of the containers which are being created
+	creating map[string]bool
+	// users is the number of environments using each container
+	users       map[string]int
+	useCounter  uint64
+	generations int
+}
+
+// pooledContainer is a running container in the pool
+type pooledContainer struct {
+	key        string
+	image      string
+	cid        string
+	inUse      int
+	lastUsed   uint64
+	generation int
+	cengine    container.ContainerEngine
+	// syncMutex prevents the environments sharing the container from syncing at the same time
+	syncMutex sync.Mutex
+	// syncedPaths stores the state of the files synced into each directory in the container
+	syncedPaths map[string]map[string]syncedFileInfo
+}
+
+// syncedFileInfo stores the state of a file when it was synced into a container
+type syncedFileInfo struct {
+	size       int64
+	modTime    time.Time
+	mode       os.FileMode
+	linkTarget string
+}
+
+// isContainerReuseEnabled returns whether the containers should be reused across environments.
+// The reuse is opt-in, since the transformers sharing a container also share the processes and files outside the source.
+func isContainerReuseEnabled() bool {
+	return qaengine.FetchBoolAnswer(common.ConfigReuseContainersKey, "Reuse containers across transformers?", []string{"A single container is used for all the transformers using the same image and the source is synced incrementally."}, false)
+}
+
+// getContainerPool returns the container pool
+func getContainerPool() *containerPool {
+	poolOnce.Do(func() {
+		maxContainersStr := qaengine.FetchStringAnswer(common.ConfigMaxContainersKey, "Enter the maximum number of containers which can be running at the same time :", []string{"Idle containers are stopped when the limit is reached."}, strconv.Itoa(defaultMaxContainers))
+		maxContainers, err := strconv.Atoi(maxContainersStr)
+		if err != nil || maxContainers < 1 {
+			logrus.Warnf("Invalid maximum number of containers %s. Using the default %d", maxContainersStr, defaultMaxContainers)
+			maxContainers = defaultMaxContainers
+		}
+		pool = newContainerPool(maxContainers, container.GetContainerEngine())
+	})
+	return pool
+}
+
+func newContainerPool(maxContainers int, cengine container.ContainerEngine) *containerPool {
+	p := &containerPool{
+		cengine:       cengine,
+		maxContainers: maxContainers,
+		containers:    map[string]*pooledContainer{},
+		creating:      map[string]bool{},
+		users:         map[string]int{},
+	}
+	p.cond = sync.NewCond(&p.mutex)
+	return p
+}
+
+// getPoolKey returns the key of the container used for the container config.
+// Environments with different resource limits or network modes do not share containers.
+func getPoolKey(c environmenttypes.Container) string {
+	if c.Resources == (environmenttypes.Resources{}) && c.Network == "" {
+		return c.Image
+	}
+	return fmt.Sprintf("%s|%s|%s|%d|%s", c.Image, c.Resources.CPU, c.Resources.Memory, c.Resources.PIDs, c.Network)
+}
+
+// register records that an environment uses the container
+func (p *containerPool) register(c environmenttypes.Container) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.users[getPoolKey(c)]++
+}
+
+// unregister records that an environment no longer uses the container. The container is removed when it has no users.
+func (p *containerPool) unregister(c environmenttypes.Container) {
+	key := getPoolKey(c)
+	p.mutex.Lock()
+	p.users[key]--
+	if p.users[key] > 0 {
+		p.mutex.Unlock()
+		return
+	}
+	delete(p.users, key)
+	for p.containers[key] != nil && p.containers[key].inUse > 0 {
+		p.cond.Wait()
+	}
+	pc, ok := p.containers[key]
+	if ok {
+		p.detach(pc)
+	}
+	p.mutex.Unlock()
+	if ok {
+		p.stop(pc)
+	}
+}
+
+// acquire returns a running container for the image, creating it if required.
+// The container is not stopped until it is released.
+// The lock is only held for the bookkeeping, so that the containers of other images can be used while a container is created.
+func (p *containerPool) acquire(c environmenttypes.Container, context string) (*pooledContainer, error) {
+	key := getPoolKey(c)
+	p.mutex.Lock()
+	for {
+		if pc, ok := p.containers[key]; ok {
+			p.useCounter++
+			pc.inUse++
+			pc.lastUsed = p.useCounter
+			p.mutex.Unlock()
+			return pc, nil
+		}
+		if p.creating[key] {
+			p.cond.Wait()
+			continue
+		}
+		if len(p.containers)+len(p.creating) < p.maxContainers {
+			break
+		}
+		if idle := p.getLeastRecentlyUsedIdle(); idle != nil {
+			logrus.Debugf("Stopping the idle container of image %s since the limit of %d containers is reached", idle.image, p.maxContainers)
+			p.detach(idle)
+			p.mutex.Unlock()
+			p.stop(idle)
+			p.mutex.Lock()
+			continue
+		}
+		p.cond.Wait()
+	}
+	p.creating[key] = true
+	p.mutex.Unlock()
+	cid, err := p.createContainer(c, context)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.creating, key)
+	p.cond.Broadcast()
+	if err != nil {
+		return nil, err
+	}
+	p.useCounter++
+	p.generations++
+	pc := &pooledContainer{
+		key:         key,
+		image:       c.Image,
+		cid:         cid,
+		inUse:       1,
+		lastUsed:    p.useCounter,
+		generation:  p.generations,
+		cengine:     p.cengine,
+		syncedPaths: map[string]map[string]syncedFileInfo{},
+	}
+	p.containers[key] = pc
+	return pc, nil
+}
+
+// createContainer creates a container for the container config, building the image if it cannot be pulled
+func (p *containerPool) createContainer(c environmenttypes.Container, context string) (string, error) {
+	if p.cengine == nil {
+		return "", fmt.Errorf("no working container runtime found")
+	}
+	image, err := getTransformerImage(c, context)
+	if err != nil {
+		return "", err
+	}
+	imageContainer := c
+	imageContainer.Image = image
+	cid, err := p.cengine.CreateContainer(imageContainer)
+	if err != nil {
+		if c.ContainerBuild.Context == "" || image != c.Image {
+			return "", err
+		}
+		logrus.Debugf("Unable to create a container with image %s. Building the image : %s", c.Image, err)
+		if imageContainer.Image, err = buildTransformerImage(c, context); err != nil {
+			return "", err
+		}
+		return p.cengine.CreateContainer(imageContainer)
+	}
+	return cid, nil
+}
+
+// getExisting returns the running container for the container config, if any, without creating it
+func (p *containerPool) getExisting(c environmenttypes.Container) *pooledContainer {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pc, ok := p.containers[getPoolKey(c)]
+	if !ok {
+		return nil
+	}
+	pc.inUse++
+	return pc
+}
+
+// release releases a container acquired from the pool
+func (p *containerPool) release(pc *pooledContainer) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pc.inUse--
+	p.cond.Broadcast()
+}
+
+func (p *containerPool) getLeastRecentlyUsedIdle() *pooledContainer {
+	var lru *pooledContainer
+	for _, pc := range p.containers {
+		if pc.inUse == 0 && (lru == nil || pc.lastUsed < lru.lastUsed) {
+			lru = pc
+		}
+	}
+	return lru
+}
+
+// detach removes a container from the pool. The caller must hold the lock and stop the container after releasing it.
+func (p *containerPool) detach(pc *pooledContainer) {
+	delete(p.containers, pc.key)
+	p.cond.Broadcast()
+}
+
+// stop stops and removes a container detached from the pool
+func (p *containerPool) stop(pc *pooledContainer) {
+	if p.cengine == nil {
+		return
+	}
+	if err := p.cengine.StopAndRemoveContainer(pc.cid); err != nil {
+		logrus.Errorf("Unable to stop and remove container %s : %s", pc.cid, err)
+	}
+}
+
+// syncDir copies the files in src which changed since the last sync into dest in the container and removes the deleted files.
+// The environments must not modify dest, since the changes made in the container are not tracked.
+func (pc *pooledContainer) syncDir(src, dest string) error {
+	pc.syncMutex.Lock()
+	defer pc.syncMutex.Unlock()
+	cengine := pc.cengine
+	synced, ok := pc.syncedPaths[dest]
+	if !ok {
+		synced = map[string]syncedFileInfo{}
+		if _, stderr, exitcode, err := cengine.RunCmdInContainer(pc.cid, environmenttypes.Command{"mkdir", "-p", dest}, "", nil); err != nil || exitcode != 0 {
+			return fmt.Errorf("unable to create the directory %s in container %s : %s %s", dest, pc.cid, err, stderr)
+		}
+	}
+	stagingDir, err := os.MkdirTemp(common.TempPath, "sync-*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+	current := map[string]syncedFileInfo{}
+	numChanged := 0
+	err = filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." || fi.Mode()&os.ModeSocket != 0 {
+			return err
+		}
+		info, err := getSyncedFileInfo(path, fi)
+		if err != nil {
+			return err
+		}
+		current[rel] = info
+		if old, ok := synced[rel]; ok && old == info {
+			return nil
+		}
+		numChanged++
+		return stageFile(path, filepath.Join(stagingDir, rel), fi, info.linkTarget)
+	})
+	if err != nil {
+		logrus.Errorf("Unable to find the changed files in %s : %s", src, err)
+		return err
+	}
+	deleted := []string{}
+	for rel := range synced {
+		if _, ok := current[rel]; !ok {
+			deleted = append(deleted, filepath.Join(dest, rel))
+		}
+	}
+	if len(deleted) > 0 {
+		if _, stderr, exitcode, err := cengine.RunCmdInContainer(pc.cid, append(environmenttypes.Command{"rm", "-rf"}, deleted...), "", nil); err != nil || exitcode != 0 {
+			return fmt.Errorf("unable to remove the deleted files from container %s : %s %s", pc.cid, err, stderr)
+		}
+	}
+	if numChanged > 0 {
+		if err := cengine.CopyDirsIntoContainer(pc.cid, map[string]string{stagingDir: dest}); err != nil {
+			logrus.Errorf("Unable to copy the changed files into container %s : %s", pc.cid, err)
+			return err
+		}
+	}
+	logrus.Debugf("Synced %s into %s of container %s. %d changed and %d deleted", src, dest, pc.cid, numChanged, len(deleted))
+	pc.syncedPaths[dest] = current
+	return nil
+}
+
+// getSyncedFileInfo returns the state of a file, which is used to find out whether it changed
+func getSyncedFileInfo(path string, fi os.FileInfo) (info syncedFileInfo, err error) {
+	info.mode = fi.Mode()
+	if fi.Mode()&os.ModeSymlink != 0 {
+		info.linkTarget, err = os.Readlink(path)
+	} else if !fi.IsDir() {
+		info.size = fi.Size()
+		info.modTime = fi.ModTime()
+	}
+	return info, err
+}
+
+// stageFile copies a file, directory or symlink into the staging directory
+func stageFile(path, stagedPath string, fi os.FileInfo, linkTarget string) error {
+	if fi.IsDir() {
+		return os.MkdirAll(stagedPath, fi.Mode().Perm())
+	}
+	if err := os.MkdirAll(filepath.Dir(stagedPath), common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	if linkTarget != "" {
+		return os.Symlink(linkTarget, stagedPath)
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(stagedPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
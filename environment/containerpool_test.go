@@ -0,0 +1,403 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+// fakeContainerEngine runs the commands on the host, with the file system of each container in a separate directory
+type fakeContainerEngine struct {
+	dir string
+
+	mutex       sync.Mutex
+	roots       map[string]string
+	numCopiesIn int
+	// blockCreate blocks the creation of the containers of an image until it is closed
+	blockCreate map[string]chan struct{}
+}
+
+func newFakeContainerEngine(t *testing.T) *fakeContainerEngine {
+	return &fakeContainerEngine{dir: t.TempDir(), roots: map[string]string{}, blockCreate: map[string]chan struct{}{}}
+}
+
+func (e *fakeContainerEngine) root(containerID string) string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.roots[containerID]
+}
+
+func (e *fakeContainerEngine) hostPath(containerID, path string) string {
+	return filepath.Join(e.root(containerID), path)
+}
+
+func (e *fakeContainerEngine) RunCmdInContainer(containerID string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error) {
+	if e.root(containerID) == "" {
+		return "", "", 0, fmt.Errorf("container %s not found", containerID)
+	}
+	args := []string{}
+	for _, arg := range cmd[1:] {
+		if filepath.IsAbs(arg) {
+			// The path is not cleaned since the trailing /. of the cp source is significant
+			arg = e.root(containerID) + arg
+		}
+		args = append(args, arg)
+	}
+	execcmd := exec.Command(cmd[0], args...)
+	if workingdir != "" {
+		execcmd.Dir = e.hostPath(containerID, workingdir)
+		if err := os.MkdirAll(execcmd.Dir, common.DefaultDirectoryPermission); err != nil {
+			return "", "", 0, err
+		}
+	}
+	output, err := execcmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return "", string(output), exitErr.ExitCode(), nil
+	}
+	return string(output), "", 0, err
+}
+
+func (e *fakeContainerEngine) InspectImage(image string) (dockertypes.ImageInspect, error) {
+	return dockertypes.ImageInspect{}, nil
+}
+
+func (e *fakeContainerEngine) CopyDirsIntoImage(image, newImageName string, paths map[string]string) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *fakeContainerEngine) CopyDirsIntoContainer(containerID string, paths map[string]string) error {
+	e.mutex.Lock()
+	e.numCopiesIn++
+	e.mutex.Unlock()
+	for src, dest := range paths {
+		if err := copyFake(src, e.hostPath(containerID, dest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *fakeContainerEngine) CopyDirsFromContainer(containerID string, paths map[string]string) error {
+	for src, dest := range paths {
+		if err := copyFake(e.hostPath(containerID, src), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *fakeContainerEngine) BuildImage(image, context, dockerfile string) error {
+	return fmt.Errorf("not supported")
+}
+
+func (e *fakeContainerEngine) RemoveImage(image string) error {
+	return nil
+}
+
+func (e *fakeContainerEngine) CreateContainer(c environmenttypes.Container) (string, error) {
+	e.mutex.Lock()
+	block := e.blockCreate[c.Image]
+	e.mutex.Unlock()
+	if block != nil {
+		<-block
+	}
+	root, err := os.MkdirTemp(e.dir, "container-*")
+	if err != nil {
+		return "", err
+	}
+	containerID := filepath.Base(root)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.roots[containerID] = root
+	return containerID, nil
+}
+
+func (e *fakeContainerEngine) StopAndRemoveContainer(containerID string) error {
+	root := e.root(containerID)
+	e.mutex.Lock()
+	delete(e.roots, containerID)
+	e.mutex.Unlock()
+	return os.RemoveAll(root)
+}
+
+func (e *fakeContainerEngine) RunContainer(c environmenttypes.Container, cmd environmenttypes.Command, volsrc string, voldest string) (string, bool, error) {
+	return "", false, fmt.Errorf("not supported")
+}
+
+// copyFake copies a file or the contents of a directory like the container engines
+func copyFake(src, dest string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	args := []string{"-a", src, dest}
+	if fi.IsDir() {
+		args = []string{"-a", src + string(filepath.Separator) + ".", dest}
+		if err := os.MkdirAll(dest, common.DefaultDirectoryPermission); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(filepath.Dir(dest), common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	if output, err := exec.Command("cp", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s : %s", err, output)
+	}
+	return nil
+}
+
+// useFakeContainerPool replaces the container pool with one using a fake container engine
+func useFakeContainerPool(t *testing.T, maxContainers int) *fakeContainerEngine {
+	common.TempPath = t.TempDir()
+	cengine := newFakeContainerEngine(t)
+	poolOnce.Do(func() {})
+	pool = newContainerPool(maxContainers, cengine)
+	return cengine
+}
+
+func writeTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the file %s . Error: %q", path, err)
+	}
+}
+
+// checkTestFiles checks that the directory contains exactly the files with the contents
+func checkTestFiles(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	got := map[string]string{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		got[rel] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read the files in %s . Error: %q", dir, err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("the directory %s has the wrong files. Expected: %v Actual: %v", dir, want, got)
+	}
+	for rel, contents := range want {
+		if got[rel] != contents {
+			t.Fatalf("the directory %s has the wrong files. Expected: %v Actual: %v", dir, want, got)
+		}
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	cengine := useFakeContainerPool(t, 1)
+	pc, err := pool.acquire(environmenttypes.Container{Image: "test"}, "")
+	if err != nil {
+		t.Fatalf("failed to acquire a container. Error: %q", err)
+	}
+	defer pool.release(pc)
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "a.txt"), "a")
+	writeTestFile(t, filepath.Join(src, "dir", "b.txt"), "b")
+	dest := "/synced"
+
+	t.Run("sync all the files", func(t *testing.T) {
+		if err := pc.syncDir(src, dest); err != nil {
+			t.Fatalf("failed to sync the directory. Error: %q", err)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, dest), map[string]string{"a.txt": "a", filepath.Join("dir", "b.txt"): "b"})
+	})
+
+	t.Run("sync without changes", func(t *testing.T) {
+		numCopiesIn := cengine.numCopiesIn
+		if err := pc.syncDir(src, dest); err != nil {
+			t.Fatalf("failed to sync the directory. Error: %q", err)
+		}
+		if cengine.numCopiesIn != numCopiesIn {
+			t.Fatalf("should not have copied anything into the container since nothing changed")
+		}
+	})
+
+	t.Run("sync changed, added and deleted files", func(t *testing.T) {
+		writeTestFile(t, filepath.Join(src, "a.txt"), "changed")
+		writeTestFile(t, filepath.Join(src, "c.txt"), "c")
+		if err := os.RemoveAll(filepath.Join(src, "dir")); err != nil {
+			t.Fatalf("failed to remove the directory. Error: %q", err)
+		}
+		if err := pc.syncDir(src, dest); err != nil {
+			t.Fatalf("failed to sync the directory. Error: %q", err)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, dest), map[string]string{"a.txt": "changed", "c.txt": "c"})
+		if _, err := os.Stat(cengine.hostPath(pc.cid, filepath.Join(dest, "dir"))); !os.IsNotExist(err) {
+			t.Fatalf("the deleted directory should have been removed from the container. Error: %v", err)
+		}
+	})
+}
+
+func TestPooledContainer(t *testing.T) {
+	cengine := useFakeContainerPool(t, 1)
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "a.txt"), "a")
+	c := environmenttypes.Container{Image: "test"}
+	newEnv := func(t *testing.T) *PooledContainer {
+		t.Helper()
+		ei, err := NewPooledContainer(EnvInfo{Name: "test", Source: src, TempPath: t.TempDir()}, nil, c)
+		if err != nil {
+			t.Fatalf("failed to create the environment. Error: %q", err)
+		}
+		return ei.(*PooledContainer)
+	}
+	env1 := newEnv(t)
+	env2 := newEnv(t)
+	pc := pool.getExisting(c)
+	pool.release(pc)
+	if pc == nil {
+		t.Fatalf("the environments should be using a container from the pool")
+	}
+
+	t.Run("changes are not seen by other environments", func(t *testing.T) {
+		if _, stderr, exitcode, err := env1.Exec(environmenttypes.Command{"rm", filepath.Join(env1.GetSource(), "a.txt")}); err != nil || exitcode != 0 {
+			t.Fatalf("failed to execute the command. Error: %v %s", err, stderr)
+		}
+		if _, stderr, exitcode, err := env1.Exec(environmenttypes.Command{"touch", filepath.Join(env1.GetSource(), "new.txt")}); err != nil || exitcode != 0 {
+			t.Fatalf("failed to execute the command. Error: %v %s", err, stderr)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, env1.GetSource()), map[string]string{"new.txt": ""})
+		checkTestFiles(t, cengine.hostPath(pc.cid, env2.GetSource()), map[string]string{"a.txt": "a"})
+		checkTestFiles(t, cengine.hostPath(pc.cid, env2.SyncedSource), map[string]string{"a.txt": "a"})
+	})
+
+	t.Run("reset discards the changes", func(t *testing.T) {
+		if err := env1.Reset(); err != nil {
+			t.Fatalf("failed to reset the environment. Error: %q", err)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, env1.GetSource()), map[string]string{"a.txt": "a"})
+	})
+
+	t.Run("reset syncs the changes in the source", func(t *testing.T) {
+		writeTestFile(t, filepath.Join(src, "a.txt"), "changed")
+		if err := env1.Reset(); err != nil {
+			t.Fatalf("failed to reset the environment. Error: %q", err)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, env1.GetSource()), map[string]string{"a.txt": "changed"})
+	})
+
+	t.Run("uploaded paths are removed on reset", func(t *testing.T) {
+		uploadDir := t.TempDir()
+		writeTestFile(t, filepath.Join(uploadDir, "up.txt"), "up")
+		envpath, err := env1.Upload(uploadDir)
+		if err != nil {
+			t.Fatalf("failed to upload. Error: %q", err)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, envpath), map[string]string{"up.txt": "up"})
+		if err := env1.Reset(); err != nil {
+			t.Fatalf("failed to reset the environment. Error: %q", err)
+		}
+		if _, err := os.Stat(cengine.hostPath(pc.cid, envpath)); !os.IsNotExist(err) {
+			t.Fatalf("the uploaded path %s should have been removed. Error: %v", envpath, err)
+		}
+	})
+
+	t.Run("destroy removes the workspace and the container", func(t *testing.T) {
+		env1.Destroy()
+		if _, err := os.Stat(cengine.hostPath(pc.cid, env1.GetSource())); !os.IsNotExist(err) {
+			t.Fatalf("the workspace %s should have been removed. Error: %v", env1.GetSource(), err)
+		}
+		checkTestFiles(t, cengine.hostPath(pc.cid, env2.GetSource()), map[string]string{"a.txt": "a"})
+		env2.Destroy()
+		if root := cengine.root(pc.cid); root != "" {
+			t.Fatalf("the container %s should have been removed after all the environments using it were destroyed", pc.cid)
+		}
+	})
+}
+
+func TestContainerPoolAcquire(t *testing.T) {
+	t.Run("other images can be used while a container is created", func(t *testing.T) {
+		cengine := useFakeContainerPool(t, 2)
+		cengine.blockCreate["slow"] = make(chan struct{})
+		slowAcquired := make(chan error)
+		go func() {
+			pc, err := pool.acquire(environmenttypes.Container{Image: "slow"}, "")
+			if err == nil {
+				pool.release(pc)
+			}
+			slowAcquired <- err
+		}()
+		fastAcquired := make(chan error)
+		go func() {
+			pc, err := pool.acquire(environmenttypes.Container{Image: "fast"}, "")
+			if err == nil {
+				pool.release(pc)
+			}
+			fastAcquired <- err
+		}()
+		select {
+		case err := <-fastAcquired:
+			if err != nil {
+				t.Fatalf("failed to acquire a container. Error: %q", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("acquiring a container should not wait for the creation of a container of another image")
+		}
+		close(cengine.blockCreate["slow"])
+		if err := <-slowAcquired; err != nil {
+			t.Fatalf("failed to acquire a container. Error: %q", err)
+		}
+	})
+
+	t.Run("the least recently used idle container is stopped at the limit", func(t *testing.T) {
+		cengine := useFakeContainerPool(t, 1)
+		pc1, err := pool.acquire(environmenttypes.Container{Image: "image1"}, "")
+		if err != nil {
+			t.Fatalf("failed to acquire a container. Error: %q", err)
+		}
+		pool.release(pc1)
+		pc2, err := pool.acquire(environmenttypes.Container{Image: "image2"}, "")
+		if err != nil {
+			t.Fatalf("failed to acquire a container. Error: %q", err)
+		}
+		defer pool.release(pc2)
+		if cengine.root(pc1.cid) != "" {
+			t.Fatalf("the idle container %s should have been stopped", pc1.cid)
+		}
+		if len(pool.containers) != 1 || pool.containers["image2"] != pc2 {
+			t.Fatalf("the pool should only have the container of image2. Actual: %+v", pool.containers)
+		}
+	})
+}
+
+func TestIsContainerReuseEnabled(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	if isContainerReuseEnabled() {
+		t.Fatalf("Expected the containers to not be reused by default")
+	}
+}
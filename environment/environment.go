@@ -85,7 +85,16 @@ type EnvironmentInstance interface {
 }
 
 // NewEnvironment creates a new environment
-func NewEnvironment(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes.Container) (env *Environment, err error) {
+func NewEnvironment(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes.Container, r environmenttypes.Remote) (env *Environment, err error) {
+	if err := c.Validate(); err != nil {
+		logrus.Errorf("Invalid container config for the environment %s : %s", envInfo.Name, err)
+		return env, err
+	}
+	if r.Host != "" && c.Image != "" {
+		err := fmt.Errorf("both a container image and a remote host are specified for the environment %s", envInfo.Name)
+		logrus.Errorf("%s", err)
+		return env, err
+	}
 	tempPath, err := os.MkdirTemp(common.TempPath, "environment-"+envInfo.Name+"-*")
 	if err != nil {
 		logrus.Errorf("Unable to create temp dir : %s", err)
@@ -98,33 +107,59 @@ func NewEnvironment(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes
 		TempPathsMap: map[string]string{},
 		active:       true,
 	}
+	if common.ReplayEnvironmentsPath != "" {
+		env.Env, err = NewReplayEnvironment(envInfo, common.ReplayEnvironmentsPath)
+		if err != nil {
+			logrus.Errorf("Unable to create replay environment : %s", err)
+		}
+		return env, err
+	}
+	if common.RecordEnvironmentsPath != "" {
+		defer func() {
+			if err != nil || env.Env == nil {
+				return
+			}
+			env.Env, err = NewRecordingEnvironment(envInfo, env.Env, common.RecordEnvironmentsPath)
+			if err != nil {
+				logrus.Errorf("Unable to create recording environment : %s", err)
+			}
+		}()
+	}
+	if r.Host != "" {
+		env.Env, err = NewRemote(envInfo, grpcQAReceiver, r)
+		if err != nil {
+			logrus.Errorf("Unable to create remote environment : %s", err)
+		}
+		return env, err
+	}
 	if c.Image != "" {
-		envVariableName := common.MakeStringEnvNameCompliant(c.Image)
 		// Check if image is part of the current environment.
 		// It will be set as environment variable with root as base path of move2kube
 		// When running in a process shared environment the environment variable will point to the base pid of the container for the image
-		envvars := os.Environ()
-		for _, envvar := range envvars {
-			envvarpair := strings.SplitN(envvar, "=", 2)
-			if len(envvarpair) > 0 && envVariableName == c.Image && len(envvarpair) > 1 {
-				_, err := strconv.Atoi(envvarpair[1])
+		if value, ok := lookupImageEnv(c.Image); ok {
+			pid, err := strconv.Atoi(value)
+			if err != nil {
+				envInfo.Context = value
+				env.Env, err = NewLocal(envInfo, grpcQAReceiver, c)
 				if err != nil {
-					envInfo.Context = envvarpair[1]
-					env.Env, err = NewLocal(envInfo, grpcQAReceiver)
-					if err != nil {
-						logrus.Errorf("Unable to create local environment : %s", err)
-					}
-					return env, err
-				} /*else {
-					env.Env, err = NewProcessSharedContainer(name, source, pid)
-					if err != nil {
-						logrus.Errorf("Unable to create process shared environment : %s", err)
-					}
-					return env, err
-				}*/
+					logrus.Errorf("Unable to create local environment : %s", err)
+				}
+				return env, err
+			}
+			env.Env, err = NewProcessSharedContainer(envInfo, grpcQAReceiver, c, pid)
+			if err != nil {
+				logrus.Errorf("Unable to create process shared environment : %s", err)
 			}
+			return env, err
 		}
 		if env.Env == nil {
+			if !envInfo.Isolated && !container.IsDisabled() && container.GetContainerEngine() != nil && isContainerReuseEnabled() {
+				env.Env, err = NewPooledContainer(envInfo, grpcQAReceiver, c)
+				if err != nil {
+					logrus.Errorf("Unable to create pooled container environment : %s", err)
+				}
+				return env, err
+			}
 			env.Env, err = NewPeerContainer(envInfo, grpcQAReceiver, c)
 			if err != nil && !container.IsDisabled() {
 				logrus.Errorf("Unable to create peer container environment : %s", err)
@@ -132,7 +167,7 @@ func NewEnvironment(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes
 			return env, err
 		}
 	}
-	env.Env, err = NewLocal(envInfo, grpcQAReceiver)
+	env.Env, err = NewLocal(envInfo, grpcQAReceiver, c)
 	if err != nil {
 		logrus.Errorf("Unable to create Local environment : %s", err)
 	}
@@ -509,3 +544,28 @@ func (e *Environment) IsPathValid(path string) bool {
 	}
 	return false
 }
+
+// IsPathWritable returns if the path can be written to by the transformer.
+// When the local execution is sandboxed, only the temp and output paths of the environment are writable.
+func (e *Environment) IsPathWritable(path string) bool {
+	if !common.SandboxLocalExecution {
+		return e.IsPathValid(path)
+	}
+	cleanpath := filepath.Clean(path)
+	if resolvedDir, err := filepath.EvalSymlinks(filepath.Dir(cleanpath)); err == nil {
+		cleanpath = filepath.Join(resolvedDir, filepath.Base(cleanpath))
+	}
+	writablePaths := []string{e.TempPath, e.GetEnvironmentOutput()}
+	for _, writablePath := range writablePaths {
+		if writablePath == "" {
+			continue
+		}
+		if resolvedPath, err := filepath.EvalSymlinks(writablePath); err == nil {
+			writablePath = resolvedPath
+		}
+		if common.IsParent(cleanpath, writablePath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment/container"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// transformerImagesCacheFile stores the transformer images built from build contexts
+	transformerImagesCacheFile = "transformerimages.yaml"
+	// transformerImageTagPrefix is the prefix of the tags of the transformer images built from build contexts
+	transformerImageTagPrefix  = types.AppNameShort + "-"
+	transformerImageHashLength = 20
+)
+
+var (
+	transformerImagesMutex sync.Mutex
+	// builtTransformerImages are the transformer images built during this run
+	builtTransformerImages = map[string]bool{}
+)
+
+// transformerImageCache stores the transformer images built from build contexts
+type transformerImageCache struct {
+	Images []cachedTransformerImage `yaml:"images"`
+}
+
+// cachedTransformerImage is a transformer image built from a build context
+type cachedTransformerImage struct {
+	Image    string    `yaml:"image"`
+	Engine   string    `yaml:"engine"`
+	Context  string    `yaml:"context"`
+	LastUsed time.Time `yaml:"lastUsed"`
+}
+
+// getTransformerImage returns the image to start the container from.
+// Images built from the build context are tagged with a hash of the build context and reused across runs.
+// If there is no such image, the image of the container is used, unless the transformer images have to be rebuilt.
+func getTransformerImage(c environmenttypes.Container, context string) (string, error) {
+	if c.ContainerBuild.Context == "" {
+		return c.Image, nil
+	}
+	if common.RebuildTransformerImages {
+		return buildTransformerImage(c, context)
+	}
+	image, err := getTransformerImageName(c, context)
+	if err != nil {
+		logrus.Errorf("Unable to get the name of the image built from the build context of %s : %s", c.Image, err)
+		return c.Image, err
+	}
+	if _, err := container.GetContainerEngine().InspectImage(image); err != nil {
+		logrus.Debugf("Image %s built from the current build context of %s not found : %s", image, c.Image, err)
+		return c.Image, nil
+	}
+	logrus.Debugf("Using the image %s built from the build context of %s", image, c.Image)
+	recordTransformerImageUse(image, filepath.Join(context, c.ContainerBuild.Context))
+	return image, nil
+}
+
+// buildTransformerImage builds the image from the build context of the container, unless it was already built during this run
+func buildTransformerImage(c environmenttypes.Container, context string) (string, error) {
+	image, err := getTransformerImageName(c, context)
+	if err != nil {
+		logrus.Errorf("Unable to get the name of the image built from the build context of %s : %s", c.Image, err)
+		return c.Image, err
+	}
+	transformerImagesMutex.Lock()
+	defer transformerImagesMutex.Unlock()
+	buildContext := filepath.Join(context, c.ContainerBuild.Context)
+	if !builtTransformerImages[image] {
+		if err := container.GetContainerEngine().BuildImage(image, buildContext, c.ContainerBuild.Dockerfile); err != nil {
+			logrus.Errorf("Unable to build new container image for %s : %s", c.Image, err)
+			return c.Image, err
+		}
+		builtTransformerImages[image] = true
+	}
+	recordTransformerImageUse(image, buildContext)
+	return image, nil
+}
+
+// getTransformerImageName returns the name of the image built from the build context of the container.
+// The image has the repository of the image of the container and is tagged with a hash of the build context.
+func getTransformerImageName(c environmenttypes.Container, context string) (string, error) {
+	hash, err := getBuildContextHash(filepath.Join(context, c.ContainerBuild.Context), c.ContainerBuild.Dockerfile)
+	if err != nil {
+		return "", err
+	}
+	repo := c.Image
+	if idx := strings.Index(repo, "@"); idx != -1 {
+		repo = repo[:idx]
+	}
+	if lastColon := strings.LastIndex(repo, ":"); lastColon > strings.LastIndex(repo, "/") {
+		repo = repo[:lastColon]
+	}
+	return repo + ":" + transformerImageTagPrefix + hash[:transformerImageHashLength], nil
+}
+
+// getBuildContextHash returns a hash of the paths, modes and contents of all the files in the build context
+func getBuildContextHash(buildContext, dockerfile string) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "dockerfile %s\n", dockerfile)
+	err := filepath.Walk(buildContext, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(buildContext, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s %s\n", filepath.ToSlash(rel), fi.Mode())
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\n", target)
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(hash, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// recordTransformerImageUse records in the cache that the transformer image was used
+func recordTransformerImageUse(image, buildContext string) {
+	cache, err := readTransformerImageCache()
+	if err != nil {
+		logrus.Debugf("Unable to read the transformer image cache : %s", err)
+	}
+	engineName := container.GetContainerEngineName()
+	cachedImage := cachedTransformerImage{Image: image, Engine: engineName, Context: buildContext, LastUsed: time.Now()}
+	found := false
+	for i, ci := range cache.Images {
+		if ci.Image == image && ci.Engine == engineName {
+			cache.Images[i] = cachedImage
+			found = true
+			break
+		}
+	}
+	if !found {
+		cache.Images = append(cache.Images, cachedImage)
+	}
+	if err := writeTransformerImageCache(cache); err != nil {
+		logrus.Warnf("Unable to record the use of the transformer image %s in the cache : %s", image, err)
+	}
+}
+
+// PruneTransformerImages removes the transformer images built from build contexts which were not used within the given duration.
+// All the images are removed if the duration is 0.
+func PruneTransformerImages(unusedFor time.Duration) (removedImages []string, err error) {
+	cache, err := readTransformerImageCache()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		logrus.Errorf("Unable to read the transformer image cache : %s", err)
+		return nil, err
+	}
+	engines := map[string]container.ContainerEngine{}
+	keptImages := []cachedTransformerImage{}
+	for _, ci := range cache.Images {
+		if unusedFor != 0 && time.Since(ci.LastUsed) < unusedFor {
+			keptImages = append(keptImages, ci)
+			continue
+		}
+		cengine, ok := engines[ci.Engine]
+		if !ok {
+			if cengine, err = container.NewContainerEngine(ci.Engine); err != nil {
+				logrus.Errorf("Unable to use the container engine %s to remove the image %s : %s", ci.Engine, ci.Image, err)
+			}
+			engines[ci.Engine] = cengine
+		}
+		if cengine == nil {
+			keptImages = append(keptImages, ci)
+			continue
+		}
+		if err := cengine.RemoveImage(ci.Image); err != nil {
+			if _, inspectErr := cengine.InspectImage(ci.Image); inspectErr == nil {
+				logrus.Errorf("Unable to remove the image %s : %s", ci.Image, err)
+				keptImages = append(keptImages, ci)
+				continue
+			}
+			logrus.Debugf("The image %s was already removed", ci.Image)
+		}
+		removedImages = append(removedImages, ci.Image)
+	}
+	cache.Images = keptImages
+	if err := writeTransformerImageCache(cache); err != nil {
+		logrus.Errorf("Unable to write the transformer image cache : %s", err)
+		return removedImages, err
+	}
+	return removedImages, nil
+}
+
+func getTransformerImageCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, types.AppName, transformerImagesCacheFile), nil
+}
+
+func readTransformerImageCache() (transformerImageCache, error) {
+	cache := transformerImageCache{}
+	cachePath, err := getTransformerImageCachePath()
+	if err != nil {
+		return cache, err
+	}
+	err = common.ReadYaml(cachePath, &cache)
+	return cache, err
+}
+
+func writeTransformerImageCache(cache transformerImageCache) error {
+	cachePath, err := getTransformerImageCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	return common.WriteYaml(cachePath, cache)
+}
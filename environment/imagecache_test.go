@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+// newTestBuildContext creates a build context with a Dockerfile and a file
+func newTestBuildContext(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "Dockerfile"), "FROM alpine\nCOPY . /app\n")
+	writeTestFile(t, filepath.Join(dir, "app", "main.sh"), "echo hello\n")
+	return dir
+}
+
+func TestGetBuildContextHash(t *testing.T) {
+	getHash := func(t *testing.T, dir, dockerfile string) string {
+		t.Helper()
+		hash, err := getBuildContextHash(dir, dockerfile)
+		if err != nil {
+			t.Fatalf("failed to get the hash of the build context %s . Error: %q", dir, err)
+		}
+		return hash
+	}
+	want := getHash(t, newTestBuildContext(t), "Dockerfile")
+
+	t.Run("identical build contexts", func(t *testing.T) {
+		if hash := getHash(t, newTestBuildContext(t), "Dockerfile"); hash != want {
+			t.Fatalf("the hash should be the same for identical build contexts. Expected: %s Actual: %s", want, hash)
+		}
+	})
+
+	testcases := []struct {
+		name       string
+		dockerfile string
+		change     func(t *testing.T, dir string)
+	}{
+		{name: "changed file", change: func(t *testing.T, dir string) {
+			writeTestFile(t, filepath.Join(dir, "app", "main.sh"), "echo world\n")
+		}},
+		{name: "added file", change: func(t *testing.T, dir string) {
+			writeTestFile(t, filepath.Join(dir, "app", "other.sh"), "")
+		}},
+		{name: "renamed file", change: func(t *testing.T, dir string) {
+			if err := os.Rename(filepath.Join(dir, "app", "main.sh"), filepath.Join(dir, "app", "start.sh")); err != nil {
+				t.Fatalf("failed to rename the file. Error: %q", err)
+			}
+		}},
+		{name: "changed mode", change: func(t *testing.T, dir string) {
+			if err := os.Chmod(filepath.Join(dir, "app", "main.sh"), 0755); err != nil {
+				t.Fatalf("failed to change the mode of the file. Error: %q", err)
+			}
+		}},
+		{name: "added symlink", change: func(t *testing.T, dir string) {
+			if err := os.Symlink("main.sh", filepath.Join(dir, "app", "link.sh")); err != nil {
+				t.Fatalf("failed to create the symlink. Error: %q", err)
+			}
+		}},
+		{name: "different dockerfile", dockerfile: "Containerfile"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestBuildContext(t)
+			dockerfile := "Dockerfile"
+			if tc.dockerfile != "" {
+				dockerfile = tc.dockerfile
+			}
+			if tc.change != nil {
+				tc.change(t, dir)
+			}
+			if hash := getHash(t, dir, dockerfile); hash == want {
+				t.Fatalf("the hash should change when the build context changes")
+			}
+		})
+	}
+
+	t.Run("missing build context", func(t *testing.T) {
+		if _, err := getBuildContextHash(filepath.Join(t.TempDir(), "missing"), "Dockerfile"); err == nil {
+			t.Fatalf("should have failed since the build context does not exist")
+		}
+	})
+}
+
+func TestGetTransformerImageName(t *testing.T) {
+	context := newTestBuildContext(t)
+	hash, err := getBuildContextHash(context, "Dockerfile")
+	if err != nil {
+		t.Fatalf("failed to get the hash of the build context. Error: %q", err)
+	}
+	tag := transformerImageTagPrefix + hash[:transformerImageHashLength]
+	testcases := []struct {
+		image string
+		want  string
+	}{
+		{image: "quay.io/konveyor/transformer", want: "quay.io/konveyor/transformer:" + tag},
+		{image: "quay.io/konveyor/transformer:latest", want: "quay.io/konveyor/transformer:" + tag},
+		{image: "localhost:5000/transformer", want: "localhost:5000/transformer:" + tag},
+		{image: "localhost:5000/transformer:v1", want: "localhost:5000/transformer:" + tag},
+		{image: "transformer@sha256:0123456789abcdef", want: "transformer:" + tag},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.image, func(t *testing.T) {
+			c := environmenttypes.Container{Image: tc.image, ContainerBuild: environmenttypes.ContainerBuild{Context: ".", Dockerfile: "Dockerfile"}}
+			image, err := getTransformerImageName(c, context)
+			if err != nil {
+				t.Fatalf("failed to get the image name. Error: %q", err)
+			}
+			if image != tc.want {
+				t.Fatalf("the image name is incorrect. Expected: %s Actual: %s", tc.want, image)
+			}
+		})
+	}
+
+	t.Run("the tag is stable and changes with the build context", func(t *testing.T) {
+		c := environmenttypes.Container{Image: "transformer", ContainerBuild: environmenttypes.ContainerBuild{Context: ".", Dockerfile: "Dockerfile"}}
+		image1, err := getTransformerImageName(c, context)
+		if err != nil {
+			t.Fatalf("failed to get the image name. Error: %q", err)
+		}
+		image2, err := getTransformerImageName(c, context)
+		if err != nil {
+			t.Fatalf("failed to get the image name. Error: %q", err)
+		}
+		if image1 != image2 {
+			t.Fatalf("the image name should be stable. Actual: %s and %s", image1, image2)
+		}
+		writeTestFile(t, filepath.Join(context, "app", "main.sh"), "echo world\n")
+		image3, err := getTransformerImageName(c, context)
+		if err != nil {
+			t.Fatalf("failed to get the image name. Error: %q", err)
+		}
+		if image3 == image1 || !strings.HasPrefix(image3, "transformer:"+transformerImageTagPrefix) {
+			t.Fatalf("the image name should have a new tag when the build context changes. Actual: %s", image3)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// rlimitInitName is the name used to re-execute move2kube to set the rlimits before executing the command
+	rlimitInitName = types.AppNameShort + "-rlimit-init"
+)
+
+var (
+	// rlimitMemoryEnvName is used to pass the address space limit to the rlimit init
+	rlimitMemoryEnvName = strings.ToUpper(types.AppNameShort) + "_RLIMIT_AS"
+	// rlimitPIDsEnvName is used to pass the process limit to the rlimit init
+	rlimitPIDsEnvName = strings.ToUpper(types.AppNameShort) + "_RLIMIT_NPROC"
+)
+
+func init() {
+	reexec.Register(rlimitInitName, rlimitInit)
+}
+
+// getLimitedCommand returns a command which runs with the resource limits approximated using rlimits.
+// The memory limit limits the address space and the pids limit limits the number of processes of the user.
+// RLIMIT_NPROC counts all the processes of the user, not only those of the command, and is not enforced for root.
+// There is no rlimit for the cpu share, so the cpu limit is ignored.
+// If the network mode is none, the command is run in new user and network namespaces.
+func getLimitedCommand(execcmd *exec.Cmd, resources environmenttypes.Resources, network environmenttypes.NetworkMode, sandboxed bool) (*exec.Cmd, error) {
+	if execcmd.Err != nil {
+		return nil, execcmd.Err
+	}
+	if resources.CPU != "" {
+		logrus.Debugf("The cpu limit %s is not supported for local execution. Ignoring it.", resources.CPU)
+	}
+	if network == environmenttypes.NetworkModeNone && !sandboxed {
+		if execcmd.SysProcAttr == nil {
+			execcmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+		execcmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+		execcmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		execcmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+		execcmd.SysProcAttr.GidMappingsEnableSetgroups = false
+	} else if network != "" && network != environmenttypes.NetworkModeNone && sandboxed {
+		logrus.Debugf("The network mode %s is ignored since sandboxed local execution has no network access", network)
+	}
+	memory, err := resources.GetMemoryBytes()
+	if err != nil {
+		return nil, err
+	}
+	if memory == 0 && resources.PIDs == 0 {
+		return execcmd, nil
+	}
+	env := execcmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	if memory > 0 {
+		env = append(env, rlimitMemoryEnvName+"="+strconv.FormatInt(memory, 10))
+	}
+	if resources.PIDs > 0 {
+		if os.Getuid() == 0 {
+			logrus.Debugf("The pids limit %d is not enforced since the process limit does not apply to root", resources.PIDs)
+		}
+		env = append(env, rlimitPIDsEnvName+"="+strconv.FormatInt(resources.PIDs, 10))
+	}
+	limitedcmd := reexec.Command(append([]string{rlimitInitName, execcmd.Path}, execcmd.Args...)...)
+	limitedcmd.Dir = execcmd.Dir
+	limitedcmd.Env = env
+	if execcmd.SysProcAttr != nil {
+		limitedcmd.SysProcAttr = execcmd.SysProcAttr
+	}
+	return limitedcmd, nil
+}
+
+// rlimitInit sets the rlimits and then executes the command
+func rlimitInit() {
+	limits := map[string]int{rlimitMemoryEnvName: unix.RLIMIT_AS, rlimitPIDsEnvName: unix.RLIMIT_NPROC}
+	for envName, resource := range limits {
+		value := os.Getenv(envName)
+		os.Unsetenv(envName)
+		if value == "" {
+			continue
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			rlimitInitFailed(fmt.Errorf("invalid limit %s : %s", value, err))
+		}
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: limit, Max: limit}); err != nil {
+			rlimitInitFailed(fmt.Errorf("unable to set the limit %s : %s", envName, err))
+		}
+	}
+	if len(os.Args) < 3 {
+		rlimitInitFailed(fmt.Errorf("no command found to execute"))
+	}
+	rlimitInitFailed(syscall.Exec(os.Args[1], os.Args[2:], os.Environ()))
+}
+
+func rlimitInitFailed(err error) {
+	fmt.Fprintf(os.Stderr, "%s : %s\n", rlimitInitName, err)
+	os.Exit(126)
+}
@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func TestGetLimitedCommand(t *testing.T) {
+	env := []string{"PATH=/usr/bin:/bin"}
+	runLimited := func(t *testing.T, script string, resources environmenttypes.Resources, network environmenttypes.NetworkMode) string {
+		t.Helper()
+		execcmd := exec.Command("/bin/sh", "-c", script)
+		execcmd.Env = env
+		limitedcmd, err := getLimitedCommand(execcmd, resources, network, false)
+		if err != nil {
+			t.Fatalf("failed to get the limited command. Error: %q", err)
+		}
+		output, err := limitedcmd.CombinedOutput()
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOSYS) {
+			t.Skipf("user namespaces are not available. Error: %q", err)
+		}
+		if err != nil {
+			t.Fatalf("failed to run the limited command. Error: %q Output: %s", err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		execcmd := exec.Command("/bin/sh", "-c", "true")
+		limitedcmd, err := getLimitedCommand(execcmd, environmenttypes.Resources{}, "", false)
+		if err != nil {
+			t.Fatalf("failed to get the limited command. Error: %q", err)
+		}
+		if limitedcmd != execcmd {
+			t.Fatalf("the command should have been returned as is when there are no limits")
+		}
+	})
+
+	t.Run("invalid memory limit", func(t *testing.T) {
+		if _, err := getLimitedCommand(exec.Command("/bin/sh"), environmenttypes.Resources{Memory: "lots"}, "", false); err == nil {
+			t.Fatalf("should have failed for an invalid memory limit")
+		}
+	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		output := runLimited(t, "ulimit -v", environmenttypes.Resources{Memory: "512Mi"}, "")
+		if want := strconv.Itoa(512 * 1024); output != want {
+			t.Fatalf("the address space limit is incorrect. Expected: %s KiB Actual: %s", want, output)
+		}
+	})
+
+	t.Run("pids limit", func(t *testing.T) {
+		output := runLimited(t, "awk '/Max processes/ {print $3}' /proc/self/limits", environmenttypes.Resources{PIDs: 100}, "")
+		if output != "100" {
+			t.Fatalf("the process limit is incorrect. Expected: 100 Actual: %s", output)
+		}
+	})
+
+	t.Run("no network", func(t *testing.T) {
+		output := runLimited(t, "tail -n +3 /proc/net/dev | cut -d: -f1", environmenttypes.Resources{}, environmenttypes.NetworkModeNone)
+		if strings.TrimSpace(output) != "lo" {
+			t.Fatalf("only the loopback interface should be available without network access. Actual interfaces: %s", output)
+		}
+	})
+
+	t.Run("network mode ignored when sandboxed", func(t *testing.T) {
+		execcmd := exec.Command("/bin/sh", "-c", "true")
+		limitedcmd, err := getLimitedCommand(execcmd, environmenttypes.Resources{}, environmenttypes.NetworkModeNone, true)
+		if err != nil {
+			t.Fatalf("failed to get the limited command. Error: %q", err)
+		}
+		if limitedcmd.SysProcAttr != nil && limitedcmd.SysProcAttr.Cloneflags != 0 {
+			t.Fatalf("the sandbox already has no network access, so no namespaces should be added. Actual clone flags: %x", limitedcmd.SysProcAttr.Cloneflags)
+		}
+	})
+}
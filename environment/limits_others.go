@@ -0,0 +1,34 @@
+//go:build !linux
+// +build !linux
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"os/exec"
+	"runtime"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+// getLimitedCommand returns the command as is since resource limits and network modes are only supported on linux for local execution
+func getLimitedCommand(execcmd *exec.Cmd, resources environmenttypes.Resources, network environmenttypes.NetworkMode, sandboxed bool) (*exec.Cmd, error) {
+	logrus.Debugf("Resource limits and network modes are not supported for local execution on %s. Ignoring them.", runtime.GOOS)
+	return execcmd, nil
+}
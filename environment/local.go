@@ -40,13 +40,18 @@ type Local struct {
 	WorkspaceContext string
 
 	GRPCQAReceiver net.Addr
+
+	Resources environmenttypes.Resources
+	Network   environmenttypes.NetworkMode
 }
 
 // NewLocal creates a new Local environment
-func NewLocal(envInfo EnvInfo, grpcQAReceiver net.Addr) (ei EnvironmentInstance, err error) {
+func NewLocal(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes.Container) (ei EnvironmentInstance, err error) {
 	local := &Local{
 		EnvInfo:        envInfo,
-		GRPCQAReceiver: grpcQAReceiver,
+		GRPCQAReceiver: getReachableQAReceiver(envInfo.Name, grpcQAReceiver, c.Network, common.SandboxLocalExecution),
+		Resources:      c.Resources,
+		Network:        c.Network,
 	}
 	if envInfo.Isolated {
 		local.WorkspaceContext, err = os.MkdirTemp(local.TempPath, types.AppNameShort)
@@ -91,17 +96,32 @@ func (e *Local) Exec(cmd environmenttypes.Command) (stdout string, stderr string
 	}
 	var outb, errb bytes.Buffer
 	var execcmd *exec.Cmd
-	if len(cmd) > 0 {
-		execcmd = exec.Command(cmd[0], cmd[1:]...)
-	} else {
+	if len(cmd) == 0 {
 		err := fmt.Errorf("no command found to execute")
 		logrus.Errorf("%s", err)
 		return "", "", 0, err
 	}
-	execcmd.Dir = e.WorkspaceContext
+	if common.SandboxLocalExecution {
+		env := append(e.getEnv(), "TMPDIR="+e.TempPath)
+		execcmd, err = getSandboxedCommand(cmd, e.getReadOnlyPaths(), []string{e.TempPath}, e.WorkspaceContext, env)
+		if err != nil {
+			logrus.Errorf("Unable to sandbox the execution of command %s : %s", cmd, err)
+			return "", "", 0, err
+		}
+	} else {
+		execcmd = exec.Command(cmd[0], cmd[1:]...)
+		execcmd.Dir = e.WorkspaceContext
+		execcmd.Env = e.getEnv()
+	}
+	if e.Resources != (environmenttypes.Resources{}) || e.Network != "" {
+		execcmd, err = getLimitedCommand(execcmd, e.Resources, e.Network, common.SandboxLocalExecution)
+		if err != nil {
+			logrus.Errorf("Unable to apply the resource limits to the command %s : %s", cmd, err)
+			return "", "", 0, err
+		}
+	}
 	execcmd.Stdout = &outb
 	execcmd.Stderr = &errb
-	execcmd.Env = e.getEnv()
 	err = execcmd.Run()
 	if err != nil {
 		var ee *exec.ExitError
@@ -190,10 +210,21 @@ func (e *Local) GetSource() string {
 	return e.WorkspaceSource
 }
 
+// getReadOnlyPaths returns the paths which are made read only when the execution is sandboxed
+func (e *Local) getReadOnlyPaths() []string {
+	readOnlyPaths := []string{}
+	for _, path := range []string{e.Source, e.Context, e.WorkspaceSource, e.WorkspaceContext} {
+		if path != "" && !common.IsStringPresent(readOnlyPaths, path) {
+			readOnlyPaths = append(readOnlyPaths, path)
+		}
+	}
+	return readOnlyPaths
+}
+
 func (e *Local) getEnv() []string {
 	environ := os.Environ()
 	if e.GRPCQAReceiver != nil {
 		environ = append(environ, GRPCEnvName+"="+e.GRPCQAReceiver.String())
 	}
-	return environ
+	return append(environ, e.Env...)
 }
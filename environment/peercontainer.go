@@ -48,6 +48,9 @@ type PeerContainer struct {
 	ImageName     string
 	ImageWithData string
 	CID           string // A started instance of ImageWithData
+
+	Resources environmenttypes.Resources
+	Network   environmenttypes.NetworkMode
 }
 
 // NewPeerContainer creates an instance of peer container based environment
@@ -55,7 +58,9 @@ func NewPeerContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttyp
 	peerContainer := &PeerContainer{
 		EnvInfo:        envInfo,
 		ImageName:      c.Image,
-		GRPCQAReceiver: grpcQAReceiver,
+		GRPCQAReceiver: getReachableQAReceiver(envInfo.Name, grpcQAReceiver, c.Network, false),
+		Resources:      c.Resources,
+		Network:        c.Network,
 	}
 	if c.WorkingDir != "" {
 		peerContainer.WorkspaceContext = c.WorkingDir
@@ -68,25 +73,28 @@ func NewPeerContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttyp
 		return ei, fmt.Errorf("no working container runtime found")
 	}
 	newImageName := peerContainer.ImageName + strings.ToLower(envInfo.Name+uniuri.NewLen(5))
-	err = cengine.CopyDirsIntoImage(peerContainer.ImageName, newImageName, map[string]string{envInfo.Source: peerContainer.WorkspaceSource})
+	image, err := getTransformerImage(c, envInfo.Context)
+	if err != nil {
+		return ei, err
+	}
+	err = cengine.CopyDirsIntoImage(image, newImageName, map[string]string{envInfo.Source: peerContainer.WorkspaceSource})
 	if err != nil {
 		logrus.Debugf("Unable to create new container image with new data")
-		if c.ContainerBuild.Context != "" {
-			err = cengine.BuildImage(c.Image, filepath.Join(envInfo.Context, c.ContainerBuild.Context), c.ContainerBuild.Dockerfile)
-			if err != nil {
-				logrus.Errorf("Unable to build new container image for %s : %s", c.Image, err)
-				return ei, err
-			}
-			err = cengine.CopyDirsIntoImage(c.Image, newImageName, map[string]string{envInfo.Source: peerContainer.WorkspaceSource})
-			if err != nil {
-				logrus.Errorf("Unable to copy paths to new container image : %s", err)
-			}
-		} else {
+		if c.ContainerBuild.Context == "" || image != c.Image {
+			return ei, err
+		}
+		image, err = buildTransformerImage(c, envInfo.Context)
+		if err != nil {
+			return ei, err
+		}
+		err = cengine.CopyDirsIntoImage(image, newImageName, map[string]string{envInfo.Source: peerContainer.WorkspaceSource})
+		if err != nil {
+			logrus.Errorf("Unable to copy paths to new container image : %s", err)
 			return ei, err
 		}
 	}
 	peerContainer.ImageWithData = newImageName
-	cid, err := cengine.CreateContainer(newImageName)
+	cid, err := cengine.CreateContainer(peerContainer.getContainerConfig())
 	if err != nil {
 		logrus.Errorf("Unable to start container with image %s : %s", newImageName, cid)
 		return ei, err
@@ -102,7 +110,7 @@ func (e *PeerContainer) Reset() error {
 	if err != nil {
 		logrus.Errorf("Unable to delete image %s : %s", e.ImageWithData, err)
 	}
-	cid, err := cengine.CreateContainer(e.ImageWithData)
+	cid, err := cengine.CreateContainer(e.getContainerConfig())
 	if err != nil {
 		logrus.Errorf("Unable to start container with image %s : %s", e.ImageWithData, cid)
 		return err
@@ -114,7 +122,7 @@ func (e *PeerContainer) Reset() error {
 // Exec executes a command in the container
 func (e *PeerContainer) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
 	cengine := container.GetContainerEngine()
-	envs := []string{}
+	envs := append([]string{}, e.Env...)
 	if e.GRPCQAReceiver != nil {
 		hostname := getIP()
 		port := cast.ToString(e.GRPCQAReceiver.(*net.TCPAddr).Port)
@@ -174,3 +182,12 @@ func (e *PeerContainer) GetContext() string {
 func (e *PeerContainer) GetSource() string {
 	return e.WorkspaceSource
 }
+
+// getContainerConfig returns the config used to start instances of ImageWithData
+func (e *PeerContainer) getContainerConfig() environmenttypes.Container {
+	return environmenttypes.Container{
+		Image:     e.ImageWithData,
+		Resources: e.Resources,
+		Network:   e.Network,
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/dchest/uniuri"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+// pooledSyncDir is the directory in the pooled containers into which the sources are synced
+var pooledSyncDir = string(filepath.Separator) + types.AppNameShort + "-sync"
+
+// PooledContainer runs the environment in a warm container which is shared by all the environments using the same image.
+// The source is synced incrementally into the container instead of creating a new image.
+// Each environment works on its own copy of the synced source, which is copied again on every reset.
+type PooledContainer struct {
+	EnvInfo
+
+	WorkspaceSource  string
+	WorkspaceContext string
+	// SyncedSource is the directory in the container into which the source is synced. It is shared by the environments using the same source.
+	SyncedSource string
+
+	GRPCQAReceiver net.Addr
+
+	Container environmenttypes.Container
+
+	// generation is the generation of the pooled container the uploaded paths are in
+	generation    int
+	uploadedPaths []string
+}
+
+// NewPooledContainer creates an environment which uses a container from the pool
+func NewPooledContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes.Container) (ei EnvironmentInstance, err error) {
+	pooledContainer := &PooledContainer{
+		EnvInfo:        envInfo,
+		GRPCQAReceiver: getReachableQAReceiver(envInfo.Name, grpcQAReceiver, c.Network, false),
+		Container:      c,
+	}
+	if c.WorkingDir != "" {
+		pooledContainer.WorkspaceContext = c.WorkingDir
+	} else {
+		pooledContainer.WorkspaceContext = filepath.Join(string(filepath.Separator), types.AppNameShort)
+	}
+	// The environments using the same source share the synced copy
+	pooledContainer.SyncedSource = filepath.Join(pooledSyncDir, fmt.Sprintf("%x", sha256.Sum256([]byte(envInfo.Source)))[:12])
+	pooledContainer.WorkspaceSource = filepath.Join(string(filepath.Separator), DefaultWorkspaceDir, uniuri.NewLen(8))
+	getContainerPool().register(c)
+	if err := pooledContainer.Reset(); err != nil {
+		pooledContainer.Destroy()
+		return ei, err
+	}
+	return pooledContainer, nil
+}
+
+// Reset syncs the changed files of the source into the container, copies them into the workspace and removes the uploaded paths
+func (e *PooledContainer) Reset() error {
+	pc, err := getContainerPool().acquire(e.Container, e.Context)
+	if err != nil {
+		logrus.Errorf("Unable to get a container with image %s : %s", e.Container.Image, err)
+		return err
+	}
+	defer getContainerPool().release(pc)
+	return e.resetWorkspace(pc)
+}
+
+// resetWorkspace replaces the workspace with a fresh copy of the synced source, discarding the changes made by the previous executions
+func (e *PooledContainer) resetWorkspace(pc *pooledContainer) error {
+	e.removeUploadedPaths(pc)
+	if err := pc.syncDir(e.Source, e.SyncedSource); err != nil {
+		logrus.Errorf("Unable to sync the source into the container with image %s : %s", e.Container.Image, err)
+		return err
+	}
+	for _, cmd := range []environmenttypes.Command{
+		{"rm", "-rf", e.WorkspaceSource},
+		{"mkdir", "-p", e.WorkspaceSource},
+		{"cp", "-a", e.SyncedSource + string(filepath.Separator) + ".", e.WorkspaceSource},
+	} {
+		if _, stderr, exitcode, err := pc.cengine.RunCmdInContainer(pc.cid, cmd, "", nil); err != nil || exitcode != 0 {
+			err = fmt.Errorf("unable to copy the synced source into the workspace %s in container %s : %v %s", e.WorkspaceSource, pc.cid, err, stderr)
+			logrus.Errorf("%s", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// acquire returns the container from the pool. The workspace is set up again if the container was replaced since the last reset.
+func (e *PooledContainer) acquire() (*pooledContainer, error) {
+	pc, err := getContainerPool().acquire(e.Container, e.Context)
+	if err != nil {
+		logrus.Errorf("Unable to get a container with image %s : %s", e.Container.Image, err)
+		return nil, err
+	}
+	if pc.generation != e.generation {
+		if err := e.resetWorkspace(pc); err != nil {
+			getContainerPool().release(pc)
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// Exec executes a command in the container
+func (e *PooledContainer) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+	pc, err := e.acquire()
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer getContainerPool().release(pc)
+	envs := append([]string{}, e.Env...)
+	if e.GRPCQAReceiver != nil {
+		hostname := getIP()
+		port := cast.ToString(e.GRPCQAReceiver.(*net.TCPAddr).Port)
+		envs = append(envs, GRPCEnvName+"="+hostname+":"+port)
+	}
+	return pc.cengine.RunCmdInContainer(pc.cid, cmd, e.WorkspaceContext, envs)
+}
+
+// Destroy removes the workspace and the uploaded paths from the container and releases the container
+func (e *PooledContainer) Destroy() error {
+	if pc := getContainerPool().getExisting(e.Container); pc != nil {
+		if pc.generation == e.generation {
+			e.uploadedPaths = append(e.uploadedPaths, e.WorkspaceSource)
+		}
+		e.removeUploadedPaths(pc)
+		getContainerPool().release(pc)
+	}
+	getContainerPool().unregister(e.Container)
+	return nil
+}
+
+// Download downloads the path to outside the environment
+func (e *PooledContainer) Download(path string) (string, error) {
+	output, err := os.MkdirTemp(e.TempPath, "*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return path, err
+	}
+	pc, err := e.acquire()
+	if err != nil {
+		return path, err
+	}
+	defer getContainerPool().release(pc)
+	if err := pc.cengine.CopyDirsFromContainer(pc.cid, map[string]string{path: output}); err != nil {
+		logrus.Errorf("Unable to copy data from container : %s", err)
+		return path, err
+	}
+	return output, nil
+}
+
+// Upload uploads the path from outside the environment into it
+func (e *PooledContainer) Upload(outpath string) (envpath string, err error) {
+	pc, err := e.acquire()
+	if err != nil {
+		return outpath, err
+	}
+	defer getContainerPool().release(pc)
+	uploadDir := "/var/tmp/" + uniuri.NewLen(5)
+	envpath = uploadDir + "/" + filepath.Base(outpath)
+	if err := pc.cengine.CopyDirsIntoContainer(pc.cid, map[string]string{outpath: envpath}); err != nil {
+		logrus.Errorf("Unable to copy data into container : %s", err)
+		return outpath, err
+	}
+	e.uploadedPaths = append(e.uploadedPaths, uploadDir)
+	return envpath, nil
+}
+
+// GetContext returns the context within the container
+func (e *PooledContainer) GetContext() string {
+	return e.WorkspaceContext
+}
+
+// GetSource returns the source path within the container
+func (e *PooledContainer) GetSource() string {
+	return e.WorkspaceSource
+}
+
+// removeUploadedPaths removes the paths uploaded by this environment, if the container still has them
+func (e *PooledContainer) removeUploadedPaths(pc *pooledContainer) {
+	if pc.generation == e.generation && len(e.uploadedPaths) > 0 {
+		_, stderr, exitcode, err := pc.cengine.RunCmdInContainer(pc.cid, append(environmenttypes.Command{"rm", "-rf"}, e.uploadedPaths...), "", nil)
+		if err != nil || exitcode != 0 {
+			logrus.Debugf("Unable to remove the uploaded paths %+v from container %s : %s %s", e.uploadedPaths, pc.cid, err, stderr)
+		}
+	}
+	e.generation = pc.generation
+	e.uploadedPaths = nil
+}
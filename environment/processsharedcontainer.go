@@ -16,100 +16,322 @@
 
 package environment
 
-/*
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxSymlinks is the maximum number of symlinks followed while resolving a path within the root file system of a container
+	maxSymlinks = 255
+)
+
+var disallowedEnvNameCharactersRegex = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// getImageEnvName returns the name of the environment variable which has the pid of the container of the image
+func getImageEnvName(image string) string {
+	return disallowedEnvNameCharactersRegex.ReplaceAllLiteralString(strings.ToUpper(image), "_")
+}
+
+// lookupImageEnv returns the value of the environment variable of the image, if it is set and not empty
+func lookupImageEnv(image string) (string, bool) {
+	value, ok := os.LookupEnv(getImageEnvName(image))
+	return value, ok && value != ""
+}
+
+// ProcessSharedContainer runs the environment in a container which shares its process namespace with move2kube.
+// The file system of the container is accessed using /proc/<pid>/root, so no container engine is required.
 type ProcessSharedContainer struct {
-	Name string
-	Pid  int
+	EnvInfo
+
+	WorkspaceSource  string
+	WorkspaceContext string
 
-	TempPath string
+	GRPCQAReceiver net.Addr
 
-	EnvContext string
-	EnvSource  string
-	OutContext string
-	OutSource  string
+	PID  int
+	Root string
+	// TempDir is the path within the container where the source and uploaded paths are stored
+	TempDir string
+}
+
+// NewProcessSharedContainer creates an environment which uses the container whose process has the given pid
+func NewProcessSharedContainer(envInfo EnvInfo, grpcQAReceiver net.Addr, c environmenttypes.Container, pid int) (ei EnvironmentInstance, err error) {
+	root := filepath.Join(string(filepath.Separator), "proc", strconv.Itoa(pid), "root")
+	if _, err := os.Stat(root); err != nil {
+		logrus.Errorf("Unable to access the root file system of the process %d : %s", pid, err)
+		return ei, err
+	}
+	psContainer := &ProcessSharedContainer{
+		EnvInfo:        envInfo,
+		GRPCQAReceiver: grpcQAReceiver,
+		PID:            pid,
+		Root:           root,
+	}
+	if c.WorkingDir != "" {
+		psContainer.WorkspaceContext = c.WorkingDir
+	} else {
+		psContainer.WorkspaceContext = filepath.Join(string(filepath.Separator), types.AppNameShort)
+	}
+	tempDirInRoot := filepath.Join(root, os.TempDir())
+	if err := os.MkdirAll(tempDirInRoot, os.ModePerm); err != nil {
+		logrus.Errorf("Unable to create the temp directory %s : %s", tempDirInRoot, err)
+		return ei, err
+	}
+	tempDir, err := os.MkdirTemp(tempDirInRoot, types.AppNameShort+"-"+strings.ToLower(envInfo.Name)+"-*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return ei, err
+	}
+	psContainer.TempDir = psContainer.toContainerPath(tempDir)
+	psContainer.WorkspaceSource = filepath.Join(psContainer.TempDir, DefaultWorkspaceDir)
+	if err := psContainer.Reset(); err != nil {
+		psContainer.Destroy()
+		return ei, err
+	}
+	return psContainer, nil
+}
 
-	ImageName     string
-	ImageWithData string
-	CID           string // A started instance of ImageWithData
-	PID           int
-	Root          string
+// toHostPath converts a path within the container to a path accessible to move2kube
+func (e *ProcessSharedContainer) toHostPath(path string) string {
+	return filepath.Join(e.Root, path)
 }
 
-func NewProcessSharedContainer(name string, source string, context string, pid int) (EnvironmentInstance, error) {
-	ei := ProcessSharedContainer{
-		Name: name,
+// toContainerPath converts a path accessible to move2kube to a path within the container
+func (e *ProcessSharedContainer) toContainerPath(path string) string {
+	rel, err := filepath.Rel(e.Root, path)
+	if err != nil {
+		logrus.Errorf("Unable to make path (%s) relative to the container root (%s) : %s", path, e.Root, err)
+		return path
+	}
+	return filepath.Join(string(filepath.Separator), rel)
+}
 
-		Source:  source,
-		Context: context,
+// Reset syncs the source into the container. Only the files that changed are copied.
+func (e *ProcessSharedContainer) Reset() error {
+	if err := filesystem.Replicate(e.Source, e.toHostPath(e.WorkspaceSource)); err != nil {
+		logrus.Errorf("Unable to copy contents to directory %s, %s : %s", e.Source, e.WorkspaceSource, err)
+		return err
 	}
-	return ei, nil
+	return nil
 }
 
-if env.Container.CID == "" {
-	if env.Container.PID == 0 {
-		env.OutContext, err = os.MkdirTemp(env.TempPath, types.AppNameShort)
-		if err != nil {
-			logrus.Errorf("Unable to create temp dir : %s", err)
-		}
-		if err := filesystem.Replicate(env.OutContext, env.EnvContext); err != nil {
-			logrus.Errorf("Unable to copy contents to directory %s, dp: %s", env.OutSource, env.EnvSource, err)
-		}
-		env.OutSource, err = os.MkdirTemp(env.TempPath, workspaceDir)
-		if err != nil {
-			logrus.Errorf("Unable to create temp dir : %s", err)
+// Exec executes a command within the root file system of the container
+func (e *ProcessSharedContainer) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+	if len(cmd) == 0 {
+		err := fmt.Errorf("no command found to execute")
+		logrus.Errorf("%s", err)
+		return "", "", 0, err
+	}
+	environ := e.getEnv()
+	cmdPath, err := lookPathInRoot(e.Root, cmd[0], environ)
+	if err != nil {
+		logrus.Errorf("Unable to find the command %s in the container of process %d : %s", cmd[0], e.PID, err)
+		return "", "", 0, err
+	}
+	var outb, errb bytes.Buffer
+	execcmd := &exec.Cmd{
+		Path:   cmdPath,
+		Args:   cmd,
+		Dir:    e.WorkspaceContext,
+		Env:    environ,
+		Stdout: &outb,
+		Stderr: &errb,
+	}
+	if err := setChroot(execcmd, e.Root); err != nil {
+		logrus.Errorf("Unable to execute in the container of process %d : %s", e.PID, err)
+		return "", "", 0, err
+	}
+	err = execcmd.Run()
+	if err != nil {
+		var ee *exec.ExitError
+		var pe *os.PathError
+		if errors.As(err, &ee) {
+			exitcode = ee.ExitCode()
+			err = nil
+		} else if errors.As(err, &pe) {
+			logrus.Errorf("PathError during execution of command: %v", pe)
+			err = pe
+		} else {
+			logrus.Errorf("Generic error during execution of command: %v", err)
 		}
-	} else {
-		env.OutContext = env.makePathRelativeForContainer(filepath.Join(string(filepath.Separator), types.AppNameShort))
-		env.OutSource = env.makePathRelativeForContainer(filepath.Join(string(filepath.Separator), workspaceDir))
 	}
-	if err := filesystem.Replicate(env.OutSource, env.EnvSource); err != nil {
-		logrus.Errorf("Unable to copy contents to directory %s, dp: %s", env.OutSource, env.EnvSource, err)
+	return outb.String(), errb.String(), exitcode, err
+}
+
+// Destroy removes the source and the uploaded paths from the container
+func (e *ProcessSharedContainer) Destroy() error {
+	if err := os.RemoveAll(e.toHostPath(e.TempDir)); err != nil {
+		logrus.Errorf("Unable to remove directory %s : %s", e.TempDir, err)
 	}
+	return nil
 }
 
-filepath.Join(string(filepath.Separator), "proc", fmt.Sprint(e.Container.PID), "root", types.AppNameShort)
-	} else {
+// Download downloads the path to outside the environment
+func (e *ProcessSharedContainer) Download(path string) (string, error) {
+	output, err := os.MkdirTemp(e.TempPath, "*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return path, err
+	}
+	hostPath := e.toHostPath(path)
+	ps, err := os.Stat(hostPath)
+	if err != nil {
+		logrus.Errorf("Unable to stat source : %s", path)
+		return "", err
+	}
+	if ps.Mode().IsRegular() {
+		output = filepath.Join(output, filepath.Base(path))
+	}
+	if err := filesystem.Replicate(hostPath, output); err != nil {
+		logrus.Errorf("Unable to replicate in syncoutput : %s", err)
+		return path, err
+	}
+	return output, nil
+}
+
+// Upload uploads the path from outside the environment into it
+func (e *ProcessSharedContainer) Upload(outpath string) (envpath string, err error) {
+	hostPath, err := os.MkdirTemp(e.toHostPath(e.TempDir), "*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return outpath, err
+	}
+	ps, err := os.Stat(outpath)
+	if err != nil {
+		logrus.Errorf("Unable to stat source : %s", outpath)
+		return "", err
+	}
+	if ps.Mode().IsRegular() {
+		hostPath = filepath.Join(hostPath, filepath.Base(outpath))
+	}
+	if err := filesystem.Replicate(outpath, hostPath); err != nil {
+		logrus.Errorf("Unable to replicate in syncoutput : %s", err)
+		return outpath, err
+	}
+	return e.toContainerPath(hostPath), nil
+}
 
+// GetContext returns the context within the container
+func (e *ProcessSharedContainer) GetContext() string {
+	return e.WorkspaceContext
+}
 
+// GetSource returns the source path within the container
+func (e *ProcessSharedContainer) GetSource() string {
+	return e.WorkspaceSource
+}
 
-		func (e *Environment) Exec(cmd environmenttypes.Command) (string, string, int, error) {
-			if workingDir == "" {
-				workingDir = e.Context
-			}
-			if (e.Container != ContainerEnvironment{}) {
-				if e.Container.CID != "" {
-					cengine := GetContainerEngine()
-					return cengine.RunCmdInContainer(e.Container.CID, cmd, "")
-				}
-				if e.Container.PID != 0 {
-					//TODO : Fix me
-					workingDir = filepath.Join("proc", fmt.Sprint(e.Container.PID), "root", workingDir)
-				} else if workingDir == "" && e.Container.Root != "" {
-					workingDir = e.Container.Root
-				}
-			}
-			var exitcode int
-			var outb, errb bytes.Buffer
-			execcmd := exec.Command(cmd.CMD, cmd.Args...)
-			execcmd.Dir = e.Context
-			execcmd.Dir = workingDir
-			execcmd.Stdout = &outb
-			execcmd.Stderr = &errb
-			err := execcmd.Run()
-			if err != nil {
-				var ee *exec.ExitError
-				var pe *os.PathError
-				if errors.As(err, &ee) {
-					exitcode = ee.ExitCode()
-					err = nil
-				} else if errors.As(err, &pe) {
-					logrus.Errorf("PathError during execution of command: %v", pe)
-					err = pe
-				} else {
-					logrus.Errorf("Generic error during execution of command: %v", err)
-				}
-			}
-			return outb.String(), errb.String(), exitcode, err
+// getEnv returns the environment of the container process along with the move2kube specific variables
+func (e *ProcessSharedContainer) getEnv() []string {
+	environ := []string{}
+	environBytes, err := os.ReadFile(filepath.Join(string(filepath.Separator), "proc", strconv.Itoa(e.PID), "environ"))
+	if err != nil {
+		logrus.Debugf("Unable to read the environment of process %d : %s", e.PID, err)
+	}
+	for _, envvar := range strings.Split(string(environBytes), "\x00") {
+		if envvar != "" {
+			environ = append(environ, envvar)
+		}
+	}
+	if e.GRPCQAReceiver != nil {
+		environ = append(environ, GRPCEnvName+"="+e.GRPCQAReceiver.String())
+	}
+	return append(environ, e.Env...)
+}
+
+// lookPathInRoot finds the executable in the PATH of the environment, within the given root file system
+func lookPathInRoot(root, file string, environ []string) (string, error) {
+	if strings.Contains(file, "/") {
+		if isExecutableInRoot(root, file) {
+			return file, nil
+		}
+		return "", fmt.Errorf("%s is not an executable", file)
+	}
+	pathEnv := "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	for _, envvar := range environ {
+		if strings.HasPrefix(envvar, "PATH=") {
+			pathEnv = strings.TrimPrefix(envvar, "PATH=")
+		}
+	}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			continue
 		}
+		path := filepath.Join(dir, file)
+		if isExecutableInRoot(root, path) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("executable %s not found in PATH %s", file, pathEnv)
+}
+
+// isExecutableInRoot returns true if the path within the root file system is an executable file
+func isExecutableInRoot(root, path string) bool {
+	hostPath, err := resolveInRoot(root, path)
+	if err != nil {
+		return false
+	}
+	fi, err := os.Stat(hostPath)
+	if err != nil {
+		return false
+	}
+	return !fi.IsDir() && fi.Mode()&0111 != 0
+}
 
-*/
+// resolveInRoot returns the path accessible to move2kube of the path within the root file system.
+// The symlinks are resolved within the root, so that the absolute symlinks do not point to the files of the host.
+func resolveInRoot(root, path string) (string, error) {
+	resolved := string(filepath.Separator)
+	remaining := path
+	symlinks := 0
+	for remaining != "" {
+		component := remaining
+		remaining = ""
+		if i := strings.IndexRune(component, filepath.Separator); i != -1 {
+			component, remaining = component[:i], component[i+1:]
+		}
+		if component == "" || component == "." {
+			continue
+		}
+		if component == ".." {
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, component)
+		fi, err := os.Lstat(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		symlinks++
+		if symlinks > maxSymlinks {
+			return "", fmt.Errorf("too many symlinks while resolving %s", path)
+		}
+		target, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			resolved = string(filepath.Separator)
+		}
+		remaining = target + string(filepath.Separator) + remaining
+	}
+	return filepath.Join(root, resolved), nil
+}
@@ -0,0 +1,216 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func TestGetImageEnvName(t *testing.T) {
+	testcases := []struct {
+		image string
+		want  string
+	}{
+		{image: "quay.io/konveyor/move2kube-transformer:latest", want: "QUAY_IO_KONVEYOR_MOVE2KUBE_TRANSFORMER_LATEST"},
+		{image: "my_transformer", want: "MY_TRANSFORMER"},
+		{image: "registry:5000/team/app@sha256:abc", want: "REGISTRY_5000_TEAM_APP_SHA256_ABC"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.image, func(t *testing.T) {
+			if actual := getImageEnvName(tc.image); actual != tc.want {
+				t.Fatalf("the env name is incorrect. Expected: %s Actual: %s", tc.want, actual)
+			}
+		})
+	}
+}
+
+func TestLookupImageEnv(t *testing.T) {
+	t.Setenv("QUAY_IO_KONVEYOR_SET_LATEST", "1234")
+	t.Setenv("QUAY_IO_KONVEYOR_EMPTY_LATEST", "")
+	testcases := []struct {
+		image     string
+		wantValue string
+		wantFound bool
+	}{
+		{image: "quay.io/konveyor/set:latest", wantValue: "1234", wantFound: true},
+		{image: "quay.io/konveyor/empty:latest", wantFound: false},
+		{image: "quay.io/konveyor/unset:latest", wantFound: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.image, func(t *testing.T) {
+			value, found := lookupImageEnv(tc.image)
+			if found != tc.wantFound || found && value != tc.wantValue {
+				t.Fatalf("the env lookup is incorrect. Expected: %q %t Actual: %q %t", tc.wantValue, tc.wantFound, value, found)
+			}
+		})
+	}
+}
+
+func TestNewProcessSharedContainer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process shared containers are supported only on linux")
+	}
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to create the source. Error: %q", err)
+	}
+	envInfo := EnvInfo{Name: "test", Source: source, TempPath: t.TempDir()}
+
+	t.Run("process which does not exist", func(t *testing.T) {
+		if _, err := NewProcessSharedContainer(envInfo, nil, environmenttypes.Container{}, -1); err == nil {
+			t.Fatalf("expected an error for a process which does not exist")
+		}
+	})
+
+	t.Run("current process", func(t *testing.T) {
+		ei, err := NewProcessSharedContainer(envInfo, nil, environmenttypes.Container{WorkingDir: source}, os.Getpid())
+		if err != nil {
+			t.Fatalf("failed to create the process shared container. Error: %q", err)
+		}
+		psContainer := ei.(*ProcessSharedContainer)
+		defer psContainer.Destroy()
+		if psContainer.GetContext() != source {
+			t.Fatalf("the context is incorrect. Expected: %s Actual: %s", source, psContainer.GetContext())
+		}
+		content, err := os.ReadFile(filepath.Join(psContainer.toHostPath(psContainer.GetSource()), "file.txt"))
+		if err != nil || string(content) != "content" {
+			t.Fatalf("expected the source to be copied into the container. Actual: %q Error: %q", content, err)
+		}
+		if os.Geteuid() != 0 {
+			t.Skip("executing in the root file system of a process requires root")
+		}
+		stdout, _, exitcode, err := psContainer.Exec(environmenttypes.Command{"sh", "-c", "cat " + filepath.Join(psContainer.GetSource(), "file.txt")})
+		if err != nil || exitcode != 0 || stdout != "content" {
+			t.Fatalf("the command output is incorrect. Actual: %q exit code: %d Error: %q", stdout, exitcode, err)
+		}
+		tempDir := psContainer.toHostPath(psContainer.TempDir)
+		psContainer.Destroy()
+		if _, err := os.Stat(tempDir); !os.IsNotExist(err) {
+			t.Fatalf("expected the temp dir %s to be removed. Error: %q", tempDir, err)
+		}
+	})
+
+	t.Run("environment variable of the image", func(t *testing.T) {
+		oldTempPath := common.TempPath
+		common.TempPath = t.TempDir()
+		defer func() { common.TempPath = oldTempPath }()
+		image := "quay.io/konveyor/process-shared:latest"
+		t.Setenv(getImageEnvName(image), strconv.Itoa(os.Getpid()))
+		env, err := NewEnvironment(EnvInfo{Name: "test", Source: source}, nil, environmenttypes.Container{Image: image}, environmenttypes.Remote{})
+		if err != nil {
+			t.Fatalf("failed to create the environment. Error: %q", err)
+		}
+		defer env.Destroy()
+		if _, ok := env.Env.(*ProcessSharedContainer); !ok {
+			t.Fatalf("expected a process shared container. Actual: %T", env.Env)
+		}
+	})
+}
+
+func TestLookPathInRoot(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"bin", "usr/bin", "opt/tools"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), os.ModePerm); err != nil {
+			t.Fatalf("failed to create the directory %s. Error: %q", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "bin", "tool"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("failed to create the executable. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "bin", "data"), []byte("data"), 0644); err != nil {
+		t.Fatalf("failed to create the file. Error: %q", err)
+	}
+	for link, target := range map[string]string{
+		"usr/bin/abstool": "/bin/tool",
+		"usr/bin/reltool": "../../bin/tool",
+		"usr/bin/hostsh":  "/bin/sh",
+		"opt/tools/bin":   "/usr/bin",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatalf("failed to create the symlink %s. Error: %q", link, err)
+		}
+	}
+	testcases := []struct {
+		name    string
+		file    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "executable in the default path", file: "tool", want: "/bin/tool"},
+		{name: "absolute symlink in the root", file: "abstool", path: "/usr/bin", want: "/usr/bin/abstool"},
+		{name: "relative symlink", file: "reltool", path: "/usr/bin", want: "/usr/bin/reltool"},
+		{name: "symlinked directory in the path", file: "abstool", path: "/opt/tools/bin", want: "/opt/tools/bin/abstool"},
+		{name: "absolute symlink to a file only on the host", file: "hostsh", path: "/usr/bin", wantErr: true},
+		{name: "not executable", file: "data", wantErr: true},
+		{name: "not in the path", file: "tool", path: "/usr/bin", wantErr: true},
+		{name: "path with a slash", file: "/usr/bin/abstool", want: "/usr/bin/abstool"},
+		{name: "path with a slash to a file only on the host", file: "/usr/bin/hostsh", wantErr: true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			environ := []string{"HOME=/root"}
+			if tc.path != "" {
+				environ = append(environ, "PATH="+tc.path)
+			}
+			actual, err := lookPathInRoot(root, tc.file, environ)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error. Actual: %s", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to find the executable. Error: %q", err)
+			}
+			if actual != tc.want {
+				t.Fatalf("the executable path is incorrect. Expected: %s Actual: %s", tc.want, actual)
+			}
+		})
+	}
+}
+
+func TestResolveInRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a"), os.ModePerm); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	if err := os.Symlink("/a/../a/loop", filepath.Join(root, "a", "loop")); err != nil {
+		t.Fatalf("failed to create the symlink. Error: %q", err)
+	}
+	if err := os.Symlink("/../../..", filepath.Join(root, "a", "up")); err != nil {
+		t.Fatalf("failed to create the symlink. Error: %q", err)
+	}
+	if _, err := resolveInRoot(root, "/a/loop"); err == nil || !strings.Contains(err.Error(), "too many symlinks") {
+		t.Fatalf("expected an error for a symlink loop. Error: %q", err)
+	}
+	actual, err := resolveInRoot(root, "/a/up/a")
+	if err != nil {
+		t.Fatalf("failed to resolve the path. Error: %q", err)
+	}
+	if want := filepath.Join(root, "a"); actual != want {
+		t.Fatalf("expected the symlink to not escape the root. Expected: %s Actual: %s", want, actual)
+	}
+}
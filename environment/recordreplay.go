@@ -0,0 +1,536 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	recordingFileName = "interactions.yaml"
+	recordingFilesDir = "files"
+
+	execInteractionType     = "Exec"
+	downloadInteractionType = "Download"
+	uploadInteractionType   = "Upload"
+)
+
+var (
+	fixtureDirsMutex sync.Mutex
+	fixtureDirs      = map[string]int{}
+)
+
+// environmentRecording stores the interactions with an environment
+type environmentRecording struct {
+	Source       string        `yaml:"source"`
+	Context      string        `yaml:"context"`
+	Interactions []interaction `yaml:"interactions"`
+}
+
+// interaction is a call to an environment along with its result
+type interaction struct {
+	Type     string                   `yaml:"type"`
+	Command  environmenttypes.Command `yaml:"command,omitempty"`
+	Stdout   string                   `yaml:"stdout,omitempty"`
+	Stderr   string                   `yaml:"stderr,omitempty"`
+	ExitCode int                      `yaml:"exitCode,omitempty"`
+	Path     string                   `yaml:"path,omitempty"`
+	EnvPath  string                   `yaml:"envPath,omitempty"`
+	Error    string                   `yaml:"error,omitempty"`
+	// Files is the path in the fixture directory of the downloaded files
+	Files string `yaml:"files,omitempty"`
+	// Changes are the changes made by an exec to the watched directories
+	Changes []dirChanges `yaml:"changes,omitempty"`
+
+	replayed bool
+}
+
+// dirChanges are the changes made by an exec to the files in a directory
+type dirChanges struct {
+	// Dir is the directory with its path replaced by a placeholder, like ${M2K_TEMP}
+	Dir string `yaml:"dir"`
+	// Files is the path in the fixture directory of the files which were added or changed
+	Files string `yaml:"files,omitempty"`
+	// DeletedPaths are the paths relative to the directory which were deleted
+	DeletedPaths []string `yaml:"deletedPaths,omitempty"`
+}
+
+// pathPlaceholders replaces the paths which change across runs with placeholders
+type pathPlaceholders [][2]string
+
+func newPathPlaceholders(envInfo EnvInfo) pathPlaceholders {
+	p := pathPlaceholders{}
+	for placeholder, path := range map[string]string{
+		"${M2K_TEMP}":      envInfo.TempPath,
+		"${M2K_SOURCE}":    envInfo.Source,
+		"${M2K_CONTEXT}":   envInfo.Context,
+		"${M2K_OUTPUT}":    envInfo.Output,
+		"${M2K_TEMP_ROOT}": common.TempPath,
+	} {
+		if path != "" {
+			p = append(p, [2]string{path, placeholder})
+		}
+	}
+	// The longest paths are replaced first, since the paths can be nested
+	sort.Slice(p, func(i, j int) bool { return len(p[i][0]) > len(p[j][0]) })
+	return p
+}
+
+func (p pathPlaceholders) normalize(s string) string {
+	for _, pp := range p {
+		s = strings.ReplaceAll(s, pp[0], pp[1])
+	}
+	return s
+}
+
+func (p pathPlaceholders) denormalize(s string) string {
+	for _, pp := range p {
+		s = strings.ReplaceAll(s, pp[1], pp[0])
+	}
+	return s
+}
+
+func (p pathPlaceholders) normalizeCommand(cmd environmenttypes.Command) environmenttypes.Command {
+	normalized := environmenttypes.Command{}
+	for _, arg := range cmd {
+		normalized = append(normalized, p.normalize(arg))
+	}
+	return normalized
+}
+
+// getFixturesDir returns the fixture directory of the environment.
+// Environments with the same name get a numbered suffix in the order in which they are created.
+func getFixturesDir(fixturesPath, envName string) string {
+	fixtureDirsMutex.Lock()
+	defer fixtureDirsMutex.Unlock()
+	key := fixturesPath + string(os.PathListSeparator) + envName
+	count := fixtureDirs[key]
+	fixtureDirs[key] = count + 1
+	if count == 0 {
+		return filepath.Join(fixturesPath, envName)
+	}
+	return filepath.Join(fixturesPath, envName+"-"+strconv.Itoa(count))
+}
+
+// RecordingEnvironment records all the interactions with an environment into a fixture directory
+type RecordingEnvironment struct {
+	EnvInfo
+
+	Env         EnvironmentInstance
+	FixturesDir string
+
+	recording    environmentRecording
+	placeholders pathPlaceholders
+	// watchedDirs are the directories whose changes by the commands are recorded
+	watchedDirs []string
+}
+
+// NewRecordingEnvironment creates an environment which records the interactions with env
+func NewRecordingEnvironment(envInfo EnvInfo, env EnvironmentInstance, fixturesPath string) (ei EnvironmentInstance, err error) {
+	e := &RecordingEnvironment{
+		EnvInfo:      envInfo,
+		Env:          env,
+		FixturesDir:  getFixturesDir(fixturesPath, envInfo.Name),
+		placeholders: newPathPlaceholders(envInfo),
+		watchedDirs:  getWatchedDirs(envInfo, env),
+	}
+	if err := os.RemoveAll(e.FixturesDir); err != nil {
+		logrus.Errorf("Unable to remove the old recording at %s : %s", e.FixturesDir, err)
+		return env, err
+	}
+	if err := os.MkdirAll(filepath.Join(e.FixturesDir, recordingFilesDir), common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("Unable to create the fixture directory %s : %s", e.FixturesDir, err)
+		return env, err
+	}
+	e.recording = environmentRecording{
+		Source:       e.placeholders.normalize(env.GetSource()),
+		Context:      e.placeholders.normalize(env.GetContext()),
+		Interactions: []interaction{},
+	}
+	return e, e.save()
+}
+
+// Reset resets the recorded environment
+func (e *RecordingEnvironment) Reset() error {
+	return e.Env.Reset()
+}
+
+// Exec executes the command in the recorded environment and records the output and the changes to the watched directories
+func (e *RecordingEnvironment) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+	snapshots := map[string]map[string]syncedFileInfo{}
+	for _, dir := range e.watchedDirs {
+		snapshot, err := snapshotDir(dir)
+		if err != nil {
+			logrus.Errorf("Unable to snapshot the directory %s : %s", dir, err)
+			continue
+		}
+		snapshots[dir] = snapshot
+	}
+	stdout, stderr, exitcode, err = e.Env.Exec(cmd)
+	i := interaction{
+		Type:     execInteractionType,
+		Command:  e.placeholders.normalizeCommand(cmd),
+		Stdout:   e.placeholders.normalize(stdout),
+		Stderr:   e.placeholders.normalize(stderr),
+		ExitCode: exitcode,
+	}
+	if err != nil {
+		i.Error = e.placeholders.normalize(err.Error())
+	}
+	for idx, dir := range e.watchedDirs {
+		before, ok := snapshots[dir]
+		if !ok {
+			continue
+		}
+		changes, err := e.recordChanges(dir, before, filepath.Join(e.newFilesDir(), strconv.Itoa(idx)))
+		if err != nil {
+			logrus.Errorf("Unable to record the changes to the directory %s : %s", dir, err)
+			continue
+		}
+		if changes.Files != "" || len(changes.DeletedPaths) != 0 {
+			i.Changes = append(i.Changes, changes)
+		}
+	}
+	e.add(i)
+	return stdout, stderr, exitcode, err
+}
+
+// Download downloads the path from the recorded environment and records the downloaded files
+func (e *RecordingEnvironment) Download(path string) (string, error) {
+	outpath, err := e.Env.Download(path)
+	i := interaction{
+		Type: downloadInteractionType,
+		Path: e.placeholders.normalize(path),
+	}
+	if err != nil {
+		i.Error = e.placeholders.normalize(err.Error())
+	} else {
+		filesDir := e.newFilesDir()
+		i.Files = filepath.Join(filesDir, filepath.Base(outpath))
+		if err := filesystem.Replicate(outpath, filepath.Join(e.FixturesDir, i.Files)); err != nil {
+			logrus.Errorf("Unable to record the downloaded files at %s : %s", outpath, err)
+		}
+	}
+	e.add(i)
+	return outpath, err
+}
+
+// Upload uploads the path into the recorded environment and records the path in the environment
+func (e *RecordingEnvironment) Upload(outpath string) (envpath string, err error) {
+	envpath, err = e.Env.Upload(outpath)
+	i := interaction{
+		Type:    uploadInteractionType,
+		Path:    e.placeholders.normalize(outpath),
+		EnvPath: e.placeholders.normalize(envpath),
+	}
+	if err != nil {
+		i.Error = e.placeholders.normalize(err.Error())
+	}
+	e.add(i)
+	return envpath, err
+}
+
+// Destroy destroys the recorded environment
+func (e *RecordingEnvironment) Destroy() error {
+	return e.Env.Destroy()
+}
+
+// GetSource returns the source of the recorded environment
+func (e *RecordingEnvironment) GetSource() string {
+	return e.Env.GetSource()
+}
+
+// GetContext returns the context of the recorded environment
+func (e *RecordingEnvironment) GetContext() string {
+	return e.Env.GetContext()
+}
+
+func (e *RecordingEnvironment) add(i interaction) {
+	e.recording.Interactions = append(e.recording.Interactions, i)
+	if err := e.save(); err != nil {
+		logrus.Errorf("Unable to save the recording of environment %s : %s", e.Name, err)
+	}
+}
+
+func (e *RecordingEnvironment) save() error {
+	return common.WriteYaml(filepath.Join(e.FixturesDir, recordingFileName), e.recording)
+}
+
+// newFilesDir returns a new directory, relative to the fixture directory, to store files in
+func (e *RecordingEnvironment) newFilesDir() string {
+	return filepath.Join(recordingFilesDir, strconv.Itoa(len(e.recording.Interactions)))
+}
+
+// recordChanges copies the files in the directory which changed since the snapshot into the files directory in the fixture directory
+func (e *RecordingEnvironment) recordChanges(dir string, before map[string]syncedFileInfo, filesDir string) (dirChanges, error) {
+	changes := dirChanges{Dir: e.placeholders.normalize(dir)}
+	after, err := snapshotDir(dir)
+	if err != nil {
+		return changes, err
+	}
+	changed, deleted := diffSnapshots(before, after)
+	for _, rel := range deleted {
+		changes.DeletedPaths = append(changes.DeletedPaths, filepath.ToSlash(rel))
+	}
+	if len(changed) == 0 {
+		return changes, nil
+	}
+	for _, rel := range changed {
+		path := filepath.Join(dir, rel)
+		fi, err := os.Lstat(path)
+		if err != nil {
+			return changes, err
+		}
+		if err := stageFile(path, filepath.Join(e.FixturesDir, filesDir, rel), fi, after[rel].linkTarget); err != nil {
+			return changes, err
+		}
+	}
+	changes.Files = filesDir
+	return changes, nil
+}
+
+// getWatchedDirs returns the directories whose changes by the commands are recorded.
+// Commands run directly on the machine can also change the output directory, and the source and
+// the context directories when the environment is not isolated. Changes made anywhere else are not recorded.
+func getWatchedDirs(envInfo EnvInfo, env EnvironmentInstance) []string {
+	watchedDirs := []string{envInfo.TempPath}
+	if _, ok := env.(*Local); !ok {
+		return watchedDirs
+	}
+	dirs := []string{envInfo.Output}
+	if !envInfo.Isolated {
+		dirs = append(dirs, envInfo.Source, envInfo.Context)
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		nested := false
+		for _, watchedDir := range watchedDirs {
+			if common.IsParent(dir, watchedDir) || common.IsParent(watchedDir, dir) {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			logrus.Debugf("The directory %s is nested with another watched directory. Not watching it separately", dir)
+			continue
+		}
+		watchedDirs = append(watchedDirs, dir)
+	}
+	return watchedDirs
+}
+
+// ReplayEnvironment serves the interactions recorded by a RecordingEnvironment without running anything
+type ReplayEnvironment struct {
+	EnvInfo
+
+	FixturesDir string
+
+	recording    environmentRecording
+	placeholders pathPlaceholders
+}
+
+// NewReplayEnvironment creates an environment which replays the interactions recorded in the fixture directory
+func NewReplayEnvironment(envInfo EnvInfo, fixturesPath string) (ei EnvironmentInstance, err error) {
+	e := &ReplayEnvironment{
+		EnvInfo:      envInfo,
+		FixturesDir:  getFixturesDir(fixturesPath, envInfo.Name),
+		placeholders: newPathPlaceholders(envInfo),
+	}
+	if err := common.ReadYaml(filepath.Join(e.FixturesDir, recordingFileName), &e.recording); err != nil {
+		logrus.Errorf("Unable to read the recording of environment %s from %s : %s", envInfo.Name, e.FixturesDir, err)
+		return e, err
+	}
+	return e, nil
+}
+
+// Reset does nothing since the replayed environment has no state
+func (e *ReplayEnvironment) Reset() error {
+	return nil
+}
+
+// Exec returns the recorded output of the command and replays the recorded changes to the watched directories
+func (e *ReplayEnvironment) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+	i, err := e.next(execInteractionType, e.placeholders.normalizeCommand(cmd), "")
+	if err != nil {
+		logrus.Errorf("%s", err)
+		return "", "", 0, err
+	}
+	for _, changes := range i.Changes {
+		dir := e.placeholders.denormalize(changes.Dir)
+		if !filepath.IsAbs(dir) || strings.Contains(dir, "${") {
+			logrus.Errorf("Unable to replay the changes to the directory %s since it is not available in this run", changes.Dir)
+			continue
+		}
+		if changes.Files != "" {
+			if err := filesystem.Merge(filepath.Join(e.FixturesDir, changes.Files), dir, false); err != nil {
+				logrus.Errorf("Unable to replay the changes to the directory %s : %s", dir, err)
+				return "", "", 0, err
+			}
+		}
+		for _, deletedPath := range changes.DeletedPaths {
+			if err := os.RemoveAll(filepath.Join(dir, filepath.FromSlash(deletedPath))); err != nil {
+				logrus.Errorf("Unable to replay the deletion of %s in the directory %s : %s", deletedPath, dir, err)
+			}
+		}
+	}
+	return e.placeholders.denormalize(i.Stdout), e.placeholders.denormalize(i.Stderr), i.ExitCode, e.getError(i)
+}
+
+// Download returns a copy of the recorded downloaded files
+func (e *ReplayEnvironment) Download(path string) (string, error) {
+	i, err := e.next(downloadInteractionType, nil, e.placeholders.normalize(path))
+	if err != nil {
+		logrus.Errorf("%s", err)
+		return path, err
+	}
+	if i.Error != "" {
+		return path, e.getError(i)
+	}
+	output, err := os.MkdirTemp(e.TempPath, "*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return path, err
+	}
+	files := filepath.Join(e.FixturesDir, i.Files)
+	fi, err := os.Stat(files)
+	if err != nil {
+		logrus.Errorf("Unable to stat the recorded files %s : %s", files, err)
+		return path, err
+	}
+	if fi.Mode().IsRegular() {
+		output = filepath.Join(output, filepath.Base(files))
+	}
+	if err := filesystem.Replicate(files, output); err != nil {
+		logrus.Errorf("Unable to replay the download of %s : %s", path, err)
+		return path, err
+	}
+	return output, nil
+}
+
+// Upload returns the recorded path in the environment. Paths in the temp directory are populated with the uploaded files.
+func (e *ReplayEnvironment) Upload(outpath string) (envpath string, err error) {
+	i, err := e.next(uploadInteractionType, nil, e.placeholders.normalize(outpath))
+	if err != nil {
+		logrus.Errorf("%s", err)
+		return outpath, err
+	}
+	if i.Error != "" {
+		return outpath, e.getError(i)
+	}
+	envpath = e.placeholders.denormalize(i.EnvPath)
+	if common.IsParent(envpath, e.TempPath) {
+		if err := filesystem.Replicate(outpath, envpath); err != nil {
+			logrus.Errorf("Unable to replay the upload of %s : %s", outpath, err)
+			return envpath, err
+		}
+	}
+	return envpath, nil
+}
+
+// Destroy does nothing since the replayed environment has no state
+func (e *ReplayEnvironment) Destroy() error {
+	return nil
+}
+
+// GetSource returns the recorded source of the environment
+func (e *ReplayEnvironment) GetSource() string {
+	return e.placeholders.denormalize(e.recording.Source)
+}
+
+// GetContext returns the recorded context of the environment
+func (e *ReplayEnvironment) GetContext() string {
+	return e.placeholders.denormalize(e.recording.Context)
+}
+
+// next returns the first interaction which has not been replayed yet and matches the command or path
+func (e *ReplayEnvironment) next(interactionType string, cmd environmenttypes.Command, path string) (*interaction, error) {
+	for idx := range e.recording.Interactions {
+		i := &e.recording.Interactions[idx]
+		if i.replayed || i.Type != interactionType || i.Path != path || strings.Join(i.Command, "\x00") != strings.Join(cmd, "\x00") {
+			continue
+		}
+		i.replayed = true
+		return i, nil
+	}
+	if interactionType == execInteractionType {
+		return nil, fmt.Errorf("no recorded %s interaction for the command %v in the recording of environment %s at %s", interactionType, cmd, e.Name, e.FixturesDir)
+	}
+	return nil, fmt.Errorf("no recorded %s interaction for the path %s in the recording of environment %s at %s", interactionType, path, e.Name, e.FixturesDir)
+}
+
+func (e *ReplayEnvironment) getError(i *interaction) error {
+	if i.Error == "" {
+		return nil
+	}
+	return errors.New(e.placeholders.denormalize(i.Error))
+}
+
+// snapshotDir returns the state of all the files in the directory. A directory which does not exist is empty.
+func snapshotDir(dir string) (map[string]syncedFileInfo, error) {
+	snapshot := map[string]syncedFileInfo{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return snapshot, nil
+	}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." || fi.Mode()&os.ModeSocket != 0 {
+			return err
+		}
+		info, err := getSyncedFileInfo(path, fi)
+		if err != nil {
+			return err
+		}
+		snapshot[rel] = info
+		return nil
+	})
+	return snapshot, err
+}
+
+// diffSnapshots returns the paths which were added or changed and the paths which were deleted between the snapshots.
+// The paths are sorted, so that the parent directories come before their contents.
+func diffSnapshots(before, after map[string]syncedFileInfo) (changed []string, deleted []string) {
+	for rel, info := range after {
+		if old, ok := before[rel]; !ok || old != info {
+			changed = append(changed, rel)
+		}
+	}
+	for rel := range before {
+		if _, ok := after[rel]; !ok {
+			deleted = append(deleted, rel)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
+	return changed, deleted
+}
@@ -0,0 +1,180 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+// newTestEnvInfo returns the info of an environment with new source, output, context and temp directories
+func newTestEnvInfo(t *testing.T) EnvInfo {
+	t.Helper()
+	envInfo := EnvInfo{Name: "test", Source: t.TempDir(), Output: t.TempDir(), Context: t.TempDir(), TempPath: t.TempDir()}
+	writeTestFile(t, filepath.Join(envInfo.Source, "src", "a.txt"), "a")
+	writeTestFile(t, filepath.Join(envInfo.TempPath, "old.txt"), "old")
+	return envInfo
+}
+
+// getTestScript returns a script which prints the source path and changes the temp, output and source directories
+func getTestScript(envInfo EnvInfo) environmenttypes.Command {
+	return environmenttypes.Command{"/bin/sh", "-c", "echo " + envInfo.Source +
+		" && echo new > " + filepath.Join(envInfo.TempPath, "new.txt") + " && rm " + filepath.Join(envInfo.TempPath, "old.txt") +
+		" && mkdir -p " + filepath.Join(envInfo.Output, "deploy") + " && echo out > " + filepath.Join(envInfo.Output, "deploy", "out.txt") +
+		" && echo changed > " + filepath.Join(envInfo.Source, "src", "a.txt")}
+}
+
+func TestRecordReplay(t *testing.T) {
+	common.TempPath = t.TempDir()
+	fixturesPath := t.TempDir()
+
+	recordEnvInfo := newTestEnvInfo(t)
+	local, err := NewLocal(recordEnvInfo, nil, environmenttypes.Container{})
+	if err != nil {
+		t.Fatalf("failed to create the local environment. Error: %q", err)
+	}
+	recorder, err := NewRecordingEnvironment(recordEnvInfo, local, fixturesPath)
+	if err != nil {
+		t.Fatalf("failed to create the recording environment. Error: %q", err)
+	}
+	recordedStdout, _, exitcode, err := recorder.Exec(getTestScript(recordEnvInfo))
+	if err != nil || exitcode != 0 {
+		t.Fatalf("failed to execute the command. Exit code: %d Error: %v", exitcode, err)
+	}
+	if _, err := recorder.Download(filepath.Join(recordEnvInfo.Source, "src")); err != nil {
+		t.Fatalf("failed to download. Error: %q", err)
+	}
+	if _, _, exitcode, _ := recorder.Exec(environmenttypes.Command{"/bin/sh", "-c", "exit 3"}); exitcode != 3 {
+		t.Fatalf("the exit code is incorrect. Expected: 3 Actual: %d", exitcode)
+	}
+
+	// The replay uses different paths, which are substituted for the recorded ones
+	fixtureDirsMutex.Lock()
+	fixtureDirs = map[string]int{}
+	fixtureDirsMutex.Unlock()
+	replayEnvInfo := newTestEnvInfo(t)
+	replayer, err := NewReplayEnvironment(replayEnvInfo, fixturesPath)
+	if err != nil {
+		t.Fatalf("failed to create the replay environment. Error: %q", err)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		if replayer.GetSource() != replayEnvInfo.Source {
+			t.Fatalf("the source is incorrect. Expected: %s Actual: %s", replayEnvInfo.Source, replayer.GetSource())
+		}
+		stdout, _, exitcode, err := replayer.Exec(getTestScript(replayEnvInfo))
+		if err != nil || exitcode != 0 {
+			t.Fatalf("failed to replay the command. Exit code: %d Error: %v", exitcode, err)
+		}
+		if want := strings.ReplaceAll(recordedStdout, recordEnvInfo.Source, replayEnvInfo.Source); stdout != want {
+			t.Fatalf("the replayed output is incorrect. Expected: %q Actual: %q", want, stdout)
+		}
+		checkTestFiles(t, replayEnvInfo.TempPath, map[string]string{"new.txt": "new\n"})
+		checkTestFiles(t, replayEnvInfo.Output, map[string]string{filepath.Join("deploy", "out.txt"): "out\n"})
+		checkTestFiles(t, replayEnvInfo.Source, map[string]string{filepath.Join("src", "a.txt"): "changed\n"})
+		outpath, err := replayer.Download(filepath.Join(replayEnvInfo.Source, "src"))
+		if err != nil {
+			t.Fatalf("failed to replay the download. Error: %q", err)
+		}
+		checkTestFiles(t, outpath, map[string]string{"a.txt": "changed\n"})
+		if _, _, exitcode, _ := replayer.Exec(environmenttypes.Command{"/bin/sh", "-c", "exit 3"}); exitcode != 3 {
+			t.Fatalf("the replayed exit code is incorrect. Expected: 3 Actual: %d", exitcode)
+		}
+	})
+
+	t.Run("command replayed more times than recorded", func(t *testing.T) {
+		if _, _, _, err := replayer.Exec(getTestScript(replayEnvInfo)); err == nil {
+			t.Fatalf("should have failed since the command was recorded only once")
+		}
+	})
+
+	t.Run("command which was not recorded", func(t *testing.T) {
+		if _, _, _, err := replayer.Exec(environmenttypes.Command{"/bin/sh", "-c", "echo other"}); err == nil {
+			t.Fatalf("should have failed since the command was not recorded")
+		}
+	})
+
+	t.Run("download which was not recorded", func(t *testing.T) {
+		if _, err := replayer.Download(filepath.Join(replayEnvInfo.Source, "other")); err == nil {
+			t.Fatalf("should have failed since the download was not recorded")
+		}
+	})
+
+	t.Run("missing recording", func(t *testing.T) {
+		if _, err := NewReplayEnvironment(EnvInfo{Name: "missing", TempPath: t.TempDir()}, fixturesPath); err == nil {
+			t.Fatalf("should have failed since there is no recording for the environment")
+		}
+	})
+}
+
+func TestSnapshotDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "a")
+	writeTestFile(t, filepath.Join(dir, "dir", "b.txt"), "b")
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("failed to create the symlink. Error: %q", err)
+	}
+	before, err := snapshotDir(dir)
+	if err != nil {
+		t.Fatalf("failed to snapshot the directory. Error: %q", err)
+	}
+	if len(before) != 4 || before["link"].linkTarget != "a.txt" || before[filepath.Join("dir", "b.txt")].size != 1 {
+		t.Fatalf("the snapshot is incorrect. Actual: %+v", before)
+	}
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "changed")
+	after, err := snapshotDir(dir)
+	if err != nil {
+		t.Fatalf("failed to snapshot the directory. Error: %q", err)
+	}
+	if after["a.txt"] == before["a.txt"] {
+		t.Fatalf("the snapshot should have changed for the changed file")
+	}
+	if after["dir"] != before["dir"] || after["link"] != before["link"] {
+		t.Fatalf("the snapshot should not have changed for the unchanged paths")
+	}
+}
+
+func TestGetWatchedDirs(t *testing.T) {
+	envInfo := EnvInfo{Source: "/src", Output: "/out", Context: "/ctx", TempPath: "/tmp/m2k"}
+	nestedEnvInfo := EnvInfo{Source: "/src", Output: "/src/out", Context: "/ctx", TempPath: "/tmp/m2k"}
+	isolatedEnvInfo := envInfo
+	isolatedEnvInfo.Isolated = true
+	testCases := []struct {
+		name    string
+		envInfo EnvInfo
+		env     EnvironmentInstance
+		want    []string
+	}{
+		{name: "local", envInfo: envInfo, env: &Local{}, want: []string{"/tmp/m2k", "/out", "/src", "/ctx"}},
+		{name: "isolated local", envInfo: isolatedEnvInfo, env: &Local{}, want: []string{"/tmp/m2k", "/out"}},
+		{name: "nested output", envInfo: nestedEnvInfo, env: &Local{}, want: []string{"/tmp/m2k", "/src/out", "/ctx"}},
+		{name: "not local", envInfo: envInfo, env: nil, want: []string{"/tmp/m2k"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, getWatchedDirs(tc.envInfo, tc.env)); diff != "" {
+				t.Fatalf("the watched directories are incorrect. Difference:\n%s", diff)
+			}
+		})
+	}
+}
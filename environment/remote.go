@@ -0,0 +1,566 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dchest/uniuri"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/sshkeys"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/pkg/sftp"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const (
+	defaultSSHPort          = 22
+	defaultRemoteWorkingDir = "/tmp"
+	sshAuthSockEnvName      = "SSH_AUTH_SOCK"
+	remoteSyncedDir         = "synced"
+)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Remote runs the environment on a remote host over SSH
+type Remote struct {
+	EnvInfo
+
+	WorkspaceSource  string
+	WorkspaceContext string
+
+	GRPCQAReceiver net.Addr
+
+	Host           string
+	RemoteTempPath string // The directory on the remote host which contains all the files of the environment
+
+	client     *ssh.Client
+	sftpClient *sftp.Client
+	// qaListener listens on the remote host and forwards the connections to the gRPC QA receiver
+	qaListener net.Listener
+	// syncedPaths stores the state of the files synced into each directory on the remote host
+	syncedPaths   map[string]map[string]syncedFileInfo
+	uploadedPaths []string
+}
+
+// NewRemote creates an environment which runs the commands on a remote host over SSH
+func NewRemote(envInfo EnvInfo, grpcQAReceiver net.Addr, r environmenttypes.Remote) (ei EnvironmentInstance, err error) {
+	port := r.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	remote := &Remote{
+		EnvInfo:        envInfo,
+		GRPCQAReceiver: grpcQAReceiver,
+		Host:           net.JoinHostPort(r.Host, strconv.Itoa(port)),
+		syncedPaths:    map[string]map[string]syncedFileInfo{},
+	}
+	config, err := getSSHClientConfig(r.Host, port, r.User)
+	if err != nil {
+		logrus.Errorf("Unable to get the ssh config for the host %s : %s", remote.Host, err)
+		return remote, err
+	}
+	if remote.client, err = ssh.Dial("tcp", remote.Host, config); err != nil {
+		logrus.Errorf("Unable to connect to the host %s : %s", remote.Host, err)
+		return remote, err
+	}
+	if remote.sftpClient, err = sftp.NewClient(remote.client); err != nil {
+		logrus.Errorf("Unable to start sftp on the host %s : %s", remote.Host, err)
+		remote.client.Close()
+		return remote, err
+	}
+	workingDir := r.WorkingDir
+	if workingDir == "" {
+		workingDir = defaultRemoteWorkingDir
+	}
+	stdout, stderr, exitcode, err := remote.run("mktemp -d " + shellQuote(path.Join(workingDir, types.AppNameShort+"-XXXXXX")))
+	if err != nil || exitcode != 0 {
+		err = fmt.Errorf("unable to create a temp dir in %s on the host %s : %v %s", workingDir, remote.Host, err, stderr)
+		logrus.Errorf("%s", err)
+		remote.close()
+		return remote, err
+	}
+	remote.RemoteTempPath = strings.TrimSpace(stdout)
+	remote.WorkspaceSource = path.Join(remote.RemoteTempPath, DefaultWorkspaceDir)
+	remote.WorkspaceContext = path.Join(remote.RemoteTempPath, types.AppNameShort)
+	if remote.GRPCQAReceiver != nil {
+		if err := remote.forwardQAReceiver(); err != nil {
+			logrus.Warnf("Unable to forward a port on the host %s to the QA gRPC server. The transformer will not be able to ask questions : %s", remote.Host, err)
+		}
+	}
+	if err := remote.Reset(); err != nil {
+		remote.Destroy()
+		return remote, err
+	}
+	return remote, nil
+}
+
+// getSSHClientConfig returns the ssh config using the keys of the ssh agent and the ssh keys of the user, and the known hosts of the user
+func getSSHClientConfig(host string, port int, username string) (*ssh.ClientConfig, error) {
+	if username == "" {
+		usr, err := user.Current()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the current user : %w", err)
+		}
+		username = usr.Username
+	}
+	authMethods := []ssh.AuthMethod{}
+	if sock := os.Getenv(sshAuthSockEnvName); sock != "" {
+		if conn, err := net.Dial("unix", sock); err != nil {
+			logrus.Debugf("Unable to connect to the ssh agent at %s : %s", sock, err)
+		} else {
+			authMethods = append(authMethods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	if key, ok := sshkeys.GetSSHKey(host); ok {
+		signer, err := ssh.ParsePrivateKey([]byte(key))
+		if err != nil {
+			logrus.Warnf("Unable to parse the ssh key for the host %s : %s", host, err)
+		} else {
+			authMethods = append(authMethods, ssh.PublicKeys(signer))
+		}
+	}
+	if len(authMethods) == 0 {
+		return nil, fmt.Errorf("no ssh agent or ssh key found to authenticate with the host %s", host)
+	}
+	hostKeyCallback, err := getHostKeyCallback(host, port)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:            username,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+// getHostKeyCallback returns a callback which only accepts the public keys of the host in the known hosts
+func getHostKeyCallback(host string, port int) (ssh.HostKeyCallback, error) {
+	sshkeys.LoadKnownHostsOfCurrentUser()
+	knownHost := host
+	if port != defaultSSHPort {
+		knownHost = "[" + host + "]:" + strconv.Itoa(port)
+	}
+	knownKeys := []ssh.PublicKey{}
+	for _, line := range sshkeys.DomainToPublicKeys[knownHost] {
+		_, _, key, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err != nil {
+			logrus.Debugf("Unable to parse the known hosts line %s : %s", line, err)
+			continue
+		}
+		knownKeys = append(knownKeys, key)
+	}
+	if len(knownKeys) == 0 {
+		return nil, fmt.Errorf("no public key found for the host %s. Add it to the known_hosts file", knownHost)
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		for _, knownKey := range knownKeys {
+			if bytes.Equal(knownKey.Marshal(), key.Marshal()) {
+				return nil
+			}
+		}
+		return fmt.Errorf("the public key of the host %s does not match any of the known public keys", hostname)
+	}, nil
+}
+
+// Reset removes the uploaded paths, syncs the source and the context into the remote host and
+// replaces the workspace with a fresh copy of them, discarding the changes made by the previous executions
+func (e *Remote) Reset() error {
+	if len(e.uploadedPaths) > 0 {
+		if err := e.removePaths(e.uploadedPaths); err != nil {
+			logrus.Errorf("Unable to remove the uploaded paths from the host %s : %s", e.Host, err)
+			return err
+		}
+		e.uploadedPaths = nil
+	}
+	syncedSource := path.Join(e.RemoteTempPath, remoteSyncedDir, DefaultWorkspaceDir)
+	if err := e.syncDir(e.Source, syncedSource); err != nil {
+		logrus.Errorf("Unable to sync the source into the host %s : %s", e.Host, err)
+		return err
+	}
+	syncedContext := path.Join(e.RemoteTempPath, remoteSyncedDir, types.AppNameShort)
+	if err := e.syncDir(e.Context, syncedContext); err != nil {
+		logrus.Errorf("Unable to sync the context into the host %s : %s", e.Host, err)
+		return err
+	}
+	cmd := "rm -rf " + shellQuote(e.WorkspaceSource) + " " + shellQuote(e.WorkspaceContext) +
+		" && mkdir -p " + shellQuote(e.WorkspaceSource) + " " + shellQuote(e.WorkspaceContext) +
+		" && cp -a " + shellQuote(syncedSource+"/.") + " " + shellQuote(e.WorkspaceSource) +
+		" && cp -a " + shellQuote(syncedContext+"/.") + " " + shellQuote(e.WorkspaceContext)
+	if _, stderr, exitcode, err := e.run(cmd); err != nil || exitcode != 0 {
+		err = fmt.Errorf("unable to copy the synced source and context into the workspace on the host %s : %v %s", e.Host, err, stderr)
+		logrus.Errorf("%s", err)
+		return err
+	}
+	return nil
+}
+
+// forwardQAReceiver listens on the loopback interface of the remote host and forwards the connections to the gRPC QA receiver
+// through the ssh connection, so that the commands can ask questions even when the remote host cannot connect to this machine
+func (e *Remote) forwardQAReceiver() error {
+	listener, err := e.client.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	e.qaListener = listener
+	go func() {
+		for {
+			remoteConn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go e.forwardQAConnection(remoteConn)
+		}
+	}()
+	return nil
+}
+
+func (e *Remote) forwardQAConnection(remoteConn net.Conn) {
+	defer remoteConn.Close()
+	localConn, err := net.Dial(e.GRPCQAReceiver.Network(), e.GRPCQAReceiver.String())
+	if err != nil {
+		logrus.Errorf("Unable to connect to the QA gRPC server at %s : %s", e.GRPCQAReceiver, err)
+		return
+	}
+	defer localConn.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(localConn, remoteConn)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(remoteConn, localConn)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// Exec executes a command on the remote host
+func (e *Remote) Exec(cmd environmenttypes.Command) (stdout string, stderr string, exitcode int, err error) {
+	if len(cmd) == 0 {
+		err := fmt.Errorf("no command found to execute")
+		logrus.Errorf("%s", err)
+		return "", "", 0, err
+	}
+	env := append([]string{}, e.Env...)
+	if e.qaListener != nil {
+		env = append(env, GRPCEnvName+"="+e.qaListener.Addr().String())
+	}
+	return e.run(getRemoteCommand(e.WorkspaceContext, env, cmd))
+}
+
+// getRemoteCommand returns the shell command which runs the command in the working directory with the environment variables
+func getRemoteCommand(workingDir string, env []string, cmd environmenttypes.Command) string {
+	cmdStr := "cd " + shellQuote(workingDir) + " &&"
+	for _, envvar := range env {
+		nameValue := strings.SplitN(envvar, "=", 2)
+		if len(nameValue) != 2 {
+			continue
+		}
+		// The name is not quoted, so it must not contain any characters interpreted by the shell
+		if !envNameRegex.MatchString(nameValue[0]) {
+			logrus.Errorf("Ignoring the environment variable %s since it is not a valid name", nameValue[0])
+			continue
+		}
+		cmdStr += " " + nameValue[0] + "=" + shellQuote(nameValue[1])
+	}
+	for _, arg := range cmd {
+		cmdStr += " " + shellQuote(arg)
+	}
+	return cmdStr
+}
+
+// Destroy removes all the files of the environment from the remote host and closes the connection
+func (e *Remote) Destroy() error {
+	if e.client == nil {
+		return nil
+	}
+	if e.RemoteTempPath != "" {
+		if err := e.removePaths([]string{e.RemoteTempPath}); err != nil {
+			logrus.Errorf("Unable to remove the directory %s from the host %s : %s", e.RemoteTempPath, e.Host, err)
+		}
+	}
+	e.close()
+	return nil
+}
+
+// Download downloads the path from the remote host
+func (e *Remote) Download(remotePath string) (string, error) {
+	output, err := os.MkdirTemp(e.TempPath, "*")
+	if err != nil {
+		logrus.Errorf("Unable to create temp dir : %s", err)
+		return remotePath, err
+	}
+	fi, err := e.sftpClient.Lstat(remotePath)
+	if err != nil {
+		logrus.Errorf("Unable to stat the path %s on the host %s : %s", remotePath, e.Host, err)
+		return remotePath, err
+	}
+	if fi.Mode().IsRegular() {
+		output = filepath.Join(output, path.Base(remotePath))
+	}
+	if err := e.download(remotePath, output); err != nil {
+		logrus.Errorf("Unable to download the path %s from the host %s : %s", remotePath, e.Host, err)
+		return remotePath, err
+	}
+	return output, nil
+}
+
+// Upload uploads the path to the remote host
+func (e *Remote) Upload(outpath string) (envpath string, err error) {
+	fi, err := os.Stat(outpath)
+	if err != nil {
+		logrus.Errorf("Unable to stat source : %s", outpath)
+		return "", err
+	}
+	uploadDir := path.Join(e.RemoteTempPath, "uploads", strings.ToLower(uniuri.NewLen(10)))
+	envpath = uploadDir
+	if fi.Mode().IsRegular() {
+		envpath = path.Join(uploadDir, filepath.Base(outpath))
+	}
+	e.uploadedPaths = append(e.uploadedPaths, uploadDir)
+	if err := e.sftpClient.MkdirAll(path.Dir(envpath)); err != nil {
+		logrus.Errorf("Unable to create the directory %s on the host %s : %s", path.Dir(envpath), e.Host, err)
+		return outpath, err
+	}
+	err = filepath.Walk(outpath, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(outpath, p)
+		if err != nil {
+			return err
+		}
+		info, err := getSyncedFileInfo(p, fi)
+		if err != nil {
+			return err
+		}
+		return e.uploadFile(p, path.Join(envpath, filepath.ToSlash(rel)), fi, info.linkTarget)
+	})
+	if err != nil {
+		logrus.Errorf("Unable to upload %s to the host %s : %s", outpath, e.Host, err)
+		return outpath, err
+	}
+	return envpath, nil
+}
+
+// GetContext returns the context on the remote host
+func (e *Remote) GetContext() string {
+	return e.WorkspaceContext
+}
+
+// GetSource returns the source on the remote host
+func (e *Remote) GetSource() string {
+	return e.WorkspaceSource
+}
+
+// run runs a shell command on the remote host
+func (e *Remote) run(cmd string) (stdout string, stderr string, exitcode int, err error) {
+	session, err := e.client.NewSession()
+	if err != nil {
+		logrus.Errorf("Unable to start a session on the host %s : %s", e.Host, err)
+		return "", "", 0, err
+	}
+	defer session.Close()
+	var outb, errb bytes.Buffer
+	session.Stdout = &outb
+	session.Stderr = &errb
+	if err := session.Run(cmd); err != nil {
+		var ee *ssh.ExitError
+		if !errors.As(err, &ee) {
+			logrus.Errorf("Error during execution of command on the host %s : %s", e.Host, err)
+			return outb.String(), errb.String(), 0, err
+		}
+		exitcode = ee.ExitStatus()
+	}
+	return outb.String(), errb.String(), exitcode, nil
+}
+
+func (e *Remote) removePaths(paths []string) error {
+	cmd := "rm -rf"
+	for _, p := range paths {
+		cmd += " " + shellQuote(p)
+	}
+	_, stderr, exitcode, err := e.run(cmd)
+	if err != nil {
+		return err
+	}
+	if exitcode != 0 {
+		return fmt.Errorf("%s", stderr)
+	}
+	return nil
+}
+
+// syncDir copies the files in src which changed since the last sync into dest on the remote host and removes the deleted files
+func (e *Remote) syncDir(src, dest string) error {
+	synced, ok := e.syncedPaths[dest]
+	if !ok {
+		synced = map[string]syncedFileInfo{}
+		if err := e.sftpClient.MkdirAll(dest); err != nil {
+			return err
+		}
+	}
+	current, err := snapshotDir(src)
+	if err != nil {
+		return err
+	}
+	changed, deleted := diffSnapshots(synced, current)
+	if len(deleted) > 0 {
+		deletedPaths := []string{}
+		for _, rel := range deleted {
+			deletedPaths = append(deletedPaths, path.Join(dest, filepath.ToSlash(rel)))
+		}
+		if err := e.removePaths(deletedPaths); err != nil {
+			return err
+		}
+	}
+	for _, rel := range changed {
+		p := filepath.Join(src, rel)
+		fi, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		if err := e.uploadFile(p, path.Join(dest, filepath.ToSlash(rel)), fi, current[rel].linkTarget); err != nil {
+			return err
+		}
+	}
+	logrus.Debugf("Synced %s into %s on the host %s. %d changed and %d deleted", src, dest, e.Host, len(changed), len(deleted))
+	e.syncedPaths[dest] = current
+	return nil
+}
+
+// uploadFile copies a file, directory or symlink to the remote host
+func (e *Remote) uploadFile(localPath, remotePath string, fi os.FileInfo, linkTarget string) error {
+	if fi.IsDir() {
+		if err := e.sftpClient.MkdirAll(remotePath); err != nil {
+			return err
+		}
+		return e.sftpClient.Chmod(remotePath, fi.Mode().Perm())
+	}
+	if err := e.sftpClient.MkdirAll(path.Dir(remotePath)); err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		if _, err := e.sftpClient.Lstat(remotePath); err == nil {
+			if err := e.sftpClient.Remove(remotePath); err != nil {
+				return err
+			}
+		}
+		return e.sftpClient.Symlink(linkTarget, remotePath)
+	}
+	if !fi.Mode().IsRegular() {
+		return nil
+	}
+	in, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := e.sftpClient.OpenFile(remotePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return e.sftpClient.Chmod(remotePath, fi.Mode().Perm())
+}
+
+// download copies a path from the remote host
+func (e *Remote) download(remotePath, localPath string) error {
+	walker := e.sftpClient.Walk(remotePath)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), remotePath), "/")
+		dest := filepath.Join(localPath, filepath.FromSlash(rel))
+		fi := walker.Stat()
+		if fi.IsDir() {
+			if err := os.MkdirAll(dest, common.DefaultDirectoryPermission); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), common.DefaultDirectoryPermission); err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := e.sftpClient.ReadLink(walker.Path())
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(target, dest); err != nil {
+				return err
+			}
+			continue
+		}
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		if err := e.downloadFile(walker.Path(), dest, fi.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Remote) downloadFile(remotePath, localPath string, perm os.FileMode) error {
+	in, err := e.sftpClient.Open(remotePath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func (e *Remote) close() {
+	if e.qaListener != nil {
+		e.qaListener.Close()
+	}
+	if e.sftpClient != nil {
+		e.sftpClient.Close()
+	}
+	e.client.Close()
+	e.client = nil
+}
+
+// shellQuote quotes the string to be used as a single word in a POSIX shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,151 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShellQuote(t *testing.T) {
+	testcases := []string{
+		"",
+		"simple",
+		"with space",
+		"it's",
+		`"double" and 'single' quotes`,
+		"$HOME `id` $(id) ${PATH}",
+		"semi; colon && and || or | pipe",
+		"new\nline\ttab",
+		`back\slash`,
+		"*?[glob]",
+	}
+	for _, tc := range testcases {
+		t.Run(tc, func(t *testing.T) {
+			output, err := exec.Command("/bin/sh", "-c", "printf '%s' "+shellQuote(tc)).Output()
+			if err != nil {
+				t.Fatalf("failed to run the shell command. Error: %q", err)
+			}
+			if string(output) != tc {
+				t.Fatalf("the quoted string was not passed as is to the command. Expected: %q Actual: %q", tc, string(output))
+			}
+		})
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	modTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	file := syncedFileInfo{size: 1, modTime: modTime, mode: 0644}
+	dir := syncedFileInfo{mode: 0755}
+	testcases := []struct {
+		name        string
+		before      map[string]syncedFileInfo
+		after       map[string]syncedFileInfo
+		wantChanged []string
+		wantDeleted []string
+	}{
+		{
+			name:   "no changes",
+			before: map[string]syncedFileInfo{"a": file, "dir": dir},
+			after:  map[string]syncedFileInfo{"a": file, "dir": dir},
+		},
+		{
+			name:        "first sync",
+			after:       map[string]syncedFileInfo{"dir/b": file, "dir": dir, "a": file},
+			wantChanged: []string{"a", "dir", "dir/b"},
+		},
+		{
+			name:        "changed size",
+			before:      map[string]syncedFileInfo{"a": file},
+			after:       map[string]syncedFileInfo{"a": {size: 2, modTime: modTime, mode: 0644}},
+			wantChanged: []string{"a"},
+		},
+		{
+			name:        "changed modification time",
+			before:      map[string]syncedFileInfo{"a": file},
+			after:       map[string]syncedFileInfo{"a": {size: 1, modTime: modTime.Add(time.Second), mode: 0644}},
+			wantChanged: []string{"a"},
+		},
+		{
+			name:        "changed mode",
+			before:      map[string]syncedFileInfo{"a": file},
+			after:       map[string]syncedFileInfo{"a": {size: 1, modTime: modTime, mode: 0755}},
+			wantChanged: []string{"a"},
+		},
+		{
+			name:        "changed symlink target",
+			before:      map[string]syncedFileInfo{"link": {linkTarget: "a"}},
+			after:       map[string]syncedFileInfo{"link": {linkTarget: "b"}},
+			wantChanged: []string{"link"},
+		},
+		{
+			name:        "deleted and added",
+			before:      map[string]syncedFileInfo{"a": file, "dir": dir, "dir/b": file},
+			after:       map[string]syncedFileInfo{"a": file, "c": file},
+			wantChanged: []string{"c"},
+			wantDeleted: []string{"dir", "dir/b"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			changed, deleted := diffSnapshots(tc.before, tc.after)
+			if diff := cmp.Diff(tc.wantChanged, changed); diff != "" {
+				t.Fatalf("the changed paths are incorrect. Differences:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, deleted); diff != "" {
+				t.Fatalf("the deleted paths are incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRemoteCommand(t *testing.T) {
+	workingDir := t.TempDir()
+	env := []string{
+		"FOO=foo bar",
+		"QUOTED='$HOME'",
+		"EMPTY=",
+		"_UNDER_score1=x",
+		"NOVALUE",
+		"BAD;touch " + workingDir + "/injected=x",
+		"1BAD=x",
+		"BAD-NAME=x",
+		"$(id)=x",
+	}
+	cmdStr := getRemoteCommand(workingDir, env, []string{"/bin/sh", "-c", `printf '%s|%s|%s|%s|%s' "$FOO" "$QUOTED" "$EMPTY" "$_UNDER_score1" "$(pwd)"`})
+	output, err := exec.Command("/bin/sh", "-c", cmdStr).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to run the command %s . Error: %q Output: %s", cmdStr, err, output)
+	}
+	if want := "foo bar|'$HOME'||x|" + workingDir; string(output) != want {
+		t.Fatalf("the command got the wrong environment. Expected: %q Actual: %q", want, string(output))
+	}
+	if _, err := os.Stat(filepath.Join(workingDir, "injected")); !os.IsNotExist(err) {
+		t.Fatalf("the invalid environment variable name should not have been passed to the shell. Error: %v", err)
+	}
+	for _, invalid := range []string{"1BAD", "BAD-NAME", "$(id)", "BAD;"} {
+		if strings.Contains(cmdStr, invalid) {
+			t.Fatalf("the invalid environment variable name %s should have been ignored. Command: %s", invalid, cmdStr)
+		}
+	}
+}
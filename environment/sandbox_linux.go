@@ -0,0 +1,153 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/konveyor/move2kube/types"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// sandboxInitName is the name used to re-execute move2kube as the init process of a namespace sandbox
+	sandboxInitName = types.AppNameShort + "-sandbox-init"
+	// bubblewrapCmd is the bubblewrap executable, which is preferred when available
+	bubblewrapCmd = "bwrap"
+)
+
+var (
+	// sandboxReadOnlyPathsEnvName is used to pass the paths which have to be made read only to the sandbox init
+	sandboxReadOnlyPathsEnvName = strings.ToUpper(types.AppNameShort) + "_SANDBOX_READONLY_PATHS"
+	// sandboxWritablePathsEnvName is used to pass the paths which have to stay writable to the sandbox init
+	sandboxWritablePathsEnvName = strings.ToUpper(types.AppNameShort) + "_SANDBOX_WRITABLE_PATHS"
+)
+
+func init() {
+	reexec.Register(sandboxInitName, sandboxInit)
+}
+
+// getSandboxedCommand returns a command which runs without network access and with write access only to the writable paths.
+// bubblewrap is used when available. Otherwise user, mount and network namespaces are used,
+// in which case the whole file system is made read only using mount_setattr, which needs Linux 5.12 or later.
+// If the file system cannot be made read only the sandboxed command fails instead of running with write access.
+func getSandboxedCommand(cmd environmenttypes.Command, readOnlyPaths, writablePaths []string, workingDir string, env []string) (*exec.Cmd, error) {
+	if len(cmd) == 0 {
+		return nil, fmt.Errorf("no command found to execute")
+	}
+	if bwrapPath, err := exec.LookPath(bubblewrapCmd); err == nil {
+		args := []string{"--die-with-parent", "--new-session", "--unshare-all", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"}
+		for _, writablePath := range writablePaths {
+			args = append(args, "--bind", writablePath, writablePath)
+		}
+		for _, readOnlyPath := range readOnlyPaths {
+			args = append(args, "--ro-bind", readOnlyPath, readOnlyPath)
+		}
+		args = append(args, "--chdir", workingDir, "--")
+		args = append(args, cmd...)
+		execcmd := exec.Command(bwrapPath, args...)
+		execcmd.Env = env
+		return execcmd, nil
+	}
+	logrus.Debugf("%s not found. Using namespaces to sandbox the execution.", bubblewrapCmd)
+	execcmd := reexec.Command(append([]string{sandboxInitName}, cmd...)...)
+	execcmd.Dir = workingDir
+	execcmd.Env = append(env,
+		sandboxReadOnlyPathsEnvName+"="+strings.Join(readOnlyPaths, string(os.PathListSeparator)),
+		sandboxWritablePathsEnvName+"="+strings.Join(writablePaths, string(os.PathListSeparator)),
+	)
+	execcmd.SysProcAttr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET
+	execcmd.SysProcAttr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+	execcmd.SysProcAttr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	execcmd.SysProcAttr.GidMappingsEnableSetgroups = false
+	return execcmd, nil
+}
+
+// sandboxInit runs inside the namespaces, makes everything other than the writable paths read only and then executes the command
+func sandboxInit() {
+	readOnlyPaths := filepath.SplitList(os.Getenv(sandboxReadOnlyPathsEnvName))
+	writablePaths := filepath.SplitList(os.Getenv(sandboxWritablePathsEnvName))
+	os.Unsetenv(sandboxReadOnlyPathsEnvName)
+	os.Unsetenv(sandboxWritablePathsEnvName)
+	// Make all the mounts private so that the changes are not propagated outside the sandbox
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		sandboxInitFailed(fmt.Errorf("unable to make the mounts private : %s", err))
+	}
+	if err := unix.MountSetattr(-1, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY}); err != nil {
+		sandboxInitFailed(fmt.Errorf("unable to make the file system read only : %s", err))
+	}
+	for _, writablePath := range writablePaths {
+		if err := makeWritable(writablePath); err != nil {
+			sandboxInitFailed(fmt.Errorf("unable to make the path %s writable : %s", writablePath, err))
+		}
+	}
+	for _, readOnlyPath := range readOnlyPaths {
+		if err := makeReadOnly(readOnlyPath); err != nil {
+			sandboxInitFailed(fmt.Errorf("unable to make the path %s read only : %s", readOnlyPath, err))
+		}
+	}
+	// The working directory has to be entered again to be on the read only mounts
+	if wd, err := os.Getwd(); err == nil {
+		if err := os.Chdir(wd); err != nil {
+			sandboxInitFailed(fmt.Errorf("unable to change to the working directory %s : %s", wd, err))
+		}
+	}
+	if len(os.Args) < 2 {
+		sandboxInitFailed(fmt.Errorf("no command found to execute"))
+	}
+	cmdPath, err := exec.LookPath(os.Args[1])
+	if err != nil {
+		sandboxInitFailed(err)
+	}
+	sandboxInitFailed(syscall.Exec(cmdPath, os.Args[1:], os.Environ()))
+}
+
+// makeWritable bind mounts the path on itself and makes the new mounts writable
+func makeWritable(path string) error {
+	if err := syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return err
+	}
+	return unix.MountSetattr(-1, path, unix.AT_RECURSIVE, &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY})
+}
+
+// makeReadOnly bind mounts the path on itself and remounts it as read only
+func makeReadOnly(path string) error {
+	if err := syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return err
+	}
+	statfs := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &statfs); err != nil {
+		return err
+	}
+	// The nosuid, nodev and noexec flags of the original mount are locked inside a user namespace and have to be preserved.
+	// Their statfs flags have the same values as the mount flags.
+	lockedFlags := uintptr(statfs.Flags) & (syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC)
+	return syscall.Mount("", path, "", syscall.MS_REMOUNT|syscall.MS_BIND|syscall.MS_RDONLY|lockedFlags, "")
+}
+
+func sandboxInitFailed(err error) {
+	fmt.Fprintf(os.Stderr, "%s : %s\n", sandboxInitName, err)
+	os.Exit(126)
+}
@@ -0,0 +1,91 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func TestGetSandboxedCommandWithNamespaces(t *testing.T) {
+	// bubblewrap is not found without a PATH, so the namespaces are used.
+	t.Setenv("PATH", "")
+	env := []string{"PATH=/usr/bin:/bin"}
+
+	runInSandbox := func(t *testing.T, script string, readOnlyPaths, writablePaths []string, workingDir string) (string, error) {
+		t.Helper()
+		cmd, err := getSandboxedCommand(environmenttypes.Command{"sh", "-c", script}, readOnlyPaths, writablePaths, workingDir, env)
+		if err != nil {
+			t.Fatalf("failed to get the sandboxed command. Error: %q", err)
+		}
+		output, err := cmd.CombinedOutput()
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOSYS) {
+			t.Skipf("user namespaces are not available. Error: %q", err)
+		}
+		if strings.Contains(string(output), "unable to make the file system read only") {
+			t.Skipf("the file system cannot be made read only. Output: %s", output)
+		}
+		return string(output), err
+	}
+
+	t.Run("write to a writable path", func(t *testing.T) {
+		writableDir := t.TempDir()
+		if output, err := runInSandbox(t, "echo foo > out.txt", nil, []string{writableDir}, writableDir); err != nil {
+			t.Fatalf("failed to write to the writable path %s . Error: %q Output: %s", writableDir, err, output)
+		}
+		data, err := os.ReadFile(filepath.Join(writableDir, "out.txt"))
+		if err != nil {
+			t.Fatalf("failed to read the file written in the sandbox. Error: %q", err)
+		}
+		if string(data) != "foo\n" {
+			t.Fatalf("the file written in the sandbox has the wrong contents. Expected: %q Actual: %q", "foo\n", string(data))
+		}
+	})
+
+	t.Run("write outside the writable paths", func(t *testing.T) {
+		writableDir := t.TempDir()
+		outsideDir := t.TempDir()
+		outsidePath := filepath.Join(outsideDir, "out.txt")
+		if output, err := runInSandbox(t, "echo foo > "+outsidePath, nil, []string{writableDir}, writableDir); err == nil {
+			t.Fatalf("should have failed to write outside the writable paths. Output: %s", output)
+		}
+		if _, err := os.Stat(outsidePath); !os.IsNotExist(err) {
+			t.Fatalf("the file %s should not have been created. Error: %v", outsidePath, err)
+		}
+	})
+
+	t.Run("write to a read only path inside a writable path", func(t *testing.T) {
+		writableDir := t.TempDir()
+		readOnlyDir := filepath.Join(writableDir, "readonly")
+		if err := os.Mkdir(readOnlyDir, os.ModePerm); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", readOnlyDir, err)
+		}
+		readOnlyPath := filepath.Join(readOnlyDir, "out.txt")
+		if output, err := runInSandbox(t, "echo foo > "+readOnlyPath, []string{readOnlyDir}, []string{writableDir}, writableDir); err == nil {
+			t.Fatalf("should have failed to write to the read only path. Output: %s", output)
+		}
+		if _, err := os.Stat(readOnlyPath); !os.IsNotExist(err) {
+			t.Fatalf("the file %s should not have been created. Error: %v", readOnlyPath, err)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+// +build !linux
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+// getSandboxedCommand returns an error since sandboxing is only supported on linux
+func getSandboxedCommand(cmd environmenttypes.Command, readOnlyPaths, writablePaths []string, workingDir string, env []string) (*exec.Cmd, error) {
+	return nil, fmt.Errorf("sandboxed local execution is not supported on %s", runtime.GOOS)
+}
@@ -29,4 +29,6 @@ type EnvInfo struct {
 	CurrEnvOutputBasePath string
 	RelTemplatesDir       string
 	TempPath              string
+
+	Env []string // Environment variables of the transformer in the form KEY=VALUE
 }
@@ -19,6 +19,7 @@ package environment
 import (
 	"net"
 
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,3 +32,12 @@ func getIP() string {
 	localAddr := conn.LocalAddr().(*net.UDPAddr)
 	return localAddr.IP.String()
 }
+
+// getReachableQAReceiver returns the gRPC QA receiver, or nil if the environment has no network access to reach it
+func getReachableQAReceiver(envName string, grpcQAReceiver net.Addr, network environmenttypes.NetworkMode, sandboxed bool) net.Addr {
+	if grpcQAReceiver == nil || (network != environmenttypes.NetworkModeNone && !sandboxed) {
+		return grpcQAReceiver
+	}
+	logrus.Warnf("The environment %s has no network access. The transformer will not be able to ask questions using the QA gRPC server at %s", envName, grpcQAReceiver)
+	return nil
+}
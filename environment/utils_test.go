@@ -0,0 +1,49 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+import (
+	"net"
+	"testing"
+
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+)
+
+func TestGetReachableQAReceiver(t *testing.T) {
+	receiver := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	testcases := []struct {
+		name      string
+		receiver  net.Addr
+		network   environmenttypes.NetworkMode
+		sandboxed bool
+		want      net.Addr
+	}{
+		{name: "default network", receiver: receiver, want: receiver},
+		{name: "host network", receiver: receiver, network: environmenttypes.NetworkModeHost, want: receiver},
+		{name: "bridge network", receiver: receiver, network: environmenttypes.NetworkModeBridge, want: receiver},
+		{name: "no network", receiver: receiver, network: environmenttypes.NetworkModeNone, want: nil},
+		{name: "sandboxed", receiver: receiver, sandboxed: true, want: nil},
+		{name: "no receiver", network: environmenttypes.NetworkModeNone, want: nil},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := getReachableQAReceiver("test", tc.receiver, tc.network, tc.sandboxed); got != tc.want {
+				t.Fatalf("the QA receiver is incorrect. Expected: %v Actual: %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"os"
 
+	"github.com/docker/docker/pkg/reexec"
 	"github.com/konveyor/move2kube/assets"
 	"github.com/konveyor/move2kube/cmd"
 	"github.com/konveyor/move2kube/common"
@@ -27,6 +28,10 @@ import (
 )
 
 func main() {
+	// Used by the sandboxed local execution to run inside the namespaces
+	if reexec.Init() {
+		return
+	}
 	rootCmd := cmd.GetRootCmd()
 	assetsFilePermissions := map[string]int{}
 	err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions)
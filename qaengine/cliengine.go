@@ -26,6 +26,16 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// goBackOption is the option shown in select and multi-select questions to revise a previous answer
+	goBackOption = "[Go back and revise a previous answer]"
+	// goBackInput is the input that can be typed into input and multi-line input questions to revise a previous answer
+	goBackInput = ":back"
+	// confirmYesOption and confirmNoOption are the options of confirm questions which offer going back
+	confirmYesOption = "Yes"
+	confirmNoOption  = "No"
+)
+
 // CliEngine handles the CLI based qa
 type CliEngine struct {
 }
@@ -47,58 +57,131 @@ func (*CliEngine) IsInteractiveEngine() bool {
 
 // FetchAnswer fetches the answer using cli
 func (c *CliEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	for {
+		ans, goBack, err := c.fetchAnswer(prob, len(GetAnsweredProblems()) > 0)
+		if err != nil || !goBack {
+			return ans, err
+		}
+		c.reviseAnswer()
+	}
+}
+
+// fetchAnswer asks the question and returns true if the user chose to go back and revise a previous answer instead
+func (c *CliEngine) fetchAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	if err := ValidateProblem(prob); err != nil {
 		logrus.Errorf("the QA problem object is invalid. Error: %q", err)
-		return prob, err
+		return prob, false, err
 	}
 	switch prob.Type {
 	case qatypes.SelectSolutionFormType:
-		return c.fetchSelectAnswer(prob)
+		return c.fetchSelectAnswer(prob, allowGoBack)
 	case qatypes.MultiSelectSolutionFormType:
-		return c.fetchMultiSelectAnswer(prob)
+		return c.fetchMultiSelectAnswer(prob, allowGoBack)
 	case qatypes.ConfirmSolutionFormType:
-		return c.fetchConfirmAnswer(prob)
+		return c.fetchConfirmAnswer(prob, allowGoBack)
 	case qatypes.InputSolutionFormType:
-		return c.fetchInputAnswer(prob)
+		return c.fetchInputAnswer(prob, allowGoBack)
 	case qatypes.MultilineInputSolutionFormType:
-		return c.fetchMultilineInputAnswer(prob)
+		return c.fetchMultilineInputAnswer(prob, allowGoBack)
 	case qatypes.PasswordSolutionFormType:
-		return c.fetchPasswordAnswer(prob)
+		return c.fetchPasswordAnswer(prob, allowGoBack)
 	}
 	logrus.Fatalf("unknown QA problem type: %+v", prob)
-	return prob, nil
+	return prob, false, nil
+}
+
+// reviseAnswer lets the user pick one of the previous answers and answer that question again
+func (c *CliEngine) reviseAnswer() {
+	answeredProblems := GetAnsweredProblems()
+	if len(answeredProblems) == 0 {
+		logrus.Infof("There are no previous answers to revise.")
+		return
+	}
+	options := []string{}
+	for i, answeredProblem := range answeredProblems {
+		var answer interface{} = "********"
+		if answeredProblem.Type != qatypes.PasswordSolutionFormType {
+			answer = answeredProblem.Answer
+		}
+		options = append(options, fmt.Sprintf("%d. %s : %v", i+1, answeredProblem.ID, answer))
+	}
+	selected := ""
+	prompt := &survey.Select{
+		Message: "Select the answer you want to revise. Answers given after it will be asked again. Transformers which already used the previous answers are not run again.",
+		Options: options,
+		Default: options[len(options)-1],
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		logrus.Fatalf("Error while asking a question : %s", err)
+	}
+	prob := answeredProblems[0]
+	for i, option := range options {
+		if option == selected {
+			prob = answeredProblems[i]
+			break
+		}
+	}
+	if prob.Type != qatypes.PasswordSolutionFormType {
+		prob.Default = prob.Answer
+	}
+	prob.Answer = nil
+	prob, _, err := c.fetchAnswer(prob, false)
+	if err != nil {
+		logrus.Errorf("Failed to revise the answer for %s Error: %q", prob.ID, err)
+		return
+	}
+	invalidated, err := ReviseAnswer(changeSelectToInputForOther(prob))
+	if err != nil {
+		logrus.Errorf("Failed to revise the answer for %s Error: %q", prob.ID, err)
+		return
+	}
+	RefetchAnswers(invalidated)
 }
 
-func (*CliEngine) fetchSelectAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (*CliEngine) fetchSelectAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	var ans, def string
 	if prob.Default != nil {
 		def = prob.Default.(string)
 	} else {
 		def = prob.Options[0]
 	}
+	options := prob.Options
+	if allowGoBack {
+		options = append(append([]string{}, prob.Options...), goBackOption)
+	}
 	prompt := &survey.Select{
 		Message: getQAMessage(prob),
-		Options: prob.Options,
+		Options: options,
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
 		logrus.Fatalf("Error while asking a question : %s", err)
 	}
+	if allowGoBack && ans == goBackOption {
+		return prob, true, nil
+	}
 	prob.Answer = ans
-	return prob, nil
+	return prob, false, nil
 }
 
-func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	ans := []string{}
+	options := prob.Options
+	if allowGoBack {
+		options = append(append([]string{}, prob.Options...), goBackOption)
+	}
 	prompt := &survey.MultiSelect{
 		Message: getQAMessage(prob),
-		Options: prob.Options,
+		Options: options,
 		Default: prob.Default,
 	}
 	tickIcon := func(icons *survey.IconSet) { icons.MarkedOption.Text = "[\u2713]" }
 	if err := survey.AskOne(prompt, &ans, survey.WithIcons(tickIcon)); err != nil {
 		logrus.Fatalf("Error while asking a question : %s", err)
 	}
+	if allowGoBack && common.IsStringPresent(ans, goBackOption) {
+		return prob, true, nil
+	}
 	otherAnsPresent := false
 	newAns := []string{}
 	for _, a := range ans {
@@ -127,14 +210,34 @@ func (*CliEngine) fetchMultiSelectAnswer(prob qatypes.Problem) (qatypes.Problem,
 		}
 	}
 	prob.Answer = newAns
-	return prob, nil
+	return prob, false, nil
 }
 
-func (*CliEngine) fetchConfirmAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (*CliEngine) fetchConfirmAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	var ans, def bool
 	if prob.Default != nil {
 		def = prob.Default.(bool)
 	}
+	if allowGoBack {
+		// The confirm prompt only accepts yes or no, so a select prompt is used to offer going back
+		selectAns, selectDef := "", confirmNoOption
+		if def {
+			selectDef = confirmYesOption
+		}
+		prompt := &survey.Select{
+			Message: getQAMessage(prob),
+			Options: []string{confirmYesOption, confirmNoOption, goBackOption},
+			Default: selectDef,
+		}
+		if err := survey.AskOne(prompt, &selectAns); err != nil {
+			logrus.Fatalf("Error while asking a question : %s", err)
+		}
+		if selectAns == goBackOption {
+			return prob, true, nil
+		}
+		prob.Answer = selectAns == confirmYesOption
+		return prob, false, nil
+	}
 	prompt := &survey.Confirm{
 		Message: getQAMessage(prob),
 		Default: def,
@@ -143,51 +246,60 @@ func (*CliEngine) fetchConfirmAnswer(prob qatypes.Problem) (qatypes.Problem, err
 		logrus.Fatalf("Error while asking a question : %s", err)
 	}
 	prob.Answer = ans
-	return prob, nil
+	return prob, false, nil
 }
 
-func (*CliEngine) fetchInputAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (*CliEngine) fetchInputAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	var ans, def string
 	if prob.Default != nil {
 		def = prob.Default.(string)
 	}
 	prompt := &survey.Input{
-		Message: getQAMessage(prob),
+		Message: getQAMessageWithGoBack(prob, allowGoBack),
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
 		logrus.Fatalf("Error while asking a question : %s", err)
 	}
+	if allowGoBack && strings.TrimSpace(ans) == goBackInput {
+		return prob, true, nil
+	}
 	prob.Answer = ans
-	return prob, nil
+	return prob, false, nil
 }
 
-func (*CliEngine) fetchMultilineInputAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (*CliEngine) fetchMultilineInputAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	var ans, def string
 	if prob.Default != nil {
 		def = prob.Default.(string)
 	}
 	prompt := &survey.Multiline{
-		Message: getQAMessage(prob),
+		Message: getQAMessageWithGoBack(prob, allowGoBack),
 		Default: def,
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
 		logrus.Fatalf("Error while asking a question : %s", err)
 	}
+	if allowGoBack && strings.TrimSpace(ans) == goBackInput {
+		return prob, true, nil
+	}
 	prob.Answer = ans
-	return prob, nil
+	return prob, false, nil
 }
 
-func (*CliEngine) fetchPasswordAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+func (*CliEngine) fetchPasswordAnswer(prob qatypes.Problem, allowGoBack bool) (qatypes.Problem, bool, error) {
 	var ans string
 	prompt := &survey.Password{
-		Message: getQAMessage(prob),
+		Message: getQAMessageWithGoBack(prob, allowGoBack),
 	}
 	if err := survey.AskOne(prompt, &ans); err != nil {
 		logrus.Fatalf("Error while asking a question : %s", err)
 	}
+	if allowGoBack && ans == goBackInput {
+		return prob, true, nil
+	}
 	prob.Answer = ans
-	return prob, nil
+	return prob, false, nil
 }
 
 func getQAMessage(prob qatypes.Problem) string {
@@ -199,3 +311,10 @@ func getQAMessage(prob qatypes.Problem) string {
 	}
 	return fmt.Sprintf("%s\nID: %s\nHints:\n[%s]\n", prob.Desc, prob.ID, strings.Join(prob.Hints, ", "))
 }
+
+func getQAMessageWithGoBack(prob qatypes.Problem, allowGoBack bool) string {
+	if !allowGoBack {
+		return getQAMessage(prob)
+	}
+	return getQAMessage(prob) + fmt.Sprintf("(Enter %s to revise a previous answer)\n", goBackInput)
+}
@@ -18,7 +18,10 @@ package qaengine
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/konveyor/move2kube/common"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
@@ -36,15 +39,21 @@ var (
 	engines       []Engine
 	writeStores   []qatypes.Store
 	defaultEngine = NewDefaultEngine()
+	audit         *qatypes.Audit
+	// answeredProblems stores the problems answered interactively in this session, in the order they were answered
+	answeredProblems      []qatypes.Problem
+	answeredProblemsMutex sync.Mutex
 )
 
 // StartEngine starts the QA Engines
-func StartEngine(qaskip bool, qaport int, qadisablecli bool) {
+func StartEngine(qaskip bool, qaport int, qadisablecli bool, qagrpc bool, qadeadline time.Duration) {
 	var e Engine
 	if qaskip {
 		e = NewDefaultEngine()
 	} else if !qadisablecli {
 		e = NewCliEngine()
+	} else if qagrpc {
+		e = NewGRPCEngine(qaport, qadeadline)
 	} else {
 		e = NewHTTPRESTEngine(qaport)
 	}
@@ -69,6 +78,16 @@ func AddEngineHighestPriority(e Engine) error {
 	return nil
 }
 
+// RemoveEngine removes the engine from the list
+func RemoveEngine(e Engine) {
+	for i, engine := range engines {
+		if engine == e {
+			engines = append(engines[:i], engines[i+1:]...)
+			return
+		}
+	}
+}
+
 // AddCaches adds cache responders.
 // Later cache files override earlier cache files.
 // [base.yaml, project.yaml, service.yaml]
@@ -92,14 +111,11 @@ func SetupWriteCacheFile(writeCachePath string, persistPasswords bool) {
 }
 
 // SetupConfigFile adds config responders - should be called only once
-func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets []string, persistPasswords bool) {
-	presetPaths := []string{}
-	for _, preset := range presets {
-		presetPath := filepath.Join(common.AssetsPath, "built-in", "presets", preset+".yaml")
-		presetPaths = append(presetPaths, presetPath)
+func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets []string, profile, customizationsPath string, persistPasswords bool) {
+	writeConfig, err := newConfig(writeConfigFile, configStrings, configFiles, presets, profile, customizationsPath, persistPasswords)
+	if err != nil {
+		logrus.Fatalf("Failed to set up the config. Error: %q", err)
 	}
-	configFiles = append(presetPaths, configFiles...)
-	writeConfig := qatypes.NewConfig(writeConfigFile, configStrings, configFiles, persistPasswords)
 	if writeConfigFile != "" {
 		writeStores = append(writeStores, writeConfig)
 	}
@@ -109,17 +125,89 @@ func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets
 	}
 }
 
+// GetEffectiveConfig returns the config that results from merging the presets, the config profile
+// along with all the profiles it extends, the config files and the config strings
+func GetEffectiveConfig(configStrings, configFiles, presets []string, profile, customizationsPath string) (map[string]interface{}, error) {
+	config, err := newConfig("", configStrings, configFiles, presets, profile, customizationsPath, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Load(); err != nil {
+		return nil, err
+	}
+	return config.GetMergedConfig(), nil
+}
+
+// newConfig creates a config store. Config files that contain config profiles are only used to look up the profile.
+func newConfig(writeConfigFile string, configStrings, configFiles, presets []string, profile, customizationsPath string, persistPasswords bool) (*qatypes.Config, error) {
+	presetPaths := []string{}
+	for _, preset := range presets {
+		presetPaths = append(presetPaths, getPresetPath(preset, customizationsPath))
+	}
+	profilePaths := []string{}
+	if customizationsPath != "" {
+		profilePaths = append(profilePaths, customizationsPath)
+	}
+	plainConfigFiles := []string{}
+	for _, configFile := range configFiles {
+		if qatypes.IsConfigProfileFile(configFile) {
+			if profile == "" {
+				return nil, fmt.Errorf("the config file %s contains config profiles, but no profile was selected. Use the --profile flag to select one of them", configFile)
+			}
+			profilePaths = append(profilePaths, configFile)
+			continue
+		}
+		plainConfigFiles = append(plainConfigFiles, configFile)
+	}
+	profiles := []qatypes.ConfigProfile{}
+	if profile != "" {
+		allProfiles, err := qatypes.ReadConfigProfiles(profilePaths...)
+		if err != nil {
+			return nil, err
+		}
+		profiles, err = qatypes.ResolveConfigProfile(profile, allProfiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return qatypes.NewConfig(writeConfigFile, configStrings, presetPaths, profiles, plainConfigFiles, persistPasswords), nil
+}
+
+// getPresetPath returns the path to the preset, preferring the presets in the customizations directory over the built-in ones
+func getPresetPath(preset, customizationsPath string) string {
+	if customizationsPath != "" {
+		customPresetPath := filepath.Join(customizationsPath, "presets", preset+".yaml")
+		if _, err := os.Stat(customPresetPath); err == nil {
+			return customPresetPath
+		}
+	}
+	return filepath.Join(common.AssetsPath, "built-in", "presets", preset+".yaml")
+}
+
+// SetupAuditFile records the source of every answer in an audit file
+func SetupAuditFile(auditPath string) {
+	audit = qatypes.NewAudit(auditPath)
+	audit.Write()
+}
+
 // FetchAnswer fetches the answer for the question
 func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	logrus.Debugf("Fetching answer for problem:\n%v", prob)
 	if prob.Answer != nil {
 		logrus.Debugf("Problem already solved.")
+		addAuditDecision(prob, qatypes.PredeterminedAnswerSource, "")
 		return prob, nil
 	}
 	var err error
+	answeredInteractively := false
+	var answeringEngine Engine
 	for _, e := range engines {
 		if prob.Desc == "" && e.IsInteractiveEngine() {
-			return defaultEngine.FetchAnswer(prob)
+			prob, err = defaultEngine.FetchAnswer(prob)
+			if err == nil {
+				addAuditDecision(prob, qatypes.DefaultAnswerSource, "")
+			}
+			return prob, err
 		}
 		prob, err = e.FetchAnswer(prob)
 		if err != nil {
@@ -128,6 +216,8 @@ func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 		}
 		if prob.Answer != nil {
 			prob = changeSelectToInputForOther(prob)
+			answeredInteractively = e.IsInteractiveEngine()
+			answeringEngine = e
 			break
 		}
 	}
@@ -150,13 +240,113 @@ func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 				prob = changeSelectToInputForOther(prob)
 			}
 		}
+		answeredInteractively = true
+		answeringEngine = lastEngine
+	}
+	answeredProblemsMutex.Lock()
+	defer answeredProblemsMutex.Unlock()
+	if answeredInteractively {
+		answeredProblems = append(answeredProblems, prob)
 	}
+	source, sourceDetail := getAnswerSource(answeringEngine, prob)
+	addAuditDecision(prob, source, sourceDetail)
 	for _, writeStore := range writeStores {
 		writeStore.AddSolution(prob)
 	}
 	return prob, err
 }
 
+// getAnswerSource returns where the answer given by the engine came from
+func getAnswerSource(e Engine, prob qatypes.Problem) (qatypes.AnswerSource, string) {
+	switch engine := e.(type) {
+	case *StoreEngine:
+		return engine.store.GetSolutionSource(prob)
+	case *DefaultEngine:
+		return qatypes.DefaultAnswerSource, ""
+	}
+	if e != nil && e.IsInteractiveEngine() {
+		return qatypes.InteractiveAnswerSource, fmt.Sprintf("%T", e)
+	}
+	return qatypes.DefaultAnswerSource, fmt.Sprintf("%T", e)
+}
+
+func addAuditDecision(prob qatypes.Problem, source qatypes.AnswerSource, sourceDetail string) {
+	if audit == nil {
+		return
+	}
+	if source == qatypes.DefaultAnswerSource {
+		logrus.Debugf("Using the default answer for the problem %s", prob.ID)
+	}
+	if err := audit.AddDecision(prob, source, sourceDetail); err != nil {
+		logrus.Debugf("Failed to record the answer for %s in the QA audit. Error: %q", prob.ID, err)
+	}
+}
+
+// GetAnsweredProblems returns the problems answered interactively in this session, in the order they were answered
+func GetAnsweredProblems() []qatypes.Problem {
+	answeredProblemsMutex.Lock()
+	defer answeredProblemsMutex.Unlock()
+	return append([]qatypes.Problem{}, answeredProblems...)
+}
+
+// ReviseAnswer replaces a previously given interactive answer with the answer in the given problem.
+// All the problems answered interactively after it are considered dependent on it.
+// They are removed from the write stores and returned. ReviseAnswer does not ask them again,
+// since the caller may be answering another problem at the time. The CLI engine asks them again
+// right away using RefetchAnswers. The REST engine returns them to the client, and they are asked
+// again only if a transformer needs them again, otherwise the answers used so far in the run stay in effect.
+// Revising an answer does not change what the transformers which already fetched the previous answers did,
+// it only changes the answer stored in the config and the answer returned when it is fetched again.
+func ReviseAnswer(prob qatypes.Problem) (invalidated []qatypes.Problem, err error) {
+	if prob.Answer == nil {
+		return nil, fmt.Errorf("the revised answer for the problem %s is empty", prob.ID)
+	}
+	answeredProblemsMutex.Lock()
+	defer answeredProblemsMutex.Unlock()
+	idx := -1
+	for i, answeredProblem := range answeredProblems {
+		if answeredProblem.ID == prob.ID {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil, fmt.Errorf("the problem %s was not answered interactively in this session", prob.ID)
+	}
+	invalidated = append(invalidated, answeredProblems[idx+1:]...)
+	answeredProblems = append(answeredProblems[:idx], prob)
+	for _, writeStore := range writeStores {
+		for _, invalidatedProblem := range invalidated {
+			if err := writeStore.RemoveSolution(invalidatedProblem); err != nil {
+				logrus.Debugf("Failed to remove the answer for %s from the store %T Error: %q", invalidatedProblem.ID, writeStore, err)
+			}
+		}
+		writeStore.AddSolution(prob)
+	}
+	addAuditDecision(prob, qatypes.InteractiveAnswerSource, "revised")
+	logrus.Infof("Revised the answer for %s . %d dependent answers were invalidated.", prob.ID, len(invalidated))
+	return invalidated, nil
+}
+
+// RefetchAnswers asks the invalidated problems again, using their previous answers as the defaults.
+// It returns the newly answered problems. The new answers are only used by the transformers which fetch them later.
+func RefetchAnswers(invalidated []qatypes.Problem) []qatypes.Problem {
+	refetched := []qatypes.Problem{}
+	for _, prob := range invalidated {
+		if prob.Type != qatypes.PasswordSolutionFormType {
+			prob.Default = prob.Answer
+		}
+		prob.Answer = nil
+		prob, err := FetchAnswer(prob)
+		if err != nil {
+			logrus.Errorf("Unable to fetch the answer for %s again : %s", prob.ID, err)
+			continue
+		}
+		refetched = append(refetched, prob)
+	}
+	return refetched
+}
+
 // WriteStoresToDisk forces all the stores to write their contents out to disk
 func WriteStoresToDisk() error {
 	var err error
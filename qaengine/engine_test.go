@@ -17,12 +17,24 @@
 package qaengine
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 )
 
+type testInteractiveEngine struct {
+	DefaultEngine
+}
+
+func (*testInteractiveEngine) IsInteractiveEngine() bool {
+	return true
+}
+
 func TestEngine(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
 
@@ -45,4 +57,82 @@ func TestEngine(t *testing.T) {
 
 	})
 
+	t.Run("2. test ReviseAnswer", func(t *testing.T) {
+
+		engines = []Engine{}
+		writeStores = []qatypes.Store{}
+		answeredProblems = nil
+		AddEngine(&testInteractiveEngine{})
+		config := qatypes.NewConfig(filepath.Join(t.TempDir(), common.ConfigFile), nil, nil, nil, nil, false)
+		if err := config.Load(); err != nil {
+			t.Fatalf("Failed to load the config. Error: %q", err)
+		}
+		writeStores = append(writeStores, config)
+
+		keys := []string{}
+		for _, name := range []string{"first", "second", "third"} {
+			key := common.BaseKey + common.Delim + name
+			keys = append(keys, key)
+			FetchStringAnswer(key, "Test description for "+name, nil, name)
+		}
+		prob, err := qatypes.NewInputProblem(keys[0], "Test description for first", nil, "first")
+		if err != nil {
+			t.Fatalf("Failed to create the problem. Error: %q", err)
+		}
+		prob.Answer = "revised"
+		invalidated, err := ReviseAnswer(prob)
+		if err != nil {
+			t.Fatalf("Failed to revise the answer. Error: %q", err)
+		}
+		if len(invalidated) != 2 || invalidated[0].ID != keys[1] || invalidated[1].ID != keys[2] {
+			t.Fatalf("Expected the answers after the revised one to be invalidated. Actual: %+v", invalidated)
+		}
+		if value, ok := config.Get(keys[0]); !ok || value != "revised" {
+			t.Fatalf("Expected the revised answer to be stored in the config. Actual: %+v", value)
+		}
+		for _, key := range keys[1:] {
+			if _, ok := config.Get(key); ok {
+				t.Fatalf("Expected the answer for %s to be removed from the config", key)
+			}
+		}
+		if answered := GetAnsweredProblems(); len(answered) != 1 || answered[0].Answer != "revised" {
+			t.Fatalf("Expected only the revised problem to remain answered. Actual: %+v", answered)
+		}
+		if _, err := ReviseAnswer(qatypes.Problem{ID: keys[1], Answer: "new"}); err == nil {
+			t.Fatalf("Expected an error when revising an invalidated problem")
+		}
+		refetched := RefetchAnswers(invalidated)
+		if len(refetched) != 2 || refetched[0].Answer != "second" || refetched[1].Answer != "third" {
+			t.Fatalf("Expected the invalidated problems to be answered again with their previous answers as the defaults. Actual: %+v", refetched)
+		}
+		for i, key := range keys[1:] {
+			if value, ok := config.Get(key); !ok || value != refetched[i].Answer {
+				t.Fatalf("Expected the answer for %s to be stored in the config again. Actual: %+v", key, value)
+			}
+		}
+		if answered := GetAnsweredProblems(); len(answered) != 3 || answered[2].ID != keys[2] {
+			t.Fatalf("Expected the answered problems to be the revised problem followed by the refetched problems. Actual: %+v", answered)
+		}
+		writeStores = []qatypes.Store{}
+		answeredProblems = nil
+
+	})
+
+
+	t.Run("3. test config profile files without a selected profile", func(t *testing.T) {
+
+		profilePath := filepath.Join(t.TempDir(), "profile.yaml")
+		profileYaml := "apiVersion: move2kube.konveyor.io/v1alpha1\nkind: ConfigProfile\nmetadata:\n  name: team\nspec:\n  config:\n    move2kube:\n      foo: bar\n"
+		if err := os.WriteFile(profilePath, []byte(profileYaml), common.DefaultFilePermission); err != nil {
+			t.Fatalf("Failed to write the config profile. Error: %q", err)
+		}
+		if _, err := newConfig("", nil, []string{profilePath}, nil, "", "", false); err == nil {
+			t.Fatalf("Expected an error when a config profile file is given without selecting a profile")
+		}
+		if _, err := newConfig("", nil, []string{profilePath}, nil, "team", "", false); err != nil {
+			t.Fatalf("Failed to create the config with the selected profile. Error: %q", err)
+		}
+
+	})
+
 }
@@ -0,0 +1,228 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/qagrpc"
+	"github.com/phayes/freeport"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	grpcKeepAliveTime    = 30 * time.Second
+	grpcKeepAliveTimeout = 10 * time.Second
+)
+
+// GRPCEngine handles qa by streaming the problems to a remote client over GRPC
+type GRPCEngine struct {
+	qagrpc.UnimplementedQAEngineStreamServer
+	port     int
+	deadline time.Duration
+	// mutex protects currentProblem and activeStream
+	mutex          sync.Mutex
+	currentProblem *qagrpc.PendingProblem
+	activeStream   *grpcEngineStream
+	solutionChan   chan *qagrpc.Solution
+}
+
+// grpcEngineStream is a stream opened by a remote client
+type grpcEngineStream struct {
+	problemChan chan *qagrpc.PendingProblem
+	cancel      context.CancelFunc
+}
+
+// NewGRPCEngine creates a new instance of the GRPC engine.
+// If the deadline is non zero and a problem is not answered before it, the default answer is used.
+func NewGRPCEngine(qaport int, deadline time.Duration) Engine {
+	return &GRPCEngine{
+		port:         qaport,
+		deadline:     deadline,
+		solutionChan: make(chan *qagrpc.Solution),
+	}
+}
+
+// StartEngine starts the GRPC server that remote clients connect to
+func (g *GRPCEngine) StartEngine() error {
+	if g.port == 0 {
+		var err error
+		g.port, err = freeport.GetFreePort()
+		if err != nil {
+			return fmt.Errorf("unable to find a free port : %s", err)
+		}
+	}
+	qaportstr := cast.ToString(g.port)
+	listener, err := net.Listen("tcp", ":"+qaportstr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on port %d : %s", g.port, err)
+	}
+	// keepalive pings make sure a client that went away is detected, so that it can reconnect
+	s := grpc.NewServer(grpc.KeepaliveParams(keepalive.ServerParameters{Time: grpcKeepAliveTime, Timeout: grpcKeepAliveTimeout}))
+	qagrpc.RegisterQAEngineStreamServer(s, g)
+	reflection.Register(s)
+	go func(listener net.Listener) {
+		if err := s.Serve(listener); err != nil {
+			logrus.Fatalf("Unable to start qa server : %s", err)
+		}
+	}(listener)
+	logrus.Info("Started QA GRPC engine on: localhost:" + qaportstr)
+	return nil
+}
+
+// IsInteractiveEngine returns true if the engine interacts with the user
+func (*GRPCEngine) IsInteractiveEngine() bool {
+	return true
+}
+
+// FetchAnswer sends the problem to the connected client and waits for the solution
+func (g *GRPCEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	if err := ValidateProblem(prob); err != nil {
+		logrus.Errorf("the QA problem object is invalid. Error: %q", err)
+		return prob, err
+	}
+	if prob.Answer != nil {
+		return prob, nil
+	}
+	defaults, err := qatypes.InterfaceToArray(prob.Default, prob.Type)
+	if err != nil {
+		logrus.Debugf("Unable to convert the default of the problem %s : %s", prob.ID, err)
+	}
+	pendingProblem := &qagrpc.PendingProblem{
+		Problem: &qagrpc.Problem{
+			Id:          prob.ID,
+			Type:        string(prob.Type),
+			Description: prob.Desc,
+			Hints:       prob.Hints,
+			Options:     prob.Options,
+			Default:     defaults,
+		},
+	}
+	var timeout <-chan time.Time
+	if g.deadline > 0 {
+		timer := time.NewTimer(g.deadline)
+		defer timer.Stop()
+		timeout = timer.C
+		pendingProblem.Deadline = time.Now().Add(g.deadline).Unix()
+	}
+	logrus.Debugf("Passing problem to GRPC QA Engine ID: %s, desc: %s", prob.ID, prob.Desc)
+	g.setCurrentProblem(pendingProblem)
+	defer g.setCurrentProblem(nil)
+	for {
+		select {
+		case solution := <-g.solutionChan:
+			if solution.GetId() != prob.ID {
+				logrus.Debugf("Ignoring the solution for %s since the current problem is %s", solution.GetId(), prob.ID)
+				continue
+			}
+			ans, err := qatypes.ArrayToInterface(solution.GetAnswer(), prob.Type)
+			if err == nil {
+				err = prob.SetAnswer(ans)
+			}
+			if err != nil {
+				logrus.Errorf("The solution for %s was rejected. Error: %q", prob.ID, err)
+				pendingProblem = &qagrpc.PendingProblem{Problem: pendingProblem.Problem, Deadline: pendingProblem.Deadline, Error: err.Error()}
+				g.setCurrentProblem(pendingProblem)
+				continue
+			}
+			return prob, nil
+		case <-timeout:
+			logrus.Warnf("The deadline for answering %s was exceeded. Using the default answer.", prob.ID)
+			return defaultEngine.FetchAnswer(prob)
+		}
+	}
+}
+
+// StreamProblems sends the pending problems to the client and receives the solutions.
+// Only the most recently connected client is served, earlier streams are closed.
+func (g *GRPCEngine) StreamProblems(stream qagrpc.QAEngineStream_StreamProblemsServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	s := &grpcEngineStream{problemChan: make(chan *qagrpc.PendingProblem, 1), cancel: cancel}
+	g.mutex.Lock()
+	if g.activeStream != nil {
+		logrus.Infof("A new GRPC QA client connected. Closing the previous stream.")
+		g.activeStream.cancel()
+	}
+	g.activeStream = s
+	if g.currentProblem != nil {
+		s.problemChan <- g.currentProblem
+	}
+	g.mutex.Unlock()
+	defer func() {
+		g.mutex.Lock()
+		if g.activeStream == s {
+			g.activeStream = nil
+		}
+		g.mutex.Unlock()
+	}()
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			solution, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			logrus.Debugf("QA Engine receives solution over GRPC: %+v", solution)
+			select {
+			case g.solutionChan <- solution:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case pendingProblem := <-s.problemChan:
+			if err := stream.Send(pendingProblem); err != nil {
+				logrus.Errorf("Failed to send the problem %s to the GRPC QA client. Error: %q", pendingProblem.GetProblem().GetId(), err)
+				return err
+			}
+		case err := <-recvErr:
+			logrus.Debugf("The GRPC QA client stream ended : %s", err)
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// setCurrentProblem sets the problem waiting for a solution and sends it to the connected client
+func (g *GRPCEngine) setCurrentProblem(pendingProblem *qagrpc.PendingProblem) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.currentProblem = pendingProblem
+	if pendingProblem == nil || g.activeStream == nil {
+		return
+	}
+	// replace any problem that was not sent yet
+	select {
+	case <-g.activeStream.problemChan:
+	default:
+	}
+	g.activeStream.problemChan <- pendingProblem
+}
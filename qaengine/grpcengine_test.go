@@ -0,0 +1,113 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/qagrpc"
+	"github.com/phayes/freeport"
+	"github.com/spf13/cast"
+	"google.golang.org/grpc"
+)
+
+func TestGRPCEngine(t *testing.T) {
+	port, err := freeport.GetFreePort()
+	if err != nil {
+		t.Fatalf("Failed to get a free port. Error: %q", err)
+	}
+	e := NewGRPCEngine(port, 0)
+	if err := e.StartEngine(); err != nil {
+		t.Fatalf("Failed to start the GRPC engine. Error: %q", err)
+	}
+	connect := func(ctx context.Context) qagrpc.QAEngineStream_StreamProblemsClient {
+		conn, err := grpc.DialContext(ctx, "localhost:"+cast.ToString(port), grpc.WithInsecure(), grpc.WithBlock())
+		if err != nil {
+			t.Fatalf("Failed to connect to the GRPC engine. Error: %q", err)
+		}
+		stream, err := qagrpc.NewQAEngineStreamClient(conn).StreamProblems(ctx)
+		if err != nil {
+			t.Fatalf("Failed to open the stream. Error: %q", err)
+		}
+		return stream
+	}
+
+	t.Run("answer after the client reconnects", func(t *testing.T) {
+		key := common.BaseKey + common.Delim + "input"
+		prob, err := qatypes.NewInputProblem(key, "Test description", nil, "default")
+		if err != nil {
+			t.Fatalf("Failed to create the problem. Error: %q", err)
+		}
+		type result struct {
+			prob qatypes.Problem
+			err  error
+		}
+		results := make(chan result)
+		go func() {
+			prob, err := e.FetchAnswer(prob)
+			results <- result{prob: prob, err: err}
+		}()
+
+		ctx1, cancel1 := context.WithTimeout(context.Background(), 10*time.Second)
+		stream := connect(ctx1)
+		pendingProblem, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive the problem. Error: %q", err)
+		}
+		if pendingProblem.GetProblem().GetId() != key {
+			t.Fatalf("Expected the problem %s . Actual: %+v", key, pendingProblem)
+		}
+		// disconnect without answering
+		cancel1()
+
+		ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel2()
+		stream = connect(ctx2)
+		pendingProblem, err = stream.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive the problem after reconnecting. Error: %q", err)
+		}
+		if pendingProblem.GetProblem().GetId() != key {
+			t.Fatalf("Expected the problem %s to be sent again. Actual: %+v", key, pendingProblem)
+		}
+		if err := stream.Send(&qagrpc.Solution{Id: key, Answer: []string{"answer"}}); err != nil {
+			t.Fatalf("Failed to send the solution. Error: %q", err)
+		}
+		res := <-results
+		if res.err != nil || res.prob.Answer != "answer" {
+			t.Fatalf("Expected the answer to be 'answer'. Actual: %+v Error: %v", res.prob.Answer, res.err)
+		}
+	})
+
+	t.Run("use the default answer when the deadline is exceeded", func(t *testing.T) {
+		g := e.(*GRPCEngine)
+		g.deadline = 100 * time.Millisecond
+		defer func() { g.deadline = 0 }()
+		prob, err := qatypes.NewConfirmProblem(common.BaseKey+common.Delim+"confirm", "Test description", nil, true)
+		if err != nil {
+			t.Fatalf("Failed to create the problem. Error: %q", err)
+		}
+		prob, err = e.FetchAnswer(prob)
+		if err != nil || prob.Answer != true {
+			t.Fatalf("Expected the default answer to be used. Actual: %+v Error: %v", prob.Answer, err)
+		}
+	})
+}
@@ -42,9 +42,11 @@ type HTTPRESTEngine struct {
 }
 
 const (
-	problemsURLPrefix        = "/problems"
-	currentProblemURLPrefix  = problemsURLPrefix + "/current"
-	currentSolutionURLPrefix = currentProblemURLPrefix + "/solution"
+	problemsURLPrefix         = "/problems"
+	currentProblemURLPrefix   = problemsURLPrefix + "/current"
+	currentSolutionURLPrefix  = currentProblemURLPrefix + "/solution"
+	answeredProblemsURLPrefix = problemsURLPrefix + "/answered"
+	revisedSolutionURLPrefix  = answeredProblemsURLPrefix + "/solution"
 )
 
 // NewHTTPRESTEngine creates a new instance of Http REST engine
@@ -70,6 +72,8 @@ func (h *HTTPRESTEngine) StartEngine() error {
 	r := mux.NewRouter()
 	r.HandleFunc(currentProblemURLPrefix, h.problemHandler).Methods("GET")
 	r.HandleFunc(currentSolutionURLPrefix, h.solutionHandler).Methods("POST")
+	r.HandleFunc(answeredProblemsURLPrefix, h.answeredProblemsHandler).Methods("GET")
+	r.HandleFunc(revisedSolutionURLPrefix, h.revisedSolutionHandler).Methods("POST")
 
 	http.Handle("/", r)
 	qaportstr := cast.ToString(h.port)
@@ -189,3 +193,62 @@ func (h *HTTPRESTEngine) solutionHandler(w http.ResponseWriter, r *http.Request)
 	}
 	h.answerChan <- h.currentProblem
 }
+
+// answeredProblemsHandler returns the problems answered so far, in the order they were answered
+func (h *HTTPRESTEngine) answeredProblemsHandler(w http.ResponseWriter, r *http.Request) {
+	answeredProblems := GetAnsweredProblems()
+	for i, answeredProblem := range answeredProblems {
+		if answeredProblem.Type == qatypes.PasswordSolutionFormType {
+			answeredProblems[i].Answer = nil
+		}
+	}
+	_ = json.NewEncoder(w).Encode(answeredProblems)
+}
+
+// revisedSolutionHandler accepts a new solution for a problem that was answered previously.
+// It responds with the dependent problems that got invalidated. They are not asked again right away,
+// since a transformer may be waiting for the answer to the current problem. They are asked again when a transformer needs them.
+func (h *HTTPRESTEngine) revisedSolutionHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		errstr := fmt.Sprintf("Error in reading posted solution: %s", err)
+		http.Error(w, errstr, http.StatusInternalServerError)
+		logrus.Errorf(errstr)
+		return
+	}
+	var revisedProb qatypes.Problem
+	if err := json.Unmarshal(body, &revisedProb); err != nil {
+		errstr := fmt.Sprintf("Error in un-marshalling solution in QA engine: %s", err)
+		http.Error(w, errstr, http.StatusInternalServerError)
+		logrus.Errorf(errstr)
+		return
+	}
+	logrus.Debugf("QA Engine receives revised solution: %+v", revisedProb)
+	var prob *qatypes.Problem
+	for _, answeredProblem := range GetAnsweredProblems() {
+		if answeredProblem.ID == revisedProb.ID {
+			prob = &answeredProblem
+			break
+		}
+	}
+	if prob == nil {
+		errstr := fmt.Sprintf("the problem %s has not been answered yet", revisedProb.ID)
+		http.Error(w, errstr, http.StatusNotFound)
+		logrus.Errorf(errstr)
+		return
+	}
+	if err := prob.SetAnswer(revisedProb.Answer); err != nil {
+		errstr := fmt.Sprintf("failed to set the solution as the answer. Error: %q", err)
+		http.Error(w, errstr, http.StatusNotAcceptable)
+		logrus.Errorf(errstr)
+		return
+	}
+	invalidated, err := ReviseAnswer(*prob)
+	if err != nil {
+		errstr := fmt.Sprintf("failed to revise the answer. Error: %q", err)
+		http.Error(w, errstr, http.StatusNotAcceptable)
+		logrus.Errorf(errstr)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(invalidated)
+}
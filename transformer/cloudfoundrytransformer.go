@@ -35,6 +35,7 @@ import (
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
 	core "k8s.io/kubernetes/pkg/apis/core"
 	"k8s.io/kubernetes/pkg/apis/networking"
 )
@@ -189,7 +190,7 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 			if serviceContainer.Image == "" {
 				serviceContainer.Image = sConfig.ServiceName
 			}
-			//TODO: Add support for services, health check, memory
+			//TODO: Add support for services
 			if application.Instances.IsSet {
 				serviceConfig.Replicas = application.Instances.Value
 			} else if cfinstanceapp.Application.Instances != 0 {
@@ -210,8 +211,25 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 				servicePort := podPort
 				serviceConfig.AddPortForwarding(servicePort, podPort, "")
 			}
+			serviceContainer.Resources = getResources(application, cfinstanceapp)
+			serviceConfig.HealthCheck = getHealthCheck(application, cfinstanceapp)
 			serviceConfig.Containers = []core.Container{serviceContainer}
 			ir.Services[sConfig.ServiceName] = serviceConfig
+			for _, task := range cfinstanceapp.Tasks {
+				for i, schedule := range task.Schedules {
+					taskServiceName := common.MakeStringDNSLabelNameCompliant(sConfig.ServiceName + "-" + task.Name)
+					if len(task.Schedules) > 1 {
+						taskServiceName = fmt.Sprintf("%s-%d", taskServiceName, i+1)
+					}
+					schedule = qaengine.FetchStringAnswer(common.ConfigServicesKey+common.Delim+`"`+taskServiceName+`"`+common.Delim+common.ConfigScheduleForServiceKeySegment, fmt.Sprintf("Enter the schedule for running the task %s of the app %s :", task.Name, sConfig.ServiceName), []string{"The schedule is in cron format. Leave it empty to not create a CronJob for the task"}, schedule)
+					if strings.TrimSpace(schedule) == "" {
+						continue
+					}
+					taskService := newScheduledService(serviceConfig, 0, taskServiceName, schedule, []string{defaultCronShell, "-c", task.Command}, nil)
+					setScheduleOptions(&taskService)
+					ir.Services[taskServiceName] = taskService
+				}
+			}
 		}
 		if len(cConfig) != 0 {
 			containerizationOption := qaengine.FetchSelectAnswer(common.ConfigServicesKey+common.Delim+sConfig.ServiceName+common.Delim+common.ConfigContainerizationOptionServiceKeySegment, fmt.Sprintf("Select the transformer to use for containerization %s :", sConfig.ServiceName), []string{fmt.Sprintf("Select containerization option to use %s", sConfig.ServiceName)}, cConfig[0], cConfig)
@@ -242,6 +260,63 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 	return nil, artifactsCreated, nil
 }
 
+// getHealthCheck returns the health check of the app from the manifest or the running instance
+func getHealthCheck(application manifest.Application, cfinstanceapp collecttypes.CfApp) *irtypes.HealthCheck {
+	healthCheckType := application.HealthCheckType
+	endpoint := application.HealthCheckHTTPEndpoint
+	timeout := int32(application.HealthCheckTimeout)
+	if healthCheckType == "" {
+		healthCheckType = cfinstanceapp.Application.HealthCheckType
+		endpoint = cfinstanceapp.Application.HealthCheckHttpEndpoint
+	}
+	if timeout == 0 {
+		timeout = int32(cfinstanceapp.Application.HealthCheckTimeout)
+	}
+	if healthCheckType == "" && timeout == 0 {
+		return nil
+	}
+	healthCheck := irtypes.HealthCheck{StartupTimeout: timeout}
+	switch healthCheckType {
+	case "http":
+		healthCheck.Type = irtypes.HTTPHealthCheckType
+		healthCheck.Path = endpoint
+		if healthCheck.Path == "" {
+			healthCheck.Path = "/"
+		}
+	case "process", "none":
+		healthCheck.Type = irtypes.ProcessHealthCheckType
+	default:
+		healthCheck.Type = irtypes.PortHealthCheckType
+	}
+	return &healthCheck
+}
+
+// getResources returns the memory and the ephemeral storage of the app from the manifest or the running instance
+func getResources(application manifest.Application, cfinstanceapp collecttypes.CfApp) core.ResourceRequirements {
+	resources := core.ResourceRequirements{}
+	memory := int64(cfinstanceapp.Application.Memory)
+	if application.Memory.IsSet {
+		memory = int64(application.Memory.Value)
+	}
+	diskQuota := int64(cfinstanceapp.Application.DiskQuota)
+	if application.DiskQuota.IsSet {
+		diskQuota = int64(application.DiskQuota.Value)
+	}
+	if memory > 0 {
+		// Cloud Foundry reserves the memory of the app and kills it when it uses more
+		memoryQuantity := *resource.NewQuantity(memory*1024*1024, resource.BinarySI)
+		resources.Requests = core.ResourceList{core.ResourceMemory: memoryQuantity}
+		resources.Limits = core.ResourceList{core.ResourceMemory: memoryQuantity}
+	}
+	if diskQuota > 0 {
+		if resources.Limits == nil {
+			resources.Limits = core.ResourceList{}
+		}
+		resources.Limits[core.ResourceEphemeralStorage] = *resource.NewQuantity(diskQuota*1024*1024, resource.BinarySI)
+	}
+	return resources
+}
+
 // prioritizeAndAddEnvironmentVariables adds relevant environment variables relevant to the application deployment
 func (t *CloudFoundry) prioritizeAndAddEnvironmentVariables(cfApp collecttypes.CfApp,
 	manifestEnvMap map[string]string, secretName string, serviceName string) ([]core.EnvVar, map[string][]byte) {
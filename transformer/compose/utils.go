@@ -106,3 +106,19 @@ func getHash(data []byte) uint64 {
 	hasher.Write(data)
 	return hasher.Sum64()
 }
+
+// getLinks returns the names of the services which are linked to or depended on. Links have the form service:alias
+func getLinks(links []string, dependsOn []string) []string {
+	names := []string{}
+	for _, link := range append(append([]string{}, links...), dependsOn...) {
+		name := strings.TrimSpace(strings.SplitN(link, ":", 2)[0])
+		if name == "" {
+			continue
+		}
+		name = common.NormalizeForMetadataName(name)
+		if !common.IsStringPresent(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
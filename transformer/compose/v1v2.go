@@ -259,6 +259,8 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 			}
 		}
 
+		serviceConfig.Links = getLinks(composeServiceConfig.Links, composeServiceConfig.DependsOn)
+
 		vml, vl := makeVolumesFromTmpFS(name, composeServiceConfig.Tmpfs)
 		for _, v := range vl {
 			serviceConfig.AddVolume(v)
@@ -238,6 +238,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		}
 
 		serviceConfig.Networks = c.getNetworks(composeServiceConfig, composeObject)
+		serviceConfig.Links = getLinks(composeServiceConfig.Links, composeServiceConfig.DependsOn)
 
 		if (composeServiceConfig.Deploy.Resources != types.Resources{}) {
 			if composeServiceConfig.Deploy.Resources.Limits != nil {
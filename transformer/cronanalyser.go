@@ -0,0 +1,385 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+var (
+	// cronWrappers are the executables which run the entries of a crontab in the foreground
+	cronWrappers = []string{"cron", "crond", "supercronic", "go-crond"}
+	// cronEnvPattern matches the environment variable assignments in crontabs
+	cronEnvPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+)
+
+const (
+	defaultCronShell = "/bin/sh"
+	// defaultSuccessfulJobsHistoryLimit and defaultFailedJobsHistoryLimit are the defaults used by Kubernetes
+	defaultSuccessfulJobsHistoryLimit int32 = 3
+	defaultFailedJobsHistoryLimit     int32 = 1
+)
+
+// CronAnalyser implements Transformer interface
+type CronAnalyser struct {
+	Config   transformertypes.Transformer
+	Env      *environment.Environment
+	crontabs map[string]crontab // [path]
+}
+
+// crontab stores the entries and the environment variables of a crontab file
+type crontab struct {
+	Entries []cronEntry
+	Env     []core.EnvVar
+	Shell   string
+}
+
+// cronEntry is a line of a crontab file
+type cronEntry struct {
+	Schedule string
+	Command  string
+}
+
+// Init Initializes the transformer
+func (t *CronAnalyser) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.crontabs = map[string]crontab{}
+	err = filepath.WalkDir(env.GetEnvironmentSource(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logrus.Debugf("Skipping path %s due to error: %s", path, err)
+			return nil
+		}
+		if d.IsDir() {
+			if path == env.GetEnvironmentSource() {
+				return nil
+			}
+			for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+				if dirRegExp.MatchString(d.Name()) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !isCrontabFile(path) {
+			return nil
+		}
+		ct, err := readCrontab(path)
+		if err != nil {
+			logrus.Debugf("Unable to read the crontab file at path %s : %s", path, err)
+			return nil
+		}
+		if len(ct.Entries) != 0 {
+			t.crontabs[path] = ct
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Unable to look for crontab files at path %s Error: %q", env.GetEnvironmentSource(), err)
+		return err
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *CronAnalyser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in base directory
+func (t *CronAnalyser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform transforms the artifacts
+func (t *CronAnalyser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	artifactsCreated := []transformertypes.Artifact{}
+	for _, a := range newArtifacts {
+		var ir irtypes.IR
+		err := a.GetConfig(irtypes.IRConfigType, &ir)
+		if err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		if len(t.crontabs) != 0 {
+			for sn, s := range ir.Services {
+				if s.OnlyIngress || s.Daemon || s.Schedule != nil || len(s.Containers) == 0 {
+					continue
+				}
+				t.convertCrontabs(ir, sn, s)
+			}
+		}
+		a.Configs[irtypes.IRConfigType] = ir
+		artifactsCreated = append(artifactsCreated, a)
+	}
+	return nil, artifactsCreated, nil
+}
+
+// convertCrontabs adds a scheduled service for each entry of the crontabs used by the service.
+// A service which only runs a cron daemon is replaced by the scheduled services.
+func (t *CronAnalyser) convertCrontabs(ir irtypes.IR, serviceName string, service irtypes.Service) {
+	containerIndex := getCronWrapperContainer(service)
+	isWrapper := containerIndex != -1
+	if !isWrapper {
+		containerIndex = 0
+	}
+	crontabPaths := t.getCrontabsOfService(ir, service, isWrapper)
+	if len(crontabPaths) == 0 {
+		if isWrapper {
+			logrus.Warnf("The service %s runs a cron daemon, but no crontab file was found for it. The service will be deployed as is", serviceName)
+		}
+		return
+	}
+	relCrontabPaths := []string{}
+	for _, crontabPath := range crontabPaths {
+		relCrontabPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), crontabPath)
+		if err != nil {
+			logrus.Errorf("Unable to make the path %s relative to the source directory : %s", crontabPath, err)
+			relCrontabPath = crontabPath
+		}
+		relCrontabPaths = append(relCrontabPaths, relCrontabPath)
+	}
+	defaultCrontabPaths := []string{}
+	if isWrapper {
+		defaultCrontabPaths = relCrontabPaths
+	}
+	selectedCrontabPaths := qaengine.FetchMultiSelectAnswer(common.ConfigServicesKey+common.Delim+`"`+serviceName+`"`+common.Delim+common.ConfigCrontabForServiceKeySegment, fmt.Sprintf("Select the crontab files whose entries should be run as CronJobs for the service %s :", serviceName), []string{"A CronJob is created for every entry of the selected crontab files"}, defaultCrontabPaths, relCrontabPaths)
+	scheduledServices := []irtypes.Service{}
+	for i, relCrontabPath := range relCrontabPaths {
+		if !common.IsStringPresent(selectedCrontabPaths, relCrontabPath) {
+			continue
+		}
+		ct := t.crontabs[crontabPaths[i]]
+		for _, entry := range ct.Entries {
+			scheduledServices = append(scheduledServices, newScheduledService(service, containerIndex, "", entry.Schedule, []string{ct.Shell, "-c", entry.Command}, ct.Env))
+		}
+	}
+	if len(scheduledServices) == 0 {
+		return
+	}
+	if isWrapper {
+		delete(ir.Services, serviceName)
+	}
+	for i, scheduledService := range scheduledServices {
+		name := serviceName
+		if !isWrapper {
+			name += "-cron"
+		}
+		if len(scheduledServices) > 1 {
+			name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		scheduledService.Name = common.MakeStringDNSLabelNameCompliant(name)
+		setScheduleOptions(&scheduledService)
+		ir.Services[scheduledService.Name] = scheduledService
+	}
+}
+
+// getCrontabsOfService returns the crontabs in the build context of the images of the service.
+// For services running a cron daemon, the crontabs whose names are used in the arguments or the mounted paths are also returned.
+func (t *CronAnalyser) getCrontabsOfService(ir irtypes.IR, service irtypes.Service, isWrapper bool) []string {
+	contextPaths := []string{}
+	names := []string{}
+	for _, c := range service.Containers {
+		if ci, ok := ir.ContainerImages[c.Image]; ok && ci.Build.ContextPath != "" {
+			contextPaths = append(contextPaths, ci.Build.ContextPath)
+		}
+		for _, arg := range append(append([]string{}, c.Command...), c.Args...) {
+			for _, field := range strings.Fields(arg) {
+				names = append(names, filepath.Base(field))
+			}
+		}
+		for _, vm := range c.VolumeMounts {
+			names = append(names, filepath.Base(vm.MountPath))
+		}
+	}
+	for _, v := range service.Volumes {
+		if v.HostPath != nil {
+			names = append(names, filepath.Base(v.HostPath.Path))
+		}
+	}
+	crontabPaths := []string{}
+	for crontabPath := range t.crontabs {
+		found := false
+		for _, contextPath := range contextPaths {
+			if common.IsParent(crontabPath, contextPath) {
+				found = true
+				break
+			}
+		}
+		if !found && isWrapper {
+			found = common.IsStringPresent(names, filepath.Base(crontabPath))
+		}
+		if found {
+			crontabPaths = append(crontabPaths, crontabPath)
+		}
+	}
+	return crontabPaths
+}
+
+// getCronWrapperContainer returns the index of the container which runs a cron daemon, -1 if there is no such container
+func getCronWrapperContainer(service irtypes.Service) int {
+	for i, c := range service.Containers {
+		for _, arg := range append(append([]string{}, c.Command...), c.Args...) {
+			for _, field := range strings.Fields(arg) {
+				if common.IsStringPresent(cronWrappers, filepath.Base(field)) {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// newScheduledService creates a service which runs the command in the given container of the service using the schedule
+func newScheduledService(service irtypes.Service, containerIndex int, name string, schedule string, command []string, env []core.EnvVar) irtypes.Service {
+	scheduledService := deepcopy.DeepCopy(service).(irtypes.Service)
+	scheduledService.Name = name
+	scheduledService.ServiceToPodPortForwardings = nil
+	scheduledService.Replicas = 0
+	scheduledService.Stateful = false
+	scheduledService.RestartPolicy = core.RestartPolicyOnFailure
+	scheduledService.Schedule = &irtypes.Schedule{Cron: schedule, ConcurrencyPolicy: batch.AllowConcurrent}
+	container := scheduledService.Containers[containerIndex]
+	container.Command = command
+	container.Args = nil
+	container.Env = append(container.Env, env...)
+	container.Ports = nil
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+	container.StartupProbe = nil
+	scheduledService.Containers = []core.Container{container}
+	return scheduledService
+}
+
+// setScheduleOptions asks for the concurrency policy and the history limits of the jobs of the scheduled service
+func setScheduleOptions(service *irtypes.Service) {
+	if service.Schedule == nil {
+		return
+	}
+	quesKeyPrefix := common.ConfigServicesKey + common.Delim + `"` + service.Name + `"` + common.Delim + common.ConfigCronJobForServiceKeySegment + common.Delim
+	policies := []string{string(batch.AllowConcurrent), string(batch.ForbidConcurrent), string(batch.ReplaceConcurrent)}
+	def := string(service.Schedule.ConcurrencyPolicy)
+	if def == "" {
+		def = string(batch.AllowConcurrent)
+	}
+	policy := qaengine.FetchSelectAnswer(quesKeyPrefix+"concurrencypolicy", fmt.Sprintf("Select the concurrency policy of the jobs of the scheduled service %s :", service.Name), []string{"Allow runs the jobs concurrently, Forbid skips a run while the previous job is running and Replace cancels the running job"}, def, policies)
+	if !common.IsStringPresent(policies, policy) {
+		logrus.Errorf("Unable to use the concurrency policy %s for the service %s. Using the default %s", policy, service.Name, def)
+		policy = def
+	}
+	service.Schedule.ConcurrencyPolicy = batch.ConcurrencyPolicy(policy)
+	service.Schedule.SuccessfulJobsHistoryLimit = fetchHistoryLimit(quesKeyPrefix+"successfuljobshistorylimit", fmt.Sprintf("Enter the number of successful jobs of the scheduled service %s to keep :", service.Name), service.Schedule.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit)
+	service.Schedule.FailedJobsHistoryLimit = fetchHistoryLimit(quesKeyPrefix+"failedjobshistorylimit", fmt.Sprintf("Enter the number of failed jobs of the scheduled service %s to keep :", service.Name), service.Schedule.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit)
+}
+
+// fetchHistoryLimit asks for the number of finished jobs to keep
+func fetchHistoryLimit(key, desc string, current *int32, def int32) *int32 {
+	if current != nil {
+		def = *current
+	}
+	ans := qaengine.FetchStringAnswer(key, desc, []string{"The older jobs and their pods are deleted"}, strconv.Itoa(int(def)))
+	value, err := strconv.ParseInt(strings.TrimSpace(ans), 10, 32)
+	if err != nil || value < 0 {
+		logrus.Errorf("Unable to use %s as the history limit for %s. Using the default %d", ans, key, def)
+		value = int64(def)
+	}
+	limit := int32(value)
+	return &limit
+}
+
+// isCrontabFile returns true if the file is named like a crontab or is in a cron.d directory
+func isCrontabFile(path string) bool {
+	name := filepath.Base(path)
+	return name == "crontab" || filepath.Ext(name) == ".crontab" || filepath.Ext(name) == ".cron" || filepath.Base(filepath.Dir(path)) == "cron.d"
+}
+
+// isSystemCrontab returns true for crontabs which have a user field before the command
+func isSystemCrontab(path string) bool {
+	return filepath.Base(filepath.Dir(path)) == "cron.d" || (filepath.Base(path) == "crontab" && filepath.Base(filepath.Dir(path)) == "etc")
+}
+
+// readCrontab parses a crontab file
+func readCrontab(path string) (crontab, error) {
+	ct := crontab{Shell: defaultCronShell}
+	f, err := os.Open(path)
+	if err != nil {
+		return ct, err
+	}
+	defer f.Close()
+	fieldsBeforeCommand := 5
+	if isSystemCrontab(path) {
+		fieldsBeforeCommand = 6
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if matches := cronEnvPattern.FindStringSubmatch(line); matches != nil {
+			name, value := matches[1], strings.Trim(strings.TrimSpace(matches[2]), `"'`)
+			switch name {
+			case "SHELL":
+				ct.Shell = value
+			case "MAILTO", "MAILFROM":
+			default:
+				ct.Env = append(ct.Env, core.EnvVar{Name: name, Value: value})
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		schedule := ""
+		commandFields := fieldsBeforeCommand
+		if strings.HasPrefix(fields[0], "@") {
+			if fields[0] == "@reboot" {
+				logrus.Warnf("Ignoring the entry %q in the crontab file at path %s since @reboot is not supported by CronJobs", line, path)
+				continue
+			}
+			schedule = fields[0]
+			commandFields = fieldsBeforeCommand - 4
+		} else if len(fields) > fieldsBeforeCommand {
+			schedule = strings.Join(fields[:5], " ")
+		}
+		if schedule == "" || len(fields) <= commandFields {
+			logrus.Warnf("Ignoring the line %q in the crontab file at path %s since it is not a valid crontab entry", line, path)
+			continue
+		}
+		command := strings.Join(fields[commandFields:], " ")
+		if strings.Contains(strings.ReplaceAll(command, `\%`, ""), "%") {
+			logrus.Warnf("The command %q in the crontab file at path %s uses %% to pass input. This is not supported in CronJobs", command, path)
+		}
+		ct.Entries = append(ct.Entries, cronEntry{Schedule: schedule, Command: strings.ReplaceAll(command, `\%`, "%")})
+	}
+	return ct, scanner.Err()
+}
@@ -0,0 +1,216 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestReadCrontab(t *testing.T) {
+	testcases := []struct {
+		name    string
+		relPath string
+		content string
+		want    crontab
+	}{
+		{
+			name:    "user crontab",
+			relPath: "app/crontab",
+			content: "# comment\n\n*/5 * * * * /app/cleanup.sh --all\n0 2 * * 1-5 backup.sh > /tmp/out 2>&1\n",
+			want: crontab{Shell: defaultCronShell, Entries: []cronEntry{
+				{Schedule: "*/5 * * * *", Command: "/app/cleanup.sh --all"},
+				{Schedule: "0 2 * * 1-5", Command: "backup.sh > /tmp/out 2>&1"},
+			}},
+		},
+		{
+			name:    "system crontab",
+			relPath: "etc/cron.d/jobs",
+			content: "*/5 * * * * root /app/cleanup.sh\n@daily www-data /app/report.sh\n",
+			want: crontab{Shell: defaultCronShell, Entries: []cronEntry{
+				{Schedule: "*/5 * * * *", Command: "/app/cleanup.sh"},
+				{Schedule: "@daily", Command: "/app/report.sh"},
+			}},
+		},
+		{
+			name:    "environment lines",
+			relPath: "jobs.cron",
+			content: "SHELL=/bin/bash\nMAILTO=admin@example.com\nPATH = /usr/local/bin:/usr/bin\nGREETING=\"hello world\"\n0 * * * * echo $GREETING\n",
+			want: crontab{
+				Shell:   "/bin/bash",
+				Env:     []core.EnvVar{{Name: "PATH", Value: "/usr/local/bin:/usr/bin"}, {Name: "GREETING", Value: "hello world"}},
+				Entries: []cronEntry{{Schedule: "0 * * * *", Command: "echo $GREETING"}},
+			},
+		},
+		{
+			name:    "macros",
+			relPath: "app.crontab",
+			content: "@daily /app/daily.sh\n@hourly /app/hourly.sh\n@reboot /app/start.sh\n",
+			want: crontab{Shell: defaultCronShell, Entries: []cronEntry{
+				{Schedule: "@daily", Command: "/app/daily.sh"},
+				{Schedule: "@hourly", Command: "/app/hourly.sh"},
+			}},
+		},
+		{
+			name:    "escaped percent signs",
+			relPath: "crontab",
+			content: "0 0 * * * tar czf /backup/$(date +\\%Y\\%m\\%d).tgz /data\n",
+			want: crontab{Shell: defaultCronShell, Entries: []cronEntry{
+				{Schedule: "0 0 * * *", Command: "tar czf /backup/$(date +%Y%m%d).tgz /data"},
+			}},
+		},
+		{
+			name:    "invalid lines are skipped",
+			relPath: "crontab",
+			content: "0 0 * * * /app/first.sh\n* * * *\n@daily\nnot a crontab entry\n0 1 * * * /app/second.sh\n",
+			want: crontab{Shell: defaultCronShell, Entries: []cronEntry{
+				{Schedule: "0 0 * * *", Command: "/app/first.sh"},
+				{Schedule: "0 1 * * *", Command: "/app/second.sh"},
+			}},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.relPath)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("Failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+			}
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to write the crontab file %s . Error: %q", path, err)
+			}
+			actual, err := readCrontab(path)
+			if err != nil {
+				t.Fatalf("Failed to read the crontab file %s . Error: %q", path, err)
+			}
+			if diff := cmp.Diff(tc.want, actual); diff != "" {
+				t.Fatalf("The crontab was not read properly. Differences:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsCrontabFile(t *testing.T) {
+	testcases := []struct {
+		path     string
+		crontab  bool
+		isSystem bool
+	}{
+		{path: "/src/crontab", crontab: true},
+		{path: "/src/etc/crontab", crontab: true, isSystem: true},
+		{path: "/src/etc/cron.d/backup", crontab: true, isSystem: true},
+		{path: "/src/jobs.cron", crontab: true},
+		{path: "/src/app.crontab", crontab: true},
+		{path: "/src/cron.yaml", crontab: false},
+		{path: "/src/main.go", crontab: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			if actual := isCrontabFile(tc.path); actual != tc.crontab {
+				t.Fatalf("Expected isCrontabFile to be %t. Actual: %t", tc.crontab, actual)
+			}
+			if actual := isSystemCrontab(tc.path); actual != tc.isSystem {
+				t.Fatalf("Expected isSystemCrontab to be %t. Actual: %t", tc.isSystem, actual)
+			}
+		})
+	}
+}
+
+func TestGetCronWrapperContainer(t *testing.T) {
+	testcases := []struct {
+		name       string
+		containers []core.Container
+		want       int
+	}{
+		{name: "no containers", want: -1},
+		{name: "web server", containers: []core.Container{{Command: []string{"nginx", "-g", "daemon off;"}}}, want: -1},
+		{name: "cron in the foreground", containers: []core.Container{{Command: []string{"cron", "-f"}}}, want: 0},
+		{name: "crond with a path", containers: []core.Container{{Command: []string{"/usr/sbin/crond"}, Args: []string{"-f", "-l", "2"}}}, want: 0},
+		{name: "supercronic in a shell command", containers: []core.Container{{Command: []string{"sh", "-c", "supercronic /etc/crontab"}}}, want: 0},
+		{name: "sidecar", containers: []core.Container{{Command: []string{"/app/server"}}, {Args: []string{"go-crond", "root:/etc/crontab"}}}, want: 1},
+		{name: "cron in an argument value", containers: []core.Container{{Command: []string{"/app/server", "--mode=cron"}}}, want: -1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := irtypes.NewServiceWithName("svc")
+			service.Containers = tc.containers
+			if actual := getCronWrapperContainer(service); actual != tc.want {
+				t.Fatalf("Expected the cron wrapper container to be %d. Actual: %d", tc.want, actual)
+			}
+		})
+	}
+}
+
+func TestNewScheduledService(t *testing.T) {
+	service := irtypes.NewServiceWithName("svc")
+	service.Replicas = 2
+	service.Containers = []core.Container{
+		{Name: "app", Image: "app", Command: []string{"/app/server"}, Ports: []core.ContainerPort{{ContainerPort: 8080}}, Env: []core.EnvVar{{Name: "A", Value: "1"}}},
+		{Name: "cron", Image: "app", Command: []string{"cron", "-f"}},
+	}
+	actual := newScheduledService(service, 0, "svc-cron", "@daily", []string{"/bin/sh", "-c", "/app/report.sh"}, []core.EnvVar{{Name: "B", Value: "2"}})
+	want := core.Container{Name: "app", Image: "app", Command: []string{"/bin/sh", "-c", "/app/report.sh"}, Env: []core.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}}
+	if diff := cmp.Diff([]core.Container{want}, actual.Containers); diff != "" {
+		t.Fatalf("The container of the scheduled service is incorrect. Differences:\n%s", diff)
+	}
+	if actual.Schedule == nil || actual.Schedule.Cron != "@daily" || actual.RestartPolicy != core.RestartPolicyOnFailure || actual.Replicas != 0 {
+		t.Fatalf("The scheduled service is incorrect. Actual: %+v", actual)
+	}
+	if len(service.Containers) != 2 || service.Containers[0].Command[0] != "/app/server" || len(service.Containers[0].Env) != 1 {
+		t.Fatalf("The original service should not have been modified. Actual: %+v", service.Containers)
+	}
+}
+
+func TestSetScheduleOptions(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	int32Ptr := func(i int32) *int32 { return &i }
+	testcases := []struct {
+		name     string
+		schedule *irtypes.Schedule
+		want     *irtypes.Schedule
+	}{
+		{
+			name:     "kubernetes defaults",
+			schedule: &irtypes.Schedule{Cron: "@daily"},
+			want:     &irtypes.Schedule{Cron: "@daily", ConcurrencyPolicy: batch.AllowConcurrent, SuccessfulJobsHistoryLimit: int32Ptr(3), FailedJobsHistoryLimit: int32Ptr(1)},
+		},
+		{
+			name:     "existing options",
+			schedule: &irtypes.Schedule{Cron: "*/5 * * * *", ConcurrencyPolicy: batch.ForbidConcurrent, SuccessfulJobsHistoryLimit: int32Ptr(0)},
+			want:     &irtypes.Schedule{Cron: "*/5 * * * *", ConcurrencyPolicy: batch.ForbidConcurrent, SuccessfulJobsHistoryLimit: int32Ptr(0), FailedJobsHistoryLimit: int32Ptr(1)},
+		},
+		{
+			name: "service without a schedule",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := irtypes.NewServiceWithName("report")
+			service.Schedule = tc.schedule
+			setScheduleOptions(&service)
+			if diff := cmp.Diff(tc.want, service.Schedule); diff != "" {
+				t.Fatalf("The schedule is incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
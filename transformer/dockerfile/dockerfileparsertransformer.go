@@ -126,6 +126,8 @@ func (t *DockerfileParser) getIRFromDockerfile(dockerfilepath, contextPath, imag
 			}
 		}
 	}
+	container.BaseImage = t.getBaseImage(df)
+	container.MaxHeapSize = t.getMaxHeapSize(df, contextPath)
 	container.Build.ContainerBuildType = irtypes.DockerfileContainerBuildType
 	container.Build.ContextPath = contextPath
 	container.Build.Artifacts = map[irtypes.ContainerBuildArtifactTypeValue][]string{
@@ -203,3 +205,71 @@ func (t *DockerfileParser) isWindowsContainer(df *dockerparser.Result) bool {
 	}
 	return false
 }
+
+// getBaseImage returns the base image of the final stage of the dockerfile
+func (t *DockerfileParser) getBaseImage(df *dockerparser.Result) string {
+	stageImages := map[string]string{}
+	baseImage := ""
+	for _, dfchild := range df.AST.Children {
+		if !strings.EqualFold(dfchild.Value, "FROM") || dfchild.Next == nil {
+			continue
+		}
+		baseImage = dfchild.Next.Value
+		if stageImage, ok := stageImages[strings.ToLower(baseImage)]; ok {
+			baseImage = stageImage
+		}
+		if aliasNode := dfchild.Next.Next; aliasNode != nil && strings.EqualFold(aliasNode.Value, "AS") && aliasNode.Next != nil {
+			stageImages[strings.ToLower(aliasNode.Next.Value)] = baseImage
+		}
+	}
+	return baseImage
+}
+
+// getMaxHeapSize returns the max JVM heap size set in the dockerfile or in the start scripts it runs
+func (t *DockerfileParser) getMaxHeapSize(df *dockerparser.Result, contextPath string) int64 {
+	maxHeapSize := int64(0)
+	for _, dfchild := range df.AST.Children {
+		if size := common.GetJavaMaxHeapSize(dfchild.Original); size != 0 {
+			maxHeapSize = size
+		}
+		if !strings.EqualFold(dfchild.Value, "CMD") && !strings.EqualFold(dfchild.Value, "ENTRYPOINT") {
+			continue
+		}
+		for node := dfchild.Next; node != nil; node = node.Next {
+			for _, word := range strings.Fields(node.Value) {
+				if !strings.HasSuffix(word, ".sh") {
+					continue
+				}
+				for _, scriptPath := range []string{filepath.Join(contextPath, word), filepath.Join(contextPath, filepath.Base(word))} {
+					if !isInContext(scriptPath, contextPath) {
+						logrus.Debugf("Ignoring the script %s since it is not in the build context %s", word, contextPath)
+						continue
+					}
+					script, err := os.ReadFile(scriptPath)
+					if err != nil {
+						continue
+					}
+					if size := common.GetJavaMaxHeapSize(string(script)); size != 0 {
+						maxHeapSize = size
+					}
+					break
+				}
+			}
+		}
+	}
+	return maxHeapSize
+}
+
+// isInContext returns true if the path, after resolving symbolic links, is in the build context
+func isInContext(path, contextPath string) bool {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	realContextPath, err := filepath.EvalSymlinks(contextPath)
+	if err != nil {
+		logrus.Debugf("Unable to resolve the build context path %s : %s", contextPath, err)
+		return false
+	}
+	return common.IsParent(realPath, realContextPath)
+}
@@ -178,6 +178,12 @@ func (t *GradleAnalyser) DirectoryDetect(dir string) (services map[string][]tran
 			if dependency.Version != "" {
 				sbc.SpringBootVersion = dependency.Version
 			}
+			for _, dependency := range gradleBuild.Dependencies {
+				if dependency.Group == springbootGroup && dependency.Name == springbootActuator {
+					sbc.SpringBootActuator = true
+					break
+				}
+			}
 			ct.Configs[artifacts.SpringBootConfigType] = sbc
 			break
 		}
@@ -458,6 +464,9 @@ func (t *GradleAnalyser) Transform(newArtifacts []transformertypes.Artifact, alr
 		if irPresent {
 			newArtifact.Configs[irtypes.IRConfigType] = injectProperties(ir, a.Name)
 		}
+		if springbootConfig.SpringBootActuator && newArtifact.Type == artifacts.JarArtifactType {
+			newArtifact.Configs[artifacts.SpringBootConfigType] = springbootConfig
+		}
 		if newArtifact.Configs == nil {
 			newArtifact.Configs = map[transformertypes.ConfigType]interface{}{}
 		}
@@ -226,6 +226,10 @@ func (t *JarAnalyser) Transform(newArtifacts []transformertypes.Artifact, alread
 		if err = a.GetConfig(irtypes.IRConfigType, &ir); err == nil {
 			dfs.Configs[irtypes.IRConfigType] = ir
 		}
+		springbootConfig := artifacts.SpringBootConfig{}
+		if err = a.GetConfig(artifacts.SpringBootConfigType, &springbootConfig); err == nil && springbootConfig.SpringBootActuator {
+			dfs.Configs[irtypes.IRConfigType] = addSpringBootHealthCheck(ir, sConfig.ServiceName)
+		}
 		createdArtifacts = append(createdArtifacts, p, dfs)
 	}
 	return pathMappings, createdArtifacts, nil
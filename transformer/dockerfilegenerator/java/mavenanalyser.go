@@ -154,6 +154,12 @@ func (t *MavenAnalyser) DirectoryDetect(dir string) (services map[string][]trans
 				if dependency.Version != "" {
 					sbc.SpringBootVersion = dependency.Version
 				}
+				for _, dependency := range *pom.Dependencies {
+					if dependency.GroupID == springbootGroup && dependency.ArtifactID == springbootActuator {
+						sbc.SpringBootActuator = true
+						break
+					}
+				}
 				ct.Configs[artifacts.SpringBootConfigType] = sbc
 				break
 			}
@@ -454,6 +460,9 @@ func (t *MavenAnalyser) Transform(newArtifacts []transformertypes.Artifact, alre
 		if irPresent {
 			newArtifact.Configs[irtypes.IRConfigType] = injectProperties(ir, a.Name)
 		}
+		if springbootConfig.SpringBootActuator && newArtifact.Type == artifacts.JarArtifactType {
+			newArtifact.Configs[artifacts.SpringBootConfigType] = springbootConfig
+		}
 		if newArtifact.Configs == nil {
 			newArtifact.Configs = map[transformertypes.ConfigType]interface{}{}
 		}
@@ -43,6 +43,11 @@ const (
 	springbootAppNameConfig  = "spring.application.name"
 	springbootProfilesConfig = "spring.profiles"
 	springbootGroup          = "org.springframework.boot"
+	springbootActuator       = "spring-boot-starter-actuator"
+	// springbootHealthPath is the health endpoint of the spring boot actuator
+	springbootHealthPath = "/actuator/health"
+	// springbootStartupTimeout is the time in seconds a spring boot app is given to start
+	springbootStartupTimeout = 120
 )
 
 const (
@@ -347,3 +352,22 @@ func getPropertiesFileSegmentsAsProperties(segments []string) (props []*properti
 	}
 	return
 }
+
+// addSpringBootHealthCheck sets the health check of the service to the health endpoint of the spring boot actuator
+func addSpringBootHealthCheck(ir irtypes.IR, serviceName string) irtypes.IR {
+	if ir.ContainerImages == nil {
+		ir.ContainerImages = map[string]irtypes.ContainerImage{}
+	}
+	if ir.Services == nil {
+		ir.Services = map[string]irtypes.Service{}
+	}
+	service, ok := ir.Services[serviceName]
+	if !ok {
+		service = irtypes.NewServiceWithName(serviceName)
+	}
+	if service.HealthCheck == nil {
+		service.HealthCheck = &irtypes.HealthCheck{Type: irtypes.HTTPHealthCheckType, Path: springbootHealthPath, StartupTimeout: springbootStartupTimeout}
+	}
+	ir.Services[serviceName] = service
+	return ir
+}
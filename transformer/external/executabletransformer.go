@@ -48,6 +48,7 @@ type ExecutableYamlConfig struct {
 	DirectoryDetectCMD environmenttypes.Command   `yaml:"directoryDetectCMD"`
 	TransformCMD       environmenttypes.Command   `yaml:"transformCMD"`
 	Container          environmenttypes.Container `yaml:"container,omitempty"`
+	Remote             environmenttypes.Remote    `yaml:"remote,omitempty"`
 }
 
 // Init Initializes the transformer
@@ -67,10 +68,10 @@ func (t *Executable) Init(tc transformertypes.Transformer, env *environment.Envi
 			logrus.Infof("Starting transformer that requires QA without QA.")
 		}
 	}
-	if !common.IsStringPresent(t.ExecConfig.Platforms, runtime.GOOS) && t.ExecConfig.Container.Image == "" {
+	if !common.IsStringPresent(t.ExecConfig.Platforms, runtime.GOOS) && t.ExecConfig.Container.Image == "" && t.ExecConfig.Remote.Host == "" {
 		return fmt.Errorf("platform %s not supported by transformer %s", runtime.GOOS, tc.Name)
 	}
-	t.Env, err = environment.NewEnvironment(env.EnvInfo, qaRPCReceiverAddr, t.ExecConfig.Container)
+	t.Env, err = environment.NewEnvironment(env.EnvInfo, qaRPCReceiverAddr, t.ExecConfig.Container, t.ExecConfig.Remote)
 	if err != nil {
 		logrus.Errorf("Unable to create Exec environment : %s", err)
 		return err
@@ -406,7 +406,7 @@ func (t *Starlark) getStarlarkFSWrite() *starlark.Builtin {
 		if filePath == "" {
 			return starlark.None, fmt.Errorf("FilePath is missing in write parameters")
 		}
-		if !t.Env.IsPathValid(filePath) {
+		if !t.Env.IsPathWritable(filePath) {
 			return starlark.None, fmt.Errorf("invalid path")
 		}
 		if len(data) == 0 {
@@ -0,0 +1,39 @@
+;; transformer.wasm is compiled from this file using wat2wasm.
+;; It is a minimal WASM transformer which is used to test the ABI between move2kube and the wasm modules.
+(module
+  (import "m2k" "query" (func $query (param i32 i32) (result i64)))
+  (memory (export "memory") 1)
+  (global $heap (mut i32) (i32.const 1024))
+
+  ;; allocate is a bump allocator which is used by move2kube to pass data to the module
+  (func (export "allocate") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $heap))
+    (global.set $heap (i32.add (global.get $heap) (local.get $size)))
+    (local.get $ptr))
+
+  ;; echo returns its input
+  (func (export "echo") (param $ptr i32) (param $size i32) (result i64)
+    (call $pack (local.get $ptr) (local.get $size)))
+
+  ;; ask asks the question in its input using the query function of move2kube and returns the answered question
+  (func (export "ask") (param $ptr i32) (param $size i32) (result i64)
+    (call $query (local.get $ptr) (local.get $size)))
+
+  (func (export "directory_detect") (param $ptr i32) (param $size i32) (result i64)
+    (call $pack (i32.const 16) (i32.const 87)))
+
+  (func (export "transform") (param $ptr i32) (param $size i32) (result i64)
+    (call $pack (i32.const 512) (i32.const 177)))
+
+  (func (export "trap") (param $ptr i32) (param $size i32) (result i64)
+    (unreachable))
+
+  ;; pack returns the offset in the upper 32 bits and the length in the lower 32 bits
+  (func $pack (param $ptr i32) (param $size i32) (result i64)
+    (i64.or
+      (i64.shl (i64.extend_i32_u (local.get $ptr)) (i64.const 32))
+      (i64.extend_i32_u (local.get $size))))
+
+  (data (i32.const 16) "{\"svc1\":[{\"name\":\"svc1\",\"type\":\"Service\",\"paths\":{\"ServiceDirPath\":[\"/source/svc1\"]}}]}")
+  (data (i32.const 512) "{\"pathMappings\":[{\"type\":\"Default\",\"sourcePath\":\"/temp/out\",\"destinationPath\":\"out\"}],\"artifacts\":[{\"name\":\"svc1\",\"type\":\"Service\",\"paths\":{\"ServiceDirPath\":[\"/source/svc1\"]}}]}"))
@@ -0,0 +1,413 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/common/pathconverters"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	// wasmHostModuleName is the name of the module which provides the move2kube functions to the wasm modules
+	wasmHostModuleName = types.AppNameShort
+	// wasmAllocateFnName is the function exported by the wasm module to allocate memory for the host
+	wasmAllocateFnName = "allocate"
+	// wasmReactorInitFnName is the function exported by WASI reactors to initialize the module
+	wasmReactorInitFnName = "_initialize"
+	// wasmCommandStartFnName is the function exported by WASI commands
+	wasmCommandStartFnName = "_start"
+
+	// wasmSourceDir is the path at which the source is mounted read only in the wasm module
+	wasmSourceDir = "/source"
+	// wasmContextDir is the path at which the transformer context is mounted read only in the wasm module
+	wasmContextDir = "/context"
+	// wasmTempDir is the path at which the temp directory of the environment, which contains the output, is mounted in the wasm module
+	wasmTempDir = "/temp"
+)
+
+// WASM implements transformer interface and is used to write external transformers as WebAssembly modules.
+// The module exports the functions directory_detect and transform, which take and return JSON.
+// The paths are converted so that they point to the directories mounted in the module.
+type WASM struct {
+	Config     transformertypes.Transformer
+	WASMConfig *WASMYamlConfig
+	Env        *environment.Environment
+
+	ctx            context.Context
+	runtime        wazero.Runtime
+	compiledModule wazero.CompiledModule
+	startFnName    string
+}
+
+// WASMYamlConfig defines yaml config for WASM transformers
+type WASMYamlConfig struct {
+	WASMModule string            `yaml:"wasmModule"`
+	Env        map[string]string `yaml:"env"`
+}
+
+// wasmDetectInput is the input passed to the directory_detect function of the wasm module
+type wasmDetectInput struct {
+	Dir string `json:"dir"`
+}
+
+// wasmTransformInput is the input passed to the transform function of the wasm module
+type wasmTransformInput struct {
+	NewArtifacts         []transformertypes.Artifact `json:"newArtifacts"`
+	AlreadySeenArtifacts []transformertypes.Artifact `json:"alreadySeenArtifacts"`
+}
+
+// Init Initializes the transformer
+func (t *WASM) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.WASMConfig = &WASMYamlConfig{}
+	err = common.GetObjFromInterface(t.Config.Spec.Config, t.WASMConfig)
+	if err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.WASMConfig, err)
+		return err
+	}
+	if t.WASMConfig.WASMModule == "" {
+		err := fmt.Errorf("no wasm module specified")
+		logrus.Errorf("%s", err)
+		return err
+	}
+	modulePath := filepath.Join(t.Env.GetEnvironmentContext(), t.WASMConfig.WASMModule)
+	moduleBytes, err := os.ReadFile(modulePath)
+	if err != nil {
+		logrus.Errorf("Unable to read the wasm module %s : %s", modulePath, err)
+		return err
+	}
+	t.ctx = context.Background()
+	t.runtime = wazero.NewRuntime(t.ctx)
+	defer func() {
+		if err != nil {
+			if err := t.Destroy(); err != nil {
+				logrus.Errorf("Unable to close the wasm runtime : %s", err)
+			}
+		}
+	}()
+	if _, err := wasi_snapshot_preview1.Instantiate(t.ctx, t.runtime); err != nil {
+		logrus.Errorf("Unable to instantiate WASI : %s", err)
+		return err
+	}
+	_, err = t.runtime.NewHostModuleBuilder(wasmHostModuleName).
+		NewFunctionBuilder().WithFunc(t.query).Export(qaFnName).
+		Instantiate(t.ctx)
+	if err != nil {
+		logrus.Errorf("Unable to instantiate the %s host module : %s", wasmHostModuleName, err)
+		return err
+	}
+	t.compiledModule, err = t.runtime.CompileModule(t.ctx, moduleBytes)
+	if err != nil {
+		logrus.Errorf("Unable to compile the wasm module %s : %s", modulePath, err)
+		return err
+	}
+	exportedFns := t.compiledModule.ExportedFunctions()
+	if _, ok := exportedFns[wasmAllocateFnName]; !ok {
+		err := fmt.Errorf("the wasm module %s does not export the function %s", modulePath, wasmAllocateFnName)
+		logrus.Errorf("%s", err)
+		return err
+	}
+	if _, ok := exportedFns[wasmReactorInitFnName]; ok {
+		t.startFnName = wasmReactorInitFnName
+	} else if _, ok := exportedFns[wasmCommandStartFnName]; ok {
+		t.startFnName = wasmCommandStartFnName
+	}
+	return nil
+}
+
+// Destroy closes the compiled module and the wasm runtime
+func (t *WASM) Destroy() error {
+	if t.compiledModule != nil {
+		if err := t.compiledModule.Close(t.ctx); err != nil {
+			logrus.Errorf("Unable to close the compiled wasm module : %s", err)
+		}
+		t.compiledModule = nil
+	}
+	if t.runtime == nil {
+		return nil
+	}
+	err := t.runtime.Close(t.ctx)
+	t.runtime = nil
+	return err
+}
+
+// GetConfig returns the transformer config
+func (t *WASM) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *WASM) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	if _, ok := t.compiledModule.ExportedFunctions()[directoryDetectFnName]; !ok {
+		return nil, nil
+	}
+	guestDir, err := t.toGuestPath(dir)
+	if err != nil {
+		logrus.Errorf("Unable to convert the path %s to a path in the wasm module : %s", dir, err)
+		return nil, err
+	}
+	output, err := t.call(directoryDetectFnName, wasmDetectInput{Dir: guestDir})
+	if err != nil {
+		logrus.Errorf("Unable to execute the wasm function %s : %s", directoryDetectFnName, err)
+		return nil, err
+	}
+	services = map[string][]transformertypes.Artifact{}
+	if err := json.Unmarshal(output, &services); err != nil {
+		logrus.Errorf("Unable to unmarshal the output of the wasm function %s : %s", directoryDetectFnName, err)
+		return nil, err
+	}
+	if err := pathconverters.ProcessPaths(&services, t.toHostPath); err != nil {
+		logrus.Errorf("Unable to convert the paths in the output of the wasm function %s : %s", directoryDetectFnName, err)
+		return nil, err
+	}
+	return services, nil
+}
+
+// Transform transforms the artifacts
+func (t *WASM) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) (pathMappings []transformertypes.PathMapping, createdArtifacts []transformertypes.Artifact, err error) {
+	if _, ok := t.compiledModule.ExportedFunctions()[transformFnName]; !ok {
+		return nil, nil, nil
+	}
+	input := wasmTransformInput{
+		NewArtifacts:         deepcopy.DeepCopy(newArtifacts).([]transformertypes.Artifact),
+		AlreadySeenArtifacts: deepcopy.DeepCopy(alreadySeenArtifacts).([]transformertypes.Artifact),
+	}
+	if err := pathconverters.ProcessPaths(&input, t.toGuestPath); err != nil {
+		logrus.Errorf("Unable to convert the paths in the artifacts to paths in the wasm module : %s", err)
+		return nil, nil, err
+	}
+	output, err := t.call(transformFnName, input)
+	if err != nil {
+		logrus.Errorf("Unable to execute the wasm function %s : %s", transformFnName, err)
+		return nil, nil, err
+	}
+	transformOutput := transformertypes.TransformOutput{}
+	if err := json.Unmarshal(output, &transformOutput); err != nil {
+		logrus.Errorf("Unable to unmarshal the output of the wasm function %s : %s", transformFnName, err)
+		return nil, nil, err
+	}
+	if err := pathconverters.ProcessPaths(&transformOutput, t.toHostPath); err != nil {
+		logrus.Errorf("Unable to convert the paths in the output of the wasm function %s : %s", transformFnName, err)
+		return nil, nil, err
+	}
+	return transformOutput.PathMappings, transformOutput.CreatedArtifacts, nil
+}
+
+// call instantiates a fresh instance of the module and calls the function with the JSON encoded input
+func (t *WASM) call(fnName string, input interface{}) ([]byte, error) {
+	inputBytes, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	defer func() {
+		if stdout.Len() > 0 {
+			logrus.Debugf("%s stdout : %s", t.Config.Name, stdout.String())
+		}
+		if stderr.Len() > 0 {
+			logrus.Debugf("%s stderr : %s", t.Config.Name, stderr.String())
+		}
+	}()
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithFSConfig(t.getFSConfig()).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithArgs(t.Config.Name).
+		WithStartFunctions()
+	moduleConfig = moduleConfig.
+		WithEnv(environment.ProjectNameEnvName, t.Env.GetProjectName()).
+		WithEnv(environment.SourceEnvName, wasmSourceDir).
+		WithEnv(environment.ContextEnvName, wasmContextDir).
+		WithEnv(environment.TempPathEnvName, wasmTempDir).
+		WithEnv(environment.RelTemplatesDirEnvName, t.Env.RelTemplatesDir)
+	for k, v := range t.WASMConfig.Env {
+		moduleConfig = moduleConfig.WithEnv(k, v)
+	}
+	for _, envvar := range t.Env.EnvInfo.Env {
+		if kv := strings.SplitN(envvar, "=", 2); len(kv) == 2 {
+			moduleConfig = moduleConfig.WithEnv(kv[0], kv[1])
+		}
+	}
+	mod, err := t.runtime.InstantiateModule(t.ctx, t.compiledModule, moduleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to instantiate the wasm module : %s", err)
+	}
+	defer mod.Close(t.ctx)
+	if t.startFnName != "" {
+		if _, err := mod.ExportedFunction(t.startFnName).Call(t.ctx); err != nil {
+			return nil, fmt.Errorf("unable to initialize the wasm module : %s", err)
+		}
+	}
+	ptr, err := writeToWASMMemory(t.ctx, mod, inputBytes)
+	if err != nil {
+		return nil, err
+	}
+	results, err := mod.ExportedFunction(fnName).Call(t.ctx, uint64(ptr), uint64(len(inputBytes)))
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("expected the function %s to return a single value, but it returned %d values", fnName, len(results))
+	}
+	return readFromWASMMemory(mod, results[0])
+}
+
+// getMounts returns the host directories mounted in the module, keyed by the path in the module
+func (t *WASM) getMounts() map[string]string {
+	return map[string]string{
+		wasmSourceDir:  t.Env.GetEnvironmentSource(),
+		wasmContextDir: t.Env.GetEnvironmentContext(),
+		wasmTempDir:    t.Env.TempPath,
+	}
+}
+
+// getFSConfig returns the file system visible to the module. The source and context are read only.
+func (t *WASM) getFSConfig() wazero.FSConfig {
+	mounts := t.getMounts()
+	return wazero.NewFSConfig().
+		WithReadOnlyDirMount(mounts[wasmSourceDir], wasmSourceDir).
+		WithReadOnlyDirMount(mounts[wasmContextDir], wasmContextDir).
+		WithDirMount(mounts[wasmTempDir], wasmTempDir)
+}
+
+// toGuestPath converts a path outside the module to the path in the module.
+// The source and context are checked first since they could be within the temp directory.
+func (t *WASM) toGuestPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return path, nil
+	}
+	mounts := t.getMounts()
+	for _, guestDir := range []string{wasmSourceDir, wasmContextDir, wasmTempDir} {
+		hostDir := mounts[guestDir]
+		if hostDir == "" || !common.IsParent(path, hostDir) {
+			continue
+		}
+		rel, err := filepath.Rel(hostDir, path)
+		if err != nil {
+			return path, err
+		}
+		return filepath.Join(guestDir, rel), nil
+	}
+	return "", fmt.Errorf("the path %s is not accessible in the wasm module", path)
+}
+
+// toHostPath converts a path in the module to the path outside the module
+func (t *WASM) toHostPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return path, nil
+	}
+	for guestDir, hostDir := range t.getMounts() {
+		if !common.IsParent(path, guestDir) {
+			continue
+		}
+		rel, err := filepath.Rel(guestDir, path)
+		if err != nil {
+			return path, err
+		}
+		return filepath.Join(hostDir, rel), nil
+	}
+	return "", fmt.Errorf("the path %s returned by the wasm module is not within %s, %s or %s", path, wasmSourceDir, wasmContextDir, wasmTempDir)
+}
+
+// query is called by the wasm module to ask a question. The problem and the answered problem are JSON encoded.
+func (t *WASM) query(ctx context.Context, mod api.Module, ptr, size uint32) uint64 {
+	probBytes, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		logrus.Errorf("Unable to read the problem from the memory of the wasm module of %s", t.Config.Name)
+		return 0
+	}
+	prob := qatypes.Problem{}
+	if err := json.Unmarshal(probBytes, &prob); err != nil {
+		logrus.Errorf("Unable to unmarshal the problem %s : %s", probBytes, err)
+		return 0
+	}
+	if prob.ID == "" {
+		logrus.Errorf("the key 'id' is missing from the question object %s", probBytes)
+		return 0
+	}
+	if !strings.HasPrefix(prob.ID, common.BaseKey) {
+		prob.ID = common.BaseKey + common.Delim + prob.ID
+	}
+	if prob.Type == "" {
+		prob.Type = qatypes.InputSolutionFormType
+	}
+	resolved, err := qaengine.FetchAnswer(prob)
+	if err != nil {
+		logrus.Fatalf("failed to ask the question. Error: %q", err)
+	}
+	resolvedBytes, err := json.Marshal(resolved)
+	if err != nil {
+		logrus.Errorf("Unable to marshal the answered problem %+v : %s", resolved, err)
+		return 0
+	}
+	resolvedPtr, err := writeToWASMMemory(ctx, mod, resolvedBytes)
+	if err != nil {
+		logrus.Errorf("Unable to write the answer to the memory of the wasm module of %s : %s", t.Config.Name, err)
+		return 0
+	}
+	return uint64(resolvedPtr)<<32 | uint64(len(resolvedBytes))
+}
+
+// writeToWASMMemory allocates memory in the module using its allocate function and copies the data into it
+func writeToWASMMemory(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	results, err := mod.ExportedFunction(wasmAllocateFnName).Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("unable to allocate memory in the wasm module : %s", err)
+	}
+	if len(results) != 1 {
+		return 0, fmt.Errorf("expected the function %s to return a single value, but it returned %d values", wasmAllocateFnName, len(results))
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("unable to write %d bytes at offset %d of the wasm module memory", len(data), ptr)
+	}
+	return ptr, nil
+}
+
+// readFromWASMMemory reads the data pointed to by a value with the offset in the upper 32 bits and the length in the lower 32 bits
+func readFromWASMMemory(mod api.Module, ptrSize uint64) ([]byte, error) {
+	ptr := uint32(ptrSize >> 32)
+	size := uint32(ptrSize)
+	data, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return nil, fmt.Errorf("unable to read %d bytes at offset %d of the wasm module memory", size, ptr)
+	}
+	// The memory is released when the module is closed, so a copy is returned
+	return append([]byte{}, data...), nil
+}
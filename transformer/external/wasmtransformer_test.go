@@ -0,0 +1,270 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// testWASMContextDir contains transformer.wasm, which is compiled from transformer.wat
+var testWASMContextDir = filepath.Join("testdata", "wasm")
+
+func newTestWASM(t *testing.T, contextDir string, config map[string]interface{}) (*WASM, error) {
+	t.Helper()
+	common.TempPath = t.TempDir()
+	contextDir, err := filepath.Abs(contextDir)
+	if err != nil {
+		t.Fatalf("failed to get the absolute path of %s . Error: %q", contextDir, err)
+	}
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:        "test",
+		ProjectName: "myproject",
+		Source:      t.TempDir(),
+		Context:     contextDir,
+	}, nil, environmenttypes.Container{}, environmenttypes.Remote{})
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	t.Cleanup(func() { env.Destroy() })
+	tc := transformertypes.Transformer{}
+	tc.Name = "test"
+	tc.Spec.Config = config
+	wasm := &WASM{}
+	t.Cleanup(func() { wasm.Destroy() })
+	return wasm, wasm.Init(tc, env)
+}
+
+func TestWASMInit(t *testing.T) {
+	t.Run("module without an allocate function", func(t *testing.T) {
+		contextDir := t.TempDir()
+		emptyModule := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+		if err := os.WriteFile(filepath.Join(contextDir, "empty.wasm"), emptyModule, 0644); err != nil {
+			t.Fatalf("failed to write the wasm module. Error: %q", err)
+		}
+		wasm, err := newTestWASM(t, contextDir, map[string]interface{}{"wasmModule": "empty.wasm"})
+		if err == nil {
+			t.Fatalf("should have failed since the module does not export the allocate function")
+		}
+		if wasm.runtime != nil || wasm.compiledModule != nil {
+			t.Fatalf("the wasm runtime should have been closed when the initialization failed")
+		}
+	})
+
+	t.Run("invalid module", func(t *testing.T) {
+		contextDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(contextDir, "invalid.wasm"), []byte("not a wasm module"), 0644); err != nil {
+			t.Fatalf("failed to write the wasm module. Error: %q", err)
+		}
+		if _, err := newTestWASM(t, contextDir, map[string]interface{}{"wasmModule": "invalid.wasm"}); err == nil {
+			t.Fatalf("should have failed to compile an invalid module")
+		}
+	})
+
+	t.Run("missing module", func(t *testing.T) {
+		if _, err := newTestWASM(t, t.TempDir(), map[string]interface{}{"wasmModule": "missing.wasm"}); err == nil {
+			t.Fatalf("should have failed since the module does not exist")
+		}
+	})
+
+	t.Run("no module specified", func(t *testing.T) {
+		if _, err := newTestWASM(t, testWASMContextDir, map[string]interface{}{}); err == nil {
+			t.Fatalf("should have failed since no module is specified")
+		}
+	})
+}
+
+func TestWASMDestroy(t *testing.T) {
+	wasm, err := newTestWASM(t, testWASMContextDir, map[string]interface{}{"wasmModule": "transformer.wasm"})
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	if err := wasm.Destroy(); err != nil {
+		t.Fatalf("failed to destroy the transformer. Error: %q", err)
+	}
+	if wasm.runtime != nil || wasm.compiledModule != nil {
+		t.Fatalf("the wasm runtime and the compiled module should have been closed")
+	}
+	if err := wasm.Destroy(); err != nil {
+		t.Fatalf("destroying the transformer again should not fail. Error: %q", err)
+	}
+}
+
+func TestWASMDirectoryDetect(t *testing.T) {
+	wasm, err := newTestWASM(t, testWASMContextDir, map[string]interface{}{"wasmModule": "transformer.wasm"})
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	sourceDir := wasm.Env.GetEnvironmentSource()
+	services, err := wasm.DirectoryDetect(sourceDir)
+	if err != nil {
+		t.Fatalf("failed to detect the services. Error: %q", err)
+	}
+	want := map[string][]transformertypes.Artifact{
+		"svc1": {{
+			Name:  "svc1",
+			Type:  "Service",
+			Paths: map[transformertypes.PathType][]string{"ServiceDirPath": {filepath.Join(sourceDir, "svc1")}},
+		}},
+	}
+	if diff := cmp.Diff(want, services); diff != "" {
+		t.Fatalf("the detected services are different from the expected ones. Differences:\n%s", diff)
+	}
+	if _, err := wasm.DirectoryDetect(t.TempDir()); err == nil {
+		t.Fatalf("should have failed to detect in a directory which is not accessible in the wasm module")
+	}
+}
+
+func TestWASMTransform(t *testing.T) {
+	wasm, err := newTestWASM(t, testWASMContextDir, map[string]interface{}{"wasmModule": "transformer.wasm"})
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	pathMappings, createdArtifacts, err := wasm.Transform(nil, nil)
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	wantPathMappings := []transformertypes.PathMapping{{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  filepath.Join(wasm.Env.TempPath, "out"),
+		DestPath: "out",
+	}}
+	if diff := cmp.Diff(wantPathMappings, pathMappings); diff != "" {
+		t.Fatalf("the path mappings are different from the expected ones. Differences:\n%s", diff)
+	}
+	wantArtifacts := []transformertypes.Artifact{{
+		Name:  "svc1",
+		Type:  "Service",
+		Paths: map[transformertypes.PathType][]string{"ServiceDirPath": {filepath.Join(wasm.Env.GetEnvironmentSource(), "svc1")}},
+	}}
+	if diff := cmp.Diff(wantArtifacts, createdArtifacts); diff != "" {
+		t.Fatalf("the created artifacts are different from the expected ones. Differences:\n%s", diff)
+	}
+}
+
+func TestWASMCall(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	wasm, err := newTestWASM(t, testWASMContextDir, map[string]interface{}{"wasmModule": "transformer.wasm"})
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+
+	t.Run("pass data to the module and read it back", func(t *testing.T) {
+		input := wasmDetectInput{Dir: "/source/foo"}
+		output, err := wasm.call("echo", input)
+		if err != nil {
+			t.Fatalf("failed to call the wasm function. Error: %q", err)
+		}
+		got := wasmDetectInput{}
+		if err := json.Unmarshal(output, &got); err != nil {
+			t.Fatalf("failed to unmarshal the output %s . Error: %q", output, err)
+		}
+		if diff := cmp.Diff(input, got); diff != "" {
+			t.Fatalf("the output is different from the input. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("ask a question", func(t *testing.T) {
+		output, err := wasm.call("ask", qatypes.Problem{ID: "wasm.test.input", Default: "foo"})
+		if err != nil {
+			t.Fatalf("failed to call the wasm function. Error: %q", err)
+		}
+		got := qatypes.Problem{}
+		if err := json.Unmarshal(output, &got); err != nil {
+			t.Fatalf("failed to unmarshal the answered problem %s . Error: %q", output, err)
+		}
+		want := qatypes.Problem{ID: "move2kube.wasm.test.input", Type: qatypes.InputSolutionFormType, Default: "foo", Answer: "foo"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("the answered problem is different from the expected one. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("ask a question without an id", func(t *testing.T) {
+		output, err := wasm.call("ask", qatypes.Problem{Default: "foo"})
+		if err != nil {
+			t.Fatalf("failed to call the wasm function. Error: %q", err)
+		}
+		if len(output) != 0 {
+			t.Fatalf("expected no answer for a question without an id. Actual: %s", output)
+		}
+	})
+
+	t.Run("trap in the module", func(t *testing.T) {
+		if _, err := wasm.call("trap", nil); err == nil {
+			t.Fatalf("should have failed since the wasm function traps")
+		}
+	})
+}
+
+func TestWASMPaths(t *testing.T) {
+	wasm, err := newTestWASM(t, testWASMContextDir, map[string]interface{}{"wasmModule": "transformer.wasm"})
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	sourceDir := wasm.Env.GetEnvironmentSource()
+	contextDir := wasm.Env.GetEnvironmentContext()
+	testcases := []struct {
+		name      string
+		hostPath  string
+		guestPath string
+	}{
+		{name: "relative path", hostPath: "foo/bar", guestPath: "foo/bar"},
+		{name: "source", hostPath: filepath.Join(sourceDir, "foo"), guestPath: "/source/foo"},
+		{name: "context", hostPath: filepath.Join(contextDir, "foo"), guestPath: "/context/foo"},
+		{name: "temp", hostPath: filepath.Join(wasm.Env.TempPath, "foo"), guestPath: "/temp/foo"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			guestPath, err := wasm.toGuestPath(tc.hostPath)
+			if err != nil {
+				t.Fatalf("failed to convert the path %s to the path in the module. Error: %q", tc.hostPath, err)
+			}
+			if guestPath != tc.guestPath {
+				t.Fatalf("the path in the module is incorrect. Expected: %s Actual: %s", tc.guestPath, guestPath)
+			}
+			hostPath, err := wasm.toHostPath(tc.guestPath)
+			if err != nil {
+				t.Fatalf("failed to convert the path %s in the module to the host path. Error: %q", tc.guestPath, err)
+			}
+			if hostPath != tc.hostPath {
+				t.Fatalf("the host path is incorrect. Expected: %s Actual: %s", tc.hostPath, hostPath)
+			}
+		})
+	}
+
+	t.Run("host path which is not mounted in the module", func(t *testing.T) {
+		if _, err := wasm.toGuestPath(t.TempDir()); err == nil {
+			t.Fatalf("should have failed to convert a path which is not mounted in the module")
+		}
+	})
+
+	t.Run("path in the module which is not mounted", func(t *testing.T) {
+		if _, err := wasm.toHostPath("/etc/passwd"); err == nil {
+			t.Fatalf("should have failed to convert a path which is not mounted in the module")
+		}
+	})
+}
@@ -33,7 +33,7 @@ import (
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
-//TODO: Add support for replicaset, cronjob and statefulset
+//TODO: Add support for replicaset
 
 const (
 	// podKind defines Pod Kind
@@ -46,6 +46,10 @@ const (
 	replicationControllerKind string = "ReplicationController"
 	// daemonSetKind defines DaemonSet Kind
 	daemonSetKind string = "DaemonSet"
+	// statefulSetKind defines StatefulSet Kind
+	statefulSetKind string = "StatefulSet"
+	// cronJobKind defines CronJob Kind
+	cronJobKind string = "CronJob"
 )
 
 // Deployment handles all objects like a Deployment
@@ -54,19 +58,31 @@ type Deployment struct {
 
 // getSupportedKinds returns kinds supported by the deployment
 func (d *Deployment) getSupportedKinds() []string {
-	return []string{podKind, jobKind, common.DeploymentKind, deploymentConfigKind, replicationControllerKind}
+	return []string{podKind, jobKind, common.DeploymentKind, deploymentConfigKind, replicationControllerKind, statefulSetKind, cronJobKind}
 }
 
 // createNewResources converts ir to runtime object
 func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
+	serviceMesh := getServiceMesh(targetCluster.Spec)
 	for _, service := range ir.Services {
+		service = addServiceToServiceMesh(service, serviceMesh)
 		var obj runtime.Object
 		if service.Daemon {
 			if !common.IsStringPresent(supportedKinds, daemonSetKind) {
 				logrus.Errorf("Creating Daemonset even though not supported by target cluster.")
 			}
 			obj = d.createDaemonSet(service, targetCluster.Spec)
+		} else if service.Schedule != nil {
+			if common.IsStringPresent(supportedKinds, cronJobKind) {
+				obj = d.createCronJob(service, targetCluster.Spec)
+			} else if common.IsStringPresent(supportedKinds, jobKind) {
+				logrus.Warnf("CronJob is not supported by the target cluster. Creating a Job for the service %s which has to be run using the schedule %s", service.Name, service.Schedule.Cron)
+				obj = d.createJob(service, targetCluster.Spec)
+			} else {
+				logrus.Errorf("Could not find a valid resource type in cluster to create a cronjob. Creating CronJob anyhow")
+				obj = d.createCronJob(service, targetCluster.Spec)
+			}
 		} else if service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
 			if common.IsStringPresent(supportedKinds, jobKind) {
 				obj = d.createJob(service, targetCluster.Spec)
@@ -76,6 +92,11 @@ func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []
 			pod := d.createPod(service, targetCluster.Spec)
 			pod.Spec.RestartPolicy = core.RestartPolicyOnFailure
 			obj = pod
+		} else if service.Stateful {
+			if !common.IsStringPresent(supportedKinds, statefulSetKind) {
+				logrus.Errorf("Creating StatefulSet even though not supported by target cluster.")
+			}
+			obj = d.createStatefulSet(service, ir.Storages, targetCluster.Spec)
 		} else if common.IsStringPresent(supportedKinds, common.DeploymentKind) {
 			obj = d.createDeployment(service, targetCluster.Spec)
 		} else if common.IsStringPresent(supportedKinds, deploymentConfigKind) {
@@ -101,6 +122,15 @@ func (d *Deployment) convertToClusterSupportedKinds(obj runtime.Object, supporte
 	if d1, ok := lobj.(*apps.DaemonSet); ok {
 		return []runtime.Object{d1}, true
 	}
+	if d1, ok := lobj.(*apps.StatefulSet); ok {
+		return []runtime.Object{d1}, true
+	}
+	if d1, ok := lobj.(*batch.CronJob); ok {
+		if !common.IsStringPresent(supportedKinds, cronJobKind) && common.IsStringPresent(supportedKinds, jobKind) {
+			return []runtime.Object{d.cronJobToJob(*d1)}, true
+		}
+		return []runtime.Object{d1}, true
+	}
 	if d1, ok := lobj.(*core.Pod); ok && (d1.Spec.RestartPolicy == core.RestartPolicyOnFailure || d1.Spec.RestartPolicy == core.RestartPolicyNever) {
 		if common.IsStringPresent(supportedKinds, jobKind) {
 			return []runtime.Object{d.podToJob(*d1, targetCluster.Spec)}, true
@@ -242,6 +272,54 @@ func (d *Deployment) createDaemonSet(service irtypes.Service, cluster collecttyp
 	return &pod
 }
 
+// createStatefulSet creates a StatefulSet with volume claim templates for the PVC backed volumes of the service
+func (d *Deployment) createStatefulSet(service irtypes.Service, storages []irtypes.Storage, cluster collecttypes.ClusterMetadataSpec) *apps.StatefulSet {
+	podSpec := service.PodSpec
+	podSpec.RestartPolicy = core.RestartPolicyAlways
+	meta := metav1.ObjectMeta{
+		Name:        service.Name,
+		Labels:      getPodLabels(service.Name, service.Networks),
+		Annotations: getAnnotations(service),
+	}
+	volumeClaimTemplates := []core.PersistentVolumeClaim{}
+	claimStorages := getVolumeClaimStorages(service, storages)
+	volumes := []core.Volume{}
+	for _, volume := range podSpec.Volumes {
+		st, ok := claimStorages[volume.Name]
+		if !ok || cluster.GetSupportedVersions(string(irtypes.PVCKind)) == nil {
+			volumes = append(volumes, volume)
+			continue
+		}
+		volumeClaimTemplates = append(volumeClaimTemplates, getVolumeClaimTemplate(volume.Name, st))
+	}
+	podSpec.Volumes = volumes
+	podSpec = d.convertVolumesKindsByPolicy(podSpec, cluster)
+	replicas := int32(service.Replicas)
+	if replicas == 0 {
+		replicas = 1
+	}
+	logrus.Debugf("Created StatefulSet for %s", service.Name)
+	return &apps.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       statefulSetKind,
+			APIVersion: apps.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: apps.StatefulSetSpec{
+			Replicas:    replicas,
+			ServiceName: getHeadlessServiceName(service.Name),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getServiceLabels(meta.Name),
+			},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta,
+				Spec:       podSpec,
+			},
+			VolumeClaimTemplates: volumeClaimTemplates,
+		},
+	}
+}
+
 func (d *Deployment) createJob(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *batch.Job {
 	podspec := service.PodSpec
 	podspec = d.convertVolumesKindsByPolicy(podspec, cluster)
@@ -267,6 +345,43 @@ func (d *Deployment) createJob(service irtypes.Service, cluster collecttypes.Clu
 	return &pod
 }
 
+func (d *Deployment) createCronJob(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *batch.CronJob {
+	podspec := service.PodSpec
+	podspec = d.convertVolumesKindsByPolicy(podspec, cluster)
+	if podspec.RestartPolicy != core.RestartPolicyNever {
+		podspec.RestartPolicy = core.RestartPolicyOnFailure
+	}
+	meta := metav1.ObjectMeta{
+		Name:        service.Name,
+		Labels:      getPodLabels(service.Name, service.Networks),
+		Annotations: getAnnotations(service),
+	}
+	cronJob := batch.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       cronJobKind,
+			APIVersion: batch.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: batch.CronJobSpec{
+			Schedule:                   service.Schedule.Cron,
+			ConcurrencyPolicy:          service.Schedule.ConcurrencyPolicy,
+			SuccessfulJobsHistoryLimit: service.Schedule.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     service.Schedule.FailedJobsHistoryLimit,
+			JobTemplate: batch.JobTemplateSpec{
+				ObjectMeta: meta,
+				Spec: batch.JobSpec{
+					Template: core.PodTemplateSpec{
+						ObjectMeta: meta,
+						Spec:       podspec,
+					},
+				},
+			},
+		},
+	}
+	logrus.Debugf("Created CronJob for %s", service.Name)
+	return &cronJob
+}
+
 // Conversions section
 
 func (d *Deployment) toDeploymentConfig(meta metav1.ObjectMeta, podspec core.PodSpec, replicas int32, cluster collecttypes.ClusterMetadataSpec) *okdappsv1.DeploymentConfig {
@@ -371,6 +486,19 @@ func (d *Deployment) podToJob(obj core.Pod, cluster collecttypes.ClusterMetadata
 	return &pod
 }
 
+// cronJobToJob converts the CronJob to a Job. The schedule is lost, so the Job has to be run using some other scheduler.
+func (d *Deployment) cronJobToJob(obj batch.CronJob) *batch.Job {
+	logrus.Warnf("CronJob is not supported by the target cluster. Converting the CronJob %s to a Job which runs once. It has to be run using the schedule %s by some other scheduler", obj.Name, obj.Spec.Schedule)
+	return &batch.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       jobKind,
+			APIVersion: batch.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: obj.ObjectMeta,
+		Spec:       obj.Spec.JobTemplate.Spec,
+	}
+}
+
 func (d *Deployment) toPod(meta metav1.ObjectMeta, podspec core.PodSpec, restartPolicy core.RestartPolicy, cluster collecttypes.ClusterMetadataSpec) *core.Pod {
 	podspec = d.convertVolumesKindsByPolicy(podspec, cluster)
 	podspec.RestartPolicy = restartPolicy
@@ -424,3 +552,43 @@ func getMatchingVolume(vm core.VolumeMount, vList []core.Volume) core.Volume {
 	}
 	return core.Volume{}
 }
+
+// getVolumeClaimStorages returns the PVC storages used by the volumes of the service
+func getVolumeClaimStorages(service irtypes.Service, storages []irtypes.Storage) map[string]irtypes.Storage {
+	claimStorages := map[string]irtypes.Storage{}
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		for _, st := range storages {
+			if st.StorageType == irtypes.PVCKind && st.Name == volume.PersistentVolumeClaim.ClaimName {
+				claimStorages[volume.Name] = st
+				break
+			}
+		}
+	}
+	return claimStorages
+}
+
+// getVolumeClaimTemplate returns the volume claim template for the volume using the spec of the PVC storage
+func getVolumeClaimTemplate(volumeName string, st irtypes.Storage) core.PersistentVolumeClaim {
+	spec := st.PersistentVolumeClaimSpec
+	if len(spec.AccessModes) == 0 {
+		spec.AccessModes = []core.PersistentVolumeAccessMode{core.ReadWriteOnce}
+	}
+	if _, ok := spec.Resources.Requests[core.ResourceStorage]; !ok {
+		requests := core.ResourceList{}
+		for k, v := range spec.Resources.Requests {
+			requests[k] = v
+		}
+		requests[core.ResourceStorage] = common.DefaultPVCSize
+		spec.Resources.Requests = requests
+	}
+	return core.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        volumeName,
+			Annotations: st.Annotations,
+		},
+		Spec: spec,
+	}
+}
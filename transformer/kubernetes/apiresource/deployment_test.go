@@ -0,0 +1,216 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func getStatefulTestService() (irtypes.Service, []irtypes.Storage) {
+	service := irtypes.NewServiceWithName("db")
+	service.Stateful = true
+	service.Containers = []core.Container{{
+		Name:  "db",
+		Image: "postgres:13",
+		VolumeMounts: []core.VolumeMount{
+			{Name: "data", MountPath: "/var/lib/postgresql/data"},
+			{Name: "config", MountPath: "/etc/postgresql"},
+		},
+	}}
+	service.Volumes = []core.Volume{
+		{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "dbdata"}}},
+		{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: "dbconfig"}}}},
+	}
+	storages := []irtypes.Storage{
+		{Name: "dbdata", StorageType: irtypes.PVCKind, PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{
+			Resources: core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: resource.MustParse("5Gi")}},
+		}},
+		{Name: "dbconfig", StorageType: irtypes.ConfigMapKind},
+	}
+	return service, storages
+}
+
+func TestCreateStatefulSet(t *testing.T) {
+	t.Run("cluster which supports PVCs", func(t *testing.T) {
+		service, storages := getStatefulTestService()
+		cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{
+			string(irtypes.PVCKind):       {"v1"},
+			string(irtypes.ConfigMapKind): {"v1"},
+		}}
+		statefulSet := (&Deployment{}).createStatefulSet(service, storages, cluster)
+		if statefulSet.Kind != statefulSetKind || statefulSet.Name != "db" {
+			t.Fatalf("expected a StatefulSet named db. Actual: %s %s", statefulSet.Kind, statefulSet.Name)
+		}
+		if statefulSet.Spec.Replicas != 1 {
+			t.Fatalf("the replicas should default to 1. Actual: %d", statefulSet.Spec.Replicas)
+		}
+		if statefulSet.Spec.ServiceName != "db-headless" {
+			t.Fatalf("the StatefulSet should use the headless service. Actual: %s", statefulSet.Spec.ServiceName)
+		}
+		if statefulSet.Spec.Template.Spec.RestartPolicy != core.RestartPolicyAlways {
+			t.Fatalf("the restart policy should be Always. Actual: %s", statefulSet.Spec.Template.Spec.RestartPolicy)
+		}
+		wantTemplates := []core.PersistentVolumeClaim{{
+			ObjectMeta: metav1.ObjectMeta{Name: "data"},
+			Spec: core.PersistentVolumeClaimSpec{
+				AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+				Resources:   core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: resource.MustParse("5Gi")}},
+			},
+		}}
+		if diff := cmp.Diff(wantTemplates, statefulSet.Spec.VolumeClaimTemplates); diff != "" {
+			t.Fatalf("the volume claim templates are incorrect. Differences:\n%s", diff)
+		}
+		wantVolumes := []core.Volume{service.Volumes[1]}
+		if diff := cmp.Diff(wantVolumes, statefulSet.Spec.Template.Spec.Volumes); diff != "" {
+			t.Fatalf("only the volumes not backed by PVCs should be in the pod spec. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("cluster which does not support PVCs", func(t *testing.T) {
+		service, storages := getStatefulTestService()
+		service.Replicas = 3
+		cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{string(irtypes.ConfigMapKind): {"v1"}}}
+		statefulSet := (&Deployment{}).createStatefulSet(service, storages, cluster)
+		if statefulSet.Spec.Replicas != 3 {
+			t.Fatalf("the replicas of the service should be used. Actual: %d", statefulSet.Spec.Replicas)
+		}
+		if len(statefulSet.Spec.VolumeClaimTemplates) != 0 {
+			t.Fatalf("there should be no volume claim templates when the cluster does not support PVCs. Actual: %+v", statefulSet.Spec.VolumeClaimTemplates)
+		}
+		volumes := statefulSet.Spec.Template.Spec.Volumes
+		if len(volumes) != 2 || volumes[0].Name != "data" || volumes[0].EmptyDir == nil {
+			t.Fatalf("the PVC volume should have been converted to an emptyDir. Actual: %+v", volumes)
+		}
+	})
+}
+
+func TestGetVolumeClaimTemplate(t *testing.T) {
+	otherRequests := core.ResourceList{core.ResourceName("example.com/iops"): resource.MustParse("100")}
+	testcases := []struct {
+		name    string
+		storage irtypes.Storage
+		want    core.PersistentVolumeClaim
+	}{
+		{
+			name:    "defaults",
+			storage: irtypes.Storage{Name: "data", StorageType: irtypes.PVCKind},
+			want: core.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "vol"},
+				Spec: core.PersistentVolumeClaimSpec{
+					AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+					Resources:   core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: common.DefaultPVCSize}},
+				},
+			},
+		},
+		{
+			name: "spec of the storage",
+			storage: irtypes.Storage{
+				Name:        "data",
+				StorageType: irtypes.PVCKind,
+				Annotations: map[string]string{"key": "value"},
+				PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{
+					AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteMany},
+					Resources:   core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: resource.MustParse("1Gi")}},
+				},
+			},
+			want: core.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "vol", Annotations: map[string]string{"key": "value"}},
+				Spec: core.PersistentVolumeClaimSpec{
+					AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteMany},
+					Resources:   core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: resource.MustParse("1Gi")}},
+				},
+			},
+		},
+		{
+			name: "other requests without storage",
+			storage: irtypes.Storage{
+				Name:                      "data",
+				StorageType:               irtypes.PVCKind,
+				PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{Resources: core.ResourceRequirements{Requests: otherRequests}},
+			},
+			want: core.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "vol"},
+				Spec: core.PersistentVolumeClaimSpec{
+					AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+					Resources: core.ResourceRequirements{Requests: core.ResourceList{
+						core.ResourceName("example.com/iops"): resource.MustParse("100"),
+						core.ResourceStorage:                  common.DefaultPVCSize,
+					}},
+				},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := getVolumeClaimTemplate("vol", tc.storage)
+			if diff := cmp.Diff(tc.want, actual); diff != "" {
+				t.Fatalf("the volume claim template is incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+	if _, ok := otherRequests[core.ResourceStorage]; ok {
+		t.Fatalf("the requests of the storage should not have been modified")
+	}
+}
+
+func TestCronJobVersion(t *testing.T) {
+	service := irtypes.NewServiceWithName("report")
+	service.Containers = []core.Container{{Name: "report", Image: "report", Command: []string{"/bin/sh", "-c", "/app/report.sh"}}}
+	successfulJobsHistoryLimit, failedJobsHistoryLimit, suspend := int32(5), int32(2), false
+	service.Schedule = &irtypes.Schedule{Cron: "@daily", ConcurrencyPolicy: batch.ForbidConcurrent, SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit, FailedJobsHistoryLimit: &failedJobsHistoryLimit}
+	wantSpec := batchv1.CronJobSpec{Schedule: "@daily", ConcurrencyPolicy: batchv1.ForbidConcurrent, Suspend: &suspend, SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit, FailedJobsHistoryLimit: &failedJobsHistoryLimit}
+
+	t.Run("cluster which supports batch/v1 CronJobs", func(t *testing.T) {
+		cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{cronJobKind: {"batch/v1", "batch/v1beta1"}}}
+		obj, err := k8sschema.ConvertToSupportedVersion((&Deployment{}).createCronJob(service, cluster), cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the CronJob. Error: %q", err)
+		}
+		cronJob, ok := obj.(*batchv1.CronJob)
+		if !ok {
+			t.Fatalf("expected the first supported version batch/v1 to be used. Actual: %T", obj)
+		}
+		actualSpec := cronJob.Spec
+		actualSpec.JobTemplate = batchv1.JobTemplateSpec{}
+		if diff := cmp.Diff(wantSpec, actualSpec); diff != "" {
+			t.Fatalf("the CronJob spec is incorrect. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("cluster which only supports batch/v1beta1 CronJobs", func(t *testing.T) {
+		cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{cronJobKind: {"batch/v1beta1"}}}
+		obj, err := k8sschema.ConvertToSupportedVersion((&Deployment{}).createCronJob(service, cluster), cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the CronJob. Error: %q", err)
+		}
+		if _, ok := obj.(*batchv1beta1.CronJob); !ok {
+			t.Fatalf("expected batch/v1beta1 to be used. Actual: %T", obj)
+		}
+	})
+}
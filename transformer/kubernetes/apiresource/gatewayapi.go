@@ -0,0 +1,367 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	// grpcPortNamePrefix is the prefix of the names of the ports which serve gRPC
+	grpcPortNamePrefix = "grpc"
+	// defaultGRPCPort is the port commonly used by gRPC servers
+	defaultGRPCPort = 50051
+	// defaultGatewayClassName is the default gateway class of the gateway
+	defaultGatewayClassName = "default"
+)
+
+// createGatewayAPIResources creates a single gateway for all services along with the HTTPRoutes and GRPCRoutes of the services
+func (d *Service) createGatewayAPIResources(ir irtypes.EnhancedIR, targetClusterSpec collecttypes.ClusterMetadataSpec) []runtime.Object {
+	grpcRouteSupported := isGatewayAPISupported(targetClusterSpec, grpcRouteKind)
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	gatewayName := ir.Name
+	routes := []runtime.Object{}
+	hostTLSs := []hostTLS{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		backendServiceName := service.BackendServiceName
+		if service.BackendServiceName == "" {
+			backendServiceName = service.Name
+		}
+		hostPrefixes := []string{}
+		httpRules := map[string][]gatewayv1beta1.HTTPRouteRule{}  //[hostprefix]
+		grpcRules := map[string][]gatewayv1alpha2.GRPCRouteRule{} //[hostprefix]
+		servicePorts, servicePortHostPrefixes, relPaths, _ := d.getExposeInfo(service)
+		for i, servicePort := range servicePorts {
+			if relPaths[i] == "" {
+				continue
+			}
+			hostPrefix := servicePortHostPrefixes[i]
+			if _, ok := httpRules[hostPrefix]; !ok {
+				if _, ok := grpcRules[hostPrefix]; !ok {
+					hostPrefixes = append(hostPrefixes, hostPrefix)
+				}
+			}
+			port := gatewayv1beta1.PortNumber(servicePort.Port)
+			backendRef := gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: gatewayv1beta1.ObjectName(backendServiceName), Port: &port}}
+			if isGRPCPort(servicePort) {
+				if grpcRouteSupported {
+					grpcRules[hostPrefix] = append(grpcRules[hostPrefix], gatewayv1alpha2.GRPCRouteRule{BackendRefs: []gatewayv1alpha2.GRPCBackendRef{{BackendRef: backendRef}}})
+					continue
+				}
+				logrus.Warnf("The cluster does not support %s. Using a %s for the gRPC port %d of the service %s", grpcRouteKind, httpRouteKind, servicePort.Port, service.Name)
+			}
+			httpRules[hostPrefix] = append(httpRules[hostPrefix], newHTTPRouteRule(relPaths[i], backendRef))
+		}
+		if len(hostPrefixes) == 0 {
+			continue
+		}
+		host := d.getIngressHost(ir.Name, targetClusterSpec)
+		if serviceTLS := d.getTLSConfig(service, gatewayKind, targetClusterSpec); serviceTLS.secretName != "" {
+			hostTLSs = addHostTLS(hostTLSs, d.getExposedHostnames(service, ir.Name, targetClusterSpec), serviceTLS.secretName)
+		}
+		for _, hostPrefix := range hostPrefixes {
+			routeName := service.Name
+			if len(hostPrefixes) > 1 && hostPrefix != "" {
+				routeName = common.MakeStringDNSNameCompliant(service.Name + "-" + hostPrefix)
+			}
+			hostname := host
+			if hostPrefix != "" {
+				hostname = hostPrefix + "." + hostname
+			}
+			if rules, ok := httpRules[hostPrefix]; ok {
+				routes = append(routes, newHTTPRoute(routeName, gatewayName, hostname, rules))
+			}
+			if rules, ok := grpcRules[hostPrefix]; ok {
+				routes = append(routes, newGRPCRoute(routeName, gatewayName, hostname, rules))
+			}
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+	return append([]runtime.Object{d.createGateway(gatewayName, hostTLSs)}, routes...)
+}
+
+// createGateway creates a gateway which accepts http traffic, and https traffic for the hosts which have TLS secrets
+func (d *Service) createGateway(name string, hostTLSs []hostTLS) *gatewayv1beta1.Gateway {
+	gatewayClassName := qaengine.FetchStringAnswer(common.ConfigIngressGatewayClassKey, "Provide the gateway class of the gateway", []string{"The gateway class of the Gateway API controller installed in the cluster"}, defaultGatewayClassName)
+	listeners := []gatewayv1beta1.Listener{{
+		Name:     "http",
+		Port:     80,
+		Protocol: gatewayv1beta1.HTTPProtocolType,
+	}}
+	for _, h := range hostTLSs {
+		secretGroup := gatewayv1beta1.Group(core.GroupName)
+		secretKind := gatewayv1beta1.Kind(string(irtypes.SecretKind))
+		tlsMode := gatewayv1beta1.TLSModeTerminate
+		listener := gatewayv1beta1.Listener{
+			Name:     "https",
+			Port:     443,
+			Protocol: gatewayv1beta1.HTTPSProtocolType,
+			TLS: &gatewayv1beta1.GatewayTLSConfig{
+				Mode:            &tlsMode,
+				CertificateRefs: []gatewayv1beta1.SecretObjectReference{{Group: &secretGroup, Kind: &secretKind, Name: gatewayv1beta1.ObjectName(h.secretName)}},
+			},
+		}
+		if h.hostname != "" {
+			hostname := gatewayv1beta1.Hostname(h.hostname)
+			listener.Name = gatewayv1beta1.SectionName(common.MakeStringDNSLabelNameCompliant("https-" + h.hostname))
+			listener.Hostname = &hostname
+		}
+		listeners = append(listeners, listener)
+	}
+	return &gatewayv1beta1.Gateway{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       gatewayKind,
+			APIVersion: gatewayv1beta1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(name),
+		},
+		Spec: gatewayv1beta1.GatewaySpec{
+			GatewayClassName: gatewayv1beta1.ObjectName(gatewayClassName),
+			Listeners:        listeners,
+		},
+	}
+}
+
+// isGatewayAPISupported returns true if the cluster supports all the kinds in the gateway api group.
+// The group is checked since other api groups, like the one of istio, also have a Gateway kind.
+func isGatewayAPISupported(targetClusterSpec collecttypes.ClusterMetadataSpec, kinds ...string) bool {
+	for _, kind := range kinds {
+		supported := false
+		for _, version := range targetClusterSpec.GetSupportedVersions(kind) {
+			gv, err := schema.ParseGroupVersion(version)
+			if err != nil {
+				logrus.Debugf("Unable to parse group version %s : %s", version, err)
+				continue
+			}
+			if gv.Group == gatewayv1beta1.GroupName {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return false
+		}
+	}
+	return true
+}
+
+// convertGatewayAPIToClusterSupportedKinds converts the gateway api resources which the cluster does not support
+func (d *Service) convertGatewayAPIToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, targetClusterSpec collecttypes.ClusterMetadataSpec) ([]runtime.Object, bool) {
+	var objectMeta metav1.ObjectMeta
+	var httpRoute *gatewayv1beta1.HTTPRoute
+	switch gatewayObj := obj.(type) {
+	case *gatewayv1beta1.Gateway:
+		objectMeta = gatewayObj.ObjectMeta
+	case *gatewayv1alpha2.Gateway:
+		objectMeta = gatewayObj.ObjectMeta
+	case *gatewayv1beta1.HTTPRoute:
+		objectMeta = gatewayObj.ObjectMeta
+		httpRoute = gatewayObj
+	case *gatewayv1alpha2.HTTPRoute:
+		objectMeta = gatewayObj.ObjectMeta
+		v1beta1HTTPRoute := gatewayv1beta1.HTTPRoute(*gatewayObj)
+		httpRoute = &v1beta1HTTPRoute
+	case *gatewayv1alpha2.GRPCRoute:
+		objectMeta = gatewayObj.ObjectMeta
+	default:
+		return nil, false
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if isGatewayAPISupported(targetClusterSpec, kind) {
+		return []runtime.Object{obj}, true
+	}
+	if httpRoute == nil {
+		logrus.Warnf("The cluster does not support %s. Ignoring the %s %s", kind, kind, objectMeta.Name)
+		return nil, true
+	}
+	ingress := d.httpRouteToIngress(*httpRoute)
+	if common.IsStringPresent(supportedKinds, routeKind) {
+		return d.ingressToRoute(ingress), true
+	}
+	if common.IsStringPresent(supportedKinds, common.IngressKind) {
+		return []runtime.Object{&ingress}, true
+	}
+	return d.ingressToService(ingress), true
+}
+
+func (d *Service) httpRouteToIngress(httpRoute gatewayv1beta1.HTTPRoute) networking.Ingress {
+	pathType := networking.PathTypePrefix
+	paths := []networking.HTTPIngressPath{}
+	for _, rule := range httpRoute.Spec.Rules {
+		path := "/"
+		for _, match := range rule.Matches {
+			if match.Path != nil && match.Path.Value != nil {
+				path = *match.Path.Value
+				break
+			}
+		}
+		for _, backendRef := range rule.BackendRefs {
+			backendPort := networking.ServiceBackendPort{}
+			if backendRef.Port != nil {
+				backendPort.Number = int32(*backendRef.Port)
+			}
+			paths = append(paths, networking.HTTPIngressPath{
+				Path:     path,
+				PathType: &pathType,
+				Backend: networking.IngressBackend{
+					Service: &networking.IngressServiceBackend{
+						Name: string(backendRef.Name),
+						Port: backendPort,
+					},
+				},
+			})
+		}
+	}
+	hostnames := httpRoute.Spec.Hostnames
+	if len(hostnames) == 0 {
+		hostnames = []gatewayv1beta1.Hostname{""}
+	}
+	rules := []networking.IngressRule{}
+	for _, hostname := range hostnames {
+		rules = append(rules, networking.IngressRule{
+			Host: string(hostname),
+			IngressRuleValue: networking.IngressRuleValue{
+				HTTP: &networking.HTTPIngressRuleValue{Paths: paths},
+			},
+		})
+	}
+	return networking.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       common.IngressKind,
+			APIVersion: networking.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: httpRoute.ObjectMeta,
+		Spec:       networking.IngressSpec{Rules: rules},
+	}
+}
+
+func (d *Service) ingressToGatewayAPI(ingress networking.Ingress) []runtime.Object {
+	hostTLSs := []hostTLS{}
+	for _, tls := range ingress.Spec.TLS {
+		hosts := tls.Hosts
+		if len(hosts) == 0 {
+			hosts = []string{""}
+		}
+		hostTLSs = addHostTLS(hostTLSs, hosts, tls.SecretName)
+	}
+	objs := []runtime.Object{d.createGateway(ingress.Name, hostTLSs)}
+	for i, ingressRule := range ingress.Spec.Rules {
+		if ingressRule.HTTP == nil {
+			continue
+		}
+		rules := []gatewayv1beta1.HTTPRouteRule{}
+		for _, path := range ingressRule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			backendRef := gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: gatewayv1beta1.ObjectName(path.Backend.Service.Name)}}
+			if path.Backend.Service.Port.Number != 0 {
+				port := gatewayv1beta1.PortNumber(path.Backend.Service.Port.Number)
+				backendRef.Port = &port
+			}
+			rules = append(rules, newHTTPRouteRule(path.Path, backendRef))
+		}
+		routeName := ingress.Name
+		if len(ingress.Spec.Rules) > 1 {
+			routeName = fmt.Sprintf("%s-%d", ingress.Name, i)
+		}
+		objs = append(objs, newHTTPRoute(routeName, ingress.Name, ingressRule.Host, rules))
+	}
+	return objs
+}
+
+func newHTTPRouteRule(path string, backendRef gatewayv1beta1.BackendRef) gatewayv1beta1.HTTPRouteRule {
+	if path == "" {
+		path = "/"
+	}
+	pathType := gatewayv1beta1.PathMatchPathPrefix
+	return gatewayv1beta1.HTTPRouteRule{
+		Matches:     []gatewayv1beta1.HTTPRouteMatch{{Path: &gatewayv1beta1.HTTPPathMatch{Type: &pathType, Value: &path}}},
+		BackendRefs: []gatewayv1beta1.HTTPBackendRef{{BackendRef: backendRef}},
+	}
+}
+
+func newHTTPRoute(name, gatewayName, hostname string, rules []gatewayv1beta1.HTTPRouteRule) *gatewayv1beta1.HTTPRoute {
+	route := &gatewayv1beta1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       httpRouteKind,
+			APIVersion: gatewayv1beta1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(name),
+		},
+		Spec: gatewayv1beta1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{{Name: gatewayv1beta1.ObjectName(gatewayName)}}},
+			Rules:           rules,
+		},
+	}
+	if hostname != "" {
+		route.Spec.Hostnames = []gatewayv1beta1.Hostname{gatewayv1beta1.Hostname(hostname)}
+	}
+	return route
+}
+
+func newGRPCRoute(name, gatewayName, hostname string, rules []gatewayv1alpha2.GRPCRouteRule) *gatewayv1alpha2.GRPCRoute {
+	route := &gatewayv1alpha2.GRPCRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       grpcRouteKind,
+			APIVersion: gatewayv1alpha2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(name),
+		},
+		Spec: gatewayv1alpha2.GRPCRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{{Name: gatewayv1beta1.ObjectName(gatewayName)}}},
+			Rules:           rules,
+		},
+	}
+	if hostname != "" {
+		route.Spec.Hostnames = []gatewayv1beta1.Hostname{gatewayv1beta1.Hostname(hostname)}
+	}
+	return route
+}
+
+// isGRPCPort returns true if the port serves gRPC
+func isGRPCPort(servicePort core.ServicePort) bool {
+	return strings.HasPrefix(strings.ToLower(servicePort.Name), grpcPortNamePrefix) || servicePort.Port == defaultGRPCPort
+}
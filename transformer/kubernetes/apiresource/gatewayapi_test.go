@@ -0,0 +1,321 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func getGatewayAPITestIR() irtypes.EnhancedIR {
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	for name, forwarding := range map[string]irtypes.ServiceToPodPortForwarding{
+		"web":      {ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}, ServiceRelPath: "/"},
+		"api":      {ServicePort: networking.ServiceBackendPort{Name: "grpc-api", Number: 9090}, PodPort: networking.ServiceBackendPort{Number: 9090}, ServiceRelPath: "/api"},
+		"internal": {ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}},
+	} {
+		service := irtypes.NewServiceWithName(name)
+		service.Containers = []core.Container{{Name: name, Image: name}}
+		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{forwarding}
+		ir.Services[name] = service
+	}
+	return irtypes.NewEnhancedIRFromIR(ir)
+}
+
+func getGatewayAPITestCluster(kinds map[string][]string) collecttypes.ClusterMetadataSpec {
+	cluster := collecttypes.ClusterMetadataSpec{Host: "example.com", APIKindVersionMap: map[string][]string{"Service": {"v1"}}}
+	for kind, versions := range kinds {
+		cluster.APIKindVersionMap[kind] = versions
+	}
+	return cluster
+}
+
+func TestIsGatewayAPISupported(t *testing.T) {
+	gatewayAPIVersion := []string{"gateway.networking.k8s.io/v1alpha2"}
+	istioVersion := []string{"networking.istio.io/v1beta1"}
+	testcases := []struct {
+		name  string
+		kinds map[string][]string
+		want  bool
+	}{
+		{name: "gateway api", kinds: map[string][]string{gatewayKind: gatewayAPIVersion, httpRouteKind: gatewayAPIVersion}, want: true},
+		{name: "istio gateway", kinds: map[string][]string{gatewayKind: istioVersion, httpRouteKind: gatewayAPIVersion}, want: false},
+		{name: "istio and gateway api", kinds: map[string][]string{gatewayKind: append(istioVersion, gatewayAPIVersion...), httpRouteKind: gatewayAPIVersion}, want: true},
+		{name: "no routes", kinds: map[string][]string{gatewayKind: gatewayAPIVersion}, want: false},
+		{name: "invalid version", kinds: map[string][]string{gatewayKind: {"gateway.networking.k8s.io/v1alpha2/extra"}, httpRouteKind: gatewayAPIVersion}, want: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := isGatewayAPISupported(getGatewayAPITestCluster(tc.kinds), gatewayKind, httpRouteKind); actual != tc.want {
+				t.Fatalf("Expected %t. Actual: %t", tc.want, actual)
+			}
+		})
+	}
+}
+
+func TestCreateGatewayAPIResources(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	gatewayAPIVersion := []string{"gateway.networking.k8s.io/v1alpha2"}
+	pathType := gatewayv1beta1.PathMatchPathPrefix
+	webPath := "/"
+	webPort := gatewayv1beta1.PortNumber(8080)
+	wantWebRoute := gatewayv1beta1.HTTPRouteSpec{
+		CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{{Name: "myproject"}}},
+		Hostnames:       []gatewayv1beta1.Hostname{"example.com"},
+		Rules: []gatewayv1beta1.HTTPRouteRule{{
+			Matches:     []gatewayv1beta1.HTTPRouteMatch{{Path: &gatewayv1beta1.HTTPPathMatch{Type: &pathType, Value: &webPath}}},
+			BackendRefs: []gatewayv1beta1.HTTPBackendRef{{BackendRef: gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "web", Port: &webPort}}}},
+		}},
+	}
+	apiPort := gatewayv1beta1.PortNumber(9090)
+
+	t.Run("cluster which supports GRPCRoutes", func(t *testing.T) {
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: gatewayAPIVersion, httpRouteKind: gatewayAPIVersion, grpcRouteKind: gatewayAPIVersion})
+		objs := (&Service{}).createGatewayAPIResources(getGatewayAPITestIR(), cluster)
+		if len(objs) != 3 {
+			t.Fatalf("Expected a gateway, an HTTPRoute and a GRPCRoute. Actual: %+v", objs)
+		}
+		gateway, ok := objs[0].(*gatewayv1beta1.Gateway)
+		if !ok || gateway.Name != "myproject" || len(gateway.Spec.Listeners) != 1 || gateway.Spec.Listeners[0].Protocol != gatewayv1beta1.HTTPProtocolType {
+			t.Fatalf("Expected a gateway with an http listener. Actual: %+v", objs[0])
+		}
+		grpcRoute, ok := objs[1].(*gatewayv1alpha2.GRPCRoute)
+		if !ok || grpcRoute.Name != "api" {
+			t.Fatalf("Expected a GRPCRoute for the service api. Actual: %+v", objs[1])
+		}
+		wantGRPCRoute := gatewayv1alpha2.GRPCRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{{Name: "myproject"}}},
+			Hostnames:       []gatewayv1beta1.Hostname{"example.com"},
+			Rules:           []gatewayv1alpha2.GRPCRouteRule{{BackendRefs: []gatewayv1alpha2.GRPCBackendRef{{BackendRef: gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "api", Port: &apiPort}}}}}},
+		}
+		if diff := cmp.Diff(wantGRPCRoute, grpcRoute.Spec); diff != "" {
+			t.Fatalf("The GRPCRoute is incorrect. Differences:\n%s", diff)
+		}
+		httpRoute, ok := objs[2].(*gatewayv1beta1.HTTPRoute)
+		if !ok || httpRoute.Name != "web" {
+			t.Fatalf("Expected an HTTPRoute for the service web. Actual: %+v", objs[2])
+		}
+		if diff := cmp.Diff(wantWebRoute, httpRoute.Spec); diff != "" {
+			t.Fatalf("The HTTPRoute is incorrect. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("cluster which does not support GRPCRoutes", func(t *testing.T) {
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: gatewayAPIVersion, httpRouteKind: gatewayAPIVersion})
+		objs := (&Service{}).createGatewayAPIResources(getGatewayAPITestIR(), cluster)
+		if len(objs) != 3 {
+			t.Fatalf("Expected a gateway and two HTTPRoutes. Actual: %+v", objs)
+		}
+		for _, obj := range objs[1:] {
+			if _, ok := obj.(*gatewayv1beta1.HTTPRoute); !ok {
+				t.Fatalf("Expected HTTPRoutes for the services. Actual: %+v", obj)
+			}
+		}
+	})
+
+	t.Run("no exposed services", func(t *testing.T) {
+		ir := getGatewayAPITestIR()
+		delete(ir.Services, "web")
+		delete(ir.Services, "api")
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: gatewayAPIVersion, httpRouteKind: gatewayAPIVersion})
+		if objs := (&Service{}).createGatewayAPIResources(ir, cluster); len(objs) != 0 {
+			t.Fatalf("Expected no gateway when no services are exposed. Actual: %+v", objs)
+		}
+	})
+}
+
+func TestConvertGatewayAPIToClusterSupportedKinds(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	gatewayAPIVersion := []string{"gateway.networking.k8s.io/v1alpha2"}
+	backendRef := gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "web"}}
+	httpRoute := newHTTPRoute("web", "myproject", "example.com", []gatewayv1beta1.HTTPRouteRule{newHTTPRouteRule("/app", backendRef)})
+	grpcRoute := newGRPCRoute("api", "myproject", "", nil)
+	istioGateway := &k8sschema.IstioGateway{TypeMeta: metav1.TypeMeta{Kind: k8sschema.IstioGatewayKind}}
+
+	t.Run("supported routes", func(t *testing.T) {
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: gatewayAPIVersion, httpRouteKind: gatewayAPIVersion, grpcRouteKind: gatewayAPIVersion})
+		for _, obj := range []interface{}{httpRoute, grpcRoute} {
+			objs, ok := (&Service{}).convertGatewayAPIToClusterSupportedKinds(obj.(interface {
+				DeepCopyObject() runtime.Object
+			}).DeepCopyObject(), nil, cluster)
+			if !ok || len(objs) != 1 {
+				t.Fatalf("Expected the route to be kept. Actual: %+v", objs)
+			}
+		}
+	})
+
+	t.Run("HTTPRoute on a cluster which supports ingress", func(t *testing.T) {
+		cluster := getGatewayAPITestCluster(map[string][]string{common.IngressKind: {"networking.k8s.io/v1"}, gatewayKind: {"networking.istio.io/v1beta1"}})
+		objs, ok := (&Service{}).convertGatewayAPIToClusterSupportedKinds(httpRoute, []string{common.IngressKind, gatewayKind}, cluster)
+		if !ok || len(objs) != 1 {
+			t.Fatalf("Expected the HTTPRoute to be converted to an ingress. Actual: %+v", objs)
+		}
+		ingress, ok := objs[0].(*networking.Ingress)
+		if !ok {
+			t.Fatalf("Expected an ingress. Actual: %T", objs[0])
+		}
+		pathType := networking.PathTypePrefix
+		want := networking.IngressSpec{Rules: []networking.IngressRule{{
+			Host: "example.com",
+			IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{{
+				Path:     "/app",
+				PathType: &pathType,
+				Backend:  networking.IngressBackend{Service: &networking.IngressServiceBackend{Name: "web"}},
+			}}}},
+		}}}
+		if diff := cmp.Diff(want, ingress.Spec); diff != "" {
+			t.Fatalf("The ingress is incorrect. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("unsupported GRPCRoute", func(t *testing.T) {
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: gatewayAPIVersion, httpRouteKind: gatewayAPIVersion})
+		objs, ok := (&Service{}).convertGatewayAPIToClusterSupportedKinds(grpcRoute, []string{gatewayKind, httpRouteKind}, cluster)
+		if !ok || len(objs) != 0 {
+			t.Fatalf("Expected the GRPCRoute to be dropped. Actual: %+v", objs)
+		}
+	})
+
+	t.Run("istio gateway", func(t *testing.T) {
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: {"networking.istio.io/v1beta1"}})
+		if _, ok := (&Service{}).convertGatewayAPIToClusterSupportedKinds(istioGateway, []string{gatewayKind}, cluster); ok {
+			t.Fatalf("Expected the istio gateway to not be handled as a gateway api resource")
+		}
+	})
+}
+
+func TestGatewayAPIVersion(t *testing.T) {
+	backendRef := gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "web"}}
+	httpRoute := newHTTPRoute("web", "myproject", "example.com", []gatewayv1beta1.HTTPRouteRule{newHTTPRouteRule("/app", backendRef)})
+	gateway := (&Service{}).createGateway("myproject", []hostTLS{{hostname: "example.com", secretName: "web-tls"}})
+
+	t.Run("cluster which supports v1beta1", func(t *testing.T) {
+		versions := []string{"gateway.networking.k8s.io/v1beta1", "gateway.networking.k8s.io/v1alpha2"}
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: versions, httpRouteKind: versions})
+		obj, err := k8sschema.ConvertToSupportedVersion(gateway.DeepCopyObject(), cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the Gateway. Error: %q", err)
+		}
+		if _, ok := obj.(*gatewayv1beta1.Gateway); !ok {
+			t.Fatalf("Expected a v1beta1 Gateway. Actual: %T", obj)
+		}
+		obj, err = k8sschema.ConvertToSupportedVersion(httpRoute.DeepCopyObject(), cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the HTTPRoute. Error: %q", err)
+		}
+		if _, ok := obj.(*gatewayv1beta1.HTTPRoute); !ok {
+			t.Fatalf("Expected a v1beta1 HTTPRoute. Actual: %T", obj)
+		}
+	})
+
+	t.Run("cluster which only supports v1alpha2", func(t *testing.T) {
+		versions := []string{"gateway.networking.k8s.io/v1alpha2"}
+		cluster := getGatewayAPITestCluster(map[string][]string{gatewayKind: versions, httpRouteKind: versions})
+		obj, err := k8sschema.ConvertToSupportedVersion(gateway.DeepCopyObject(), cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the Gateway. Error: %q", err)
+		}
+		v1alpha2Gateway, ok := obj.(*gatewayv1alpha2.Gateway)
+		if !ok {
+			t.Fatalf("Expected a v1alpha2 Gateway. Actual: %T", obj)
+		}
+		if v1alpha2Gateway.APIVersion != gatewayv1alpha2.SchemeGroupVersion.String() || v1alpha2Gateway.Kind != gatewayKind {
+			t.Fatalf("The type meta of the Gateway is incorrect. Actual: %+v", v1alpha2Gateway.TypeMeta)
+		}
+		if diff := cmp.Diff(gateway.Spec, v1alpha2Gateway.Spec); diff != "" {
+			t.Fatalf("The Gateway spec should not change. Differences:\n%s", diff)
+		}
+		obj, err = k8sschema.ConvertToSupportedVersion(httpRoute.DeepCopyObject(), cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the HTTPRoute. Error: %q", err)
+		}
+		v1alpha2HTTPRoute, ok := obj.(*gatewayv1alpha2.HTTPRoute)
+		if !ok {
+			t.Fatalf("Expected a v1alpha2 HTTPRoute. Actual: %T", obj)
+		}
+		if diff := cmp.Diff(httpRoute.Spec, v1alpha2HTTPRoute.Spec); diff != "" {
+			t.Fatalf("The HTTPRoute spec should not change. Differences:\n%s", diff)
+		}
+	})
+}
+
+func TestIngressToGatewayAPI(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	pathType := networking.PathTypePrefix
+	ingress := networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "myproject"},
+		Spec: networking.IngressSpec{
+			TLS: []networking.IngressTLS{{Hosts: []string{"web.example.com"}, SecretName: "web-tls"}},
+			Rules: []networking.IngressRule{
+				{Host: "web.example.com", IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{{
+					Path: "/", PathType: &pathType, Backend: networking.IngressBackend{Service: &networking.IngressServiceBackend{Name: "web", Port: networking.ServiceBackendPort{Number: 8080}}},
+				}}}}},
+				{Host: "api.example.com", IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{{
+					Path: "/v1", PathType: &pathType, Backend: networking.IngressBackend{Service: &networking.IngressServiceBackend{Name: "api"}},
+				}}}}},
+			},
+		},
+	}
+	objs := (&Service{}).ingressToGatewayAPI(ingress)
+	if len(objs) != 3 {
+		t.Fatalf("Expected a gateway and two HTTPRoutes. Actual: %+v", objs)
+	}
+	gateway := objs[0].(*gatewayv1beta1.Gateway)
+	if len(gateway.Spec.Listeners) != 2 || gateway.Spec.Listeners[1].TLS == nil || gateway.Spec.Listeners[1].TLS.CertificateRefs[0].Name != "web-tls" || *gateway.Spec.Listeners[1].Hostname != "web.example.com" {
+		t.Fatalf("Expected an https listener for the host web.example.com. Actual: %+v", gateway.Spec.Listeners)
+	}
+	for i, want := range []struct{ name, hostname, path, backend string }{{"myproject-0", "web.example.com", "/", "web"}, {"myproject-1", "api.example.com", "/v1", "api"}} {
+		route := objs[i+1].(*gatewayv1beta1.HTTPRoute)
+		rule := route.Spec.Rules[0]
+		if route.Name != want.name || string(route.Spec.Hostnames[0]) != want.hostname || *rule.Matches[0].Path.Value != want.path || string(rule.BackendRefs[0].Name) != want.backend {
+			t.Fatalf("Expected the HTTPRoute %+v. Actual: %+v", want, route)
+		}
+	}
+}
+
+func TestIsGRPCPort(t *testing.T) {
+	testcases := []struct {
+		port core.ServicePort
+		want bool
+	}{
+		{port: core.ServicePort{Name: "grpc", Port: 9090}, want: true},
+		{port: core.ServicePort{Name: "GRPC-api", Port: 9090}, want: true},
+		{port: core.ServicePort{Name: "port-50051", Port: 50051}, want: true},
+		{port: core.ServicePort{Name: "http", Port: 8080}, want: false},
+		{port: core.ServicePort{Name: "http-grpc", Port: 8080}, want: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.port.Name, func(t *testing.T) {
+			if actual := isGRPCPort(tc.port); actual != tc.want {
+				t.Fatalf("Expected %t. Actual: %t", tc.want, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	okdappsv1 "github.com/openshift/api/apps/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	horizontalPodAutoscalerKind = "HorizontalPodAutoscaler"
+	defaultTargetUtilization    = 80
+)
+
+// HorizontalPodAutoscaler handles HorizontalPodAutoscaler objects
+type HorizontalPodAutoscaler struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (h *HorizontalPodAutoscaler) getSupportedKinds() []string {
+	return []string{horizontalPodAutoscalerKind}
+}
+
+// createNewResources converts ir to runtime objects
+func (h *HorizontalPodAutoscaler) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	objs := []runtime.Object{}
+	if !common.IsStringPresent(supportedKinds, horizontalPodAutoscalerKind) {
+		logrus.Debugf("Could not find a valid resource type in cluster to create a HorizontalPodAutoscaler")
+		return nil
+	}
+	for _, service := range ir.Services {
+		if service.OnlyIngress || service.Daemon || service.Stateful || service.Schedule != nil || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+			continue
+		}
+		if obj := h.createHorizontalPodAutoscaler(service, targetCluster.Spec); obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (h *HorizontalPodAutoscaler) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsStringPresent(h.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// createHorizontalPodAutoscaler creates a HorizontalPodAutoscaler for the service if the user wants one.
+// The replicas and the resource requests of the service, from sources like compose deploy.replicas and deploy.resources, are used as defaults.
+func (h *HorizontalPodAutoscaler) createHorizontalPodAutoscaler(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *autoscaling.HorizontalPodAutoscaler {
+	hasCPURequests := hasResourceRequests(service, core.ResourceCPU)
+	hasMemoryRequests := hasResourceRequests(service, core.ResourceMemory)
+	keyPrefix := common.ConfigServicesKey + common.Delim + `"` + service.Name + `"` + common.Delim + common.ConfigHPAForServiceKeySegment + common.Delim
+	if !qaengine.FetchBoolAnswer(keyPrefix+"enable", fmt.Sprintf("Create a HorizontalPodAutoscaler for the service %s?", service.Name), []string{"The number of replicas is scaled based on the resource utilization of the pods"}, hasCPURequests || hasMemoryRequests) {
+		return nil
+	}
+	minReplicas := int32(service.Replicas)
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	minReplicas = fetchInt32Answer(keyPrefix+"minreplicas", fmt.Sprintf("Enter the minimum number of replicas of the service %s :", service.Name), []string{"The autoscaler does not scale the service below this number of replicas"}, minReplicas)
+	maxReplicas := fetchInt32Answer(keyPrefix+"maxreplicas", fmt.Sprintf("Enter the maximum number of replicas of the service %s :", service.Name), []string{"The autoscaler does not scale the service above this number of replicas"}, 2*minReplicas)
+	if maxReplicas < minReplicas {
+		logrus.Warnf("The maximum number of replicas %d of the service %s is less than the minimum %d. Using %d", maxReplicas, service.Name, minReplicas, minReplicas)
+		maxReplicas = minReplicas
+	}
+	if !hasCPURequests {
+		logrus.Warnf("Not all the containers of the service %s have cpu requests. The autoscaler requires them to compute the cpu utilization", service.Name)
+	}
+	metrics := []autoscaling.MetricSpec{getResourceUtilizationMetric(core.ResourceCPU, fetchInt32Answer(keyPrefix+"cpuutilization", fmt.Sprintf("Enter the target cpu utilization (in percent) of the service %s :", service.Name), []string{"Pods are added when the average cpu utilization is above the target"}, defaultTargetUtilization))}
+	if hasMemoryRequests {
+		metrics = append(metrics, getResourceUtilizationMetric(core.ResourceMemory, fetchInt32Answer(keyPrefix+"memoryutilization", fmt.Sprintf("Enter the target memory utilization (in percent) of the service %s :", service.Name), []string{"Pods are added when the average memory utilization is above the target"}, defaultTargetUtilization)))
+	}
+	return &autoscaling.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       horizontalPodAutoscalerKind,
+			APIVersion: autoscaling.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: getScaleTargetRef(service.Name, cluster),
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    maxReplicas,
+			Metrics:        metrics,
+		},
+	}
+}
+
+// getScaleTargetRef returns a reference to the workload created for the service, in the same order of preference as the Deployment api resource
+func getScaleTargetRef(name string, cluster collecttypes.ClusterMetadataSpec) autoscaling.CrossVersionObjectReference {
+	ref := autoscaling.CrossVersionObjectReference{Kind: common.DeploymentKind, Name: name, APIVersion: "apps/v1"}
+	if versions := cluster.GetSupportedVersions(common.DeploymentKind); versions != nil {
+		ref.APIVersion = versions[0]
+	} else if cluster.GetSupportedVersions(deploymentConfigKind) != nil {
+		ref.Kind = deploymentConfigKind
+		ref.APIVersion = okdappsv1.SchemeGroupVersion.String()
+	} else if cluster.GetSupportedVersions(replicationControllerKind) != nil {
+		ref.Kind = replicationControllerKind
+		ref.APIVersion = "v1"
+	}
+	return ref
+}
+
+func getResourceUtilizationMetric(name core.ResourceName, utilization int32) autoscaling.MetricSpec {
+	return autoscaling.MetricSpec{
+		Type: autoscaling.ResourceMetricSourceType,
+		Resource: &autoscaling.ResourceMetricSource{
+			Name: name,
+			Target: autoscaling.MetricTarget{
+				Type:               autoscaling.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
+// hasResourceRequests returns true if all the containers of the service request the resource. The requests default to the limits.
+func hasResourceRequests(service irtypes.Service, name core.ResourceName) bool {
+	if len(service.Containers) == 0 {
+		return false
+	}
+	for _, container := range service.Containers {
+		if _, ok := container.Resources.Requests[name]; ok {
+			continue
+		}
+		if _, ok := container.Resources.Limits[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func fetchInt32Answer(key, desc string, context []string, def int32) int32 {
+	ans := qaengine.FetchStringAnswer(key, desc, context, strconv.Itoa(int(def)))
+	value, err := strconv.ParseInt(ans, 10, 32)
+	if err != nil || value < 0 {
+		logrus.Errorf("%s is not a valid number for %s. Using the default %d", ans, key, def)
+		return def
+	}
+	return int32(value)
+}
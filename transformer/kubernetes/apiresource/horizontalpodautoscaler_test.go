@@ -0,0 +1,188 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func getHPATestService(replicas int, requests core.ResourceList, limits core.ResourceList) irtypes.Service {
+	service := irtypes.NewServiceWithName("web")
+	service.Replicas = replicas
+	service.Containers = []core.Container{{Name: "web", Image: "web", Resources: core.ResourceRequirements{Requests: requests, Limits: limits}}}
+	return service
+}
+
+func TestCreateHorizontalPodAutoscaler(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"Deployment": {"apps/v1"}}}
+	utilization := int32(defaultTargetUtilization)
+	cpuMetric := autoscaling.MetricSpec{
+		Type:     autoscaling.ResourceMetricSourceType,
+		Resource: &autoscaling.ResourceMetricSource{Name: core.ResourceCPU, Target: autoscaling.MetricTarget{Type: autoscaling.UtilizationMetricType, AverageUtilization: &utilization}},
+	}
+	memoryMetric := autoscaling.MetricSpec{
+		Type:     autoscaling.ResourceMetricSourceType,
+		Resource: &autoscaling.ResourceMetricSource{Name: core.ResourceMemory, Target: autoscaling.MetricTarget{Type: autoscaling.UtilizationMetricType, AverageUtilization: &utilization}},
+	}
+	testcases := []struct {
+		name        string
+		service     irtypes.Service
+		minReplicas int32
+		maxReplicas int32
+		metrics     []autoscaling.MetricSpec
+	}{
+		{
+			name:        "cpu requests",
+			service:     getHPATestService(2, core.ResourceList{core.ResourceCPU: resource.MustParse("100m")}, nil),
+			minReplicas: 2,
+			maxReplicas: 4,
+			metrics:     []autoscaling.MetricSpec{cpuMetric},
+		},
+		{
+			name:        "cpu and memory limits",
+			service:     getHPATestService(0, nil, core.ResourceList{core.ResourceCPU: resource.MustParse("1"), core.ResourceMemory: resource.MustParse("1Gi")}),
+			minReplicas: 1,
+			maxReplicas: 2,
+			metrics:     []autoscaling.MetricSpec{cpuMetric, memoryMetric},
+		},
+		{
+			name:        "memory requests",
+			service:     getHPATestService(3, core.ResourceList{core.ResourceMemory: resource.MustParse("512Mi")}, nil),
+			minReplicas: 3,
+			maxReplicas: 6,
+			metrics:     []autoscaling.MetricSpec{cpuMetric, memoryMetric},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			hpa := (&HorizontalPodAutoscaler{}).createHorizontalPodAutoscaler(tc.service, cluster)
+			if hpa == nil {
+				t.Fatalf("expected a HorizontalPodAutoscaler for the service %s", tc.service.Name)
+			}
+			want := autoscaling.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"},
+				MinReplicas:    &tc.minReplicas,
+				MaxReplicas:    tc.maxReplicas,
+				Metrics:        tc.metrics,
+			}
+			if diff := cmp.Diff(want, hpa.Spec); diff != "" {
+				t.Fatalf("the HorizontalPodAutoscaler is incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("no resource requests", func(t *testing.T) {
+		if hpa := (&HorizontalPodAutoscaler{}).createHorizontalPodAutoscaler(getHPATestService(2, nil, nil), cluster); hpa != nil {
+			t.Fatalf("expected no HorizontalPodAutoscaler by default when the containers have no resource requests. Actual: %+v", hpa)
+		}
+	})
+}
+
+func TestHorizontalPodAutoscalerCreateNewResources(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	requests := core.ResourceList{core.ResourceCPU: resource.MustParse("100m")}
+	ir := irtypes.NewIR()
+	for _, name := range []string{"web", "db", "agent", "job"} {
+		service := getHPATestService(1, requests, nil)
+		service.Name = name
+		ir.Services[name] = service
+	}
+	db := ir.Services["db"]
+	db.Stateful = true
+	ir.Services["db"] = db
+	agent := ir.Services["agent"]
+	agent.Daemon = true
+	ir.Services["agent"] = agent
+	job := ir.Services["job"]
+	job.RestartPolicy = core.RestartPolicyOnFailure
+	ir.Services["job"] = job
+	cluster := collecttypes.ClusterMetadata{Spec: collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"Deployment": {"apps/v1"}}}}
+
+	objs := (&HorizontalPodAutoscaler{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), []string{horizontalPodAutoscalerKind}, cluster)
+	if len(objs) != 1 || objs[0].(*autoscaling.HorizontalPodAutoscaler).Name != "web" {
+		t.Fatalf("expected a HorizontalPodAutoscaler only for the service web. Actual: %+v", objs)
+	}
+	if objs := (&HorizontalPodAutoscaler{}).createNewResources(irtypes.NewEnhancedIRFromIR(ir), nil, cluster); len(objs) != 0 {
+		t.Fatalf("expected no HorizontalPodAutoscalers when the cluster does not support them. Actual: %+v", objs)
+	}
+}
+
+func TestGetScaleTargetRef(t *testing.T) {
+	testcases := []struct {
+		name  string
+		kinds map[string][]string
+		want  autoscaling.CrossVersionObjectReference
+	}{
+		{name: "deployment", kinds: map[string][]string{"Deployment": {"apps/v1"}, "DeploymentConfig": {"apps.openshift.io/v1"}}, want: autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"}},
+		{name: "deployment config", kinds: map[string][]string{"DeploymentConfig": {"apps.openshift.io/v1"}}, want: autoscaling.CrossVersionObjectReference{Kind: "DeploymentConfig", Name: "web", APIVersion: "apps.openshift.io/v1"}},
+		{name: "replication controller", kinds: map[string][]string{"ReplicationController": {"v1"}}, want: autoscaling.CrossVersionObjectReference{Kind: "ReplicationController", Name: "web", APIVersion: "v1"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := getScaleTargetRef("web", collecttypes.ClusterMetadataSpec{APIKindVersionMap: tc.kinds})
+			if diff := cmp.Diff(tc.want, actual); diff != "" {
+				t.Fatalf("the scale target is incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHorizontalPodAutoscalerVersion(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{
+		"Deployment":                {"apps/v1"},
+		horizontalPodAutoscalerKind: {"autoscaling/v1", "autoscaling/v2beta2"},
+	}}
+
+	t.Run("cpu metric", func(t *testing.T) {
+		hpa := (&HorizontalPodAutoscaler{}).createHorizontalPodAutoscaler(getHPATestService(1, core.ResourceList{core.ResourceCPU: resource.MustParse("100m")}, nil), cluster)
+		obj, err := k8sschema.ConvertToSupportedVersion(hpa, cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the HorizontalPodAutoscaler. Error: %q", err)
+		}
+		if _, ok := obj.(*autoscalingv1.HorizontalPodAutoscaler); !ok {
+			t.Fatalf("expected the first supported version autoscaling/v1 to be used. Actual: %T", obj)
+		}
+	})
+
+	t.Run("cpu and memory metrics", func(t *testing.T) {
+		hpa := (&HorizontalPodAutoscaler{}).createHorizontalPodAutoscaler(getHPATestService(1, core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("1Gi")}, nil), cluster)
+		obj, err := k8sschema.ConvertToSupportedVersion(hpa, cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the HorizontalPodAutoscaler. Error: %q", err)
+		}
+		v2hpa, ok := obj.(*autoscalingv2beta2.HorizontalPodAutoscaler)
+		if !ok {
+			t.Fatalf("expected autoscaling/v2beta2 to be used to keep the memory metric. Actual: %T", obj)
+		}
+		if len(v2hpa.Spec.Metrics) != 2 || v2hpa.Spec.Metrics[1].Resource.Name != "memory" {
+			t.Fatalf("expected the cpu and memory metrics. Actual: %+v", v2hpa.Spec.Metrics)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	strictMTLSMode     = "STRICT"
+	permissiveMTLSMode = "PERMISSIVE"
+	// istioMutualTLSMode makes the sidecars use the istio certificates for the traffic to the services
+	istioMutualTLSMode = "ISTIO_MUTUAL"
+	// istioMeshGateway is the reserved gateway name of the sidecars in the mesh
+	istioMeshGateway = "mesh"
+	// defaultIstioIngressGatewayPrincipal is the identity of the default istio ingress gateway
+	defaultIstioIngressGatewayPrincipal = "cluster.local/ns/istio-system/sa/istio-ingressgateway-service-account"
+)
+
+// Istio handles the istio service mesh resources of the services
+type Istio struct {
+}
+
+// getSupportedKinds returns supported kinds
+func (i *Istio) getSupportedKinds() []string {
+	return []string{k8sschema.VirtualServiceKind, k8sschema.DestinationRuleKind, k8sschema.IstioGatewayKind, k8sschema.PeerAuthenticationKind, k8sschema.AuthorizationPolicyKind, rbacv1.ServiceAccountKind}
+}
+
+// createNewResources converts IR to runtime objects
+func (i *Istio) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if getServiceMesh(targetCluster.Spec) != istioServiceMesh {
+		return nil
+	}
+	objs := createServiceAccountsForMesh(ir)
+	meshServiceNames := getMeshServiceNames(ir)
+	if len(meshServiceNames) > 0 {
+		mtlsMode := qaengine.FetchSelectAnswer(common.ConfigServiceMeshMTLSModeKey, "Select the mutual TLS mode of the service mesh :", []string{"STRICT: the services accept only mutual TLS traffic, PERMISSIVE: the services accept plain text traffic too"}, strictMTLSMode, []string{strictMTLSMode, permissiveMTLSMode})
+		objs = append(objs, i.createPeerAuthentication(ir.Name, mtlsMode))
+	}
+	ingressBackendServiceNames := getIngressBackendServiceNames(ir)
+	ingressGatewayPrincipal := ""
+	for _, serviceName := range meshServiceNames {
+		service := ir.Services[serviceName]
+		if len(service.ServiceToPodPortForwardings) == 0 {
+			continue
+		}
+		objs = append(objs, i.createDestinationRule(service), i.createVirtualService(service))
+		if isExposedOutsideMesh(service) {
+			logrus.Infof("The service %s can be accessed from outside the service mesh. Not creating the %s of the service", service.Name, k8sschema.AuthorizationPolicyKind)
+			continue
+		}
+		serviceAccountNames := getLinkingServiceAccountNames(ir, service.Name)
+		if len(serviceAccountNames) == 0 {
+			logrus.Debugf("No links to the service %s were found. Not creating the %s of the service", service.Name, k8sschema.AuthorizationPolicyKind)
+			continue
+		}
+		principals := []string{}
+		for _, serviceAccountName := range serviceAccountNames {
+			principals = append(principals, "*/sa/"+serviceAccountName)
+		}
+		if common.IsStringPresent(ingressBackendServiceNames, service.Name) {
+			if ingressGatewayPrincipal == "" {
+				ingressGatewayPrincipal = qaengine.FetchStringAnswer(common.ConfigServiceMeshIngressGatewayPrincipalKey, "Enter the principal of the istio ingress gateway :", []string{"The exposed services allow the requests from the ingress gateway with this identity"}, defaultIstioIngressGatewayPrincipal)
+			}
+			principals = append(principals, ingressGatewayPrincipal)
+		}
+		objs = append(objs, i.createAuthorizationPolicy(service, principals))
+	}
+	objs = append(objs, i.createGatewayResources(ir, targetCluster.Spec)...)
+	return objs
+}
+
+// createPeerAuthentication creates the namespace wide mutual TLS configuration
+func (i *Istio) createPeerAuthentication(name string, mtlsMode string) *k8sschema.PeerAuthentication {
+	return &k8sschema.PeerAuthentication{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.PeerAuthenticationKind,
+			APIVersion: k8sschema.IstioSecuritySchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(name),
+		},
+		Spec: k8sschema.PeerAuthenticationSpec{
+			MTLS: &k8sschema.IstioPeerAuthenticationMTLS{Mode: mtlsMode},
+		},
+	}
+}
+
+// createDestinationRule makes the clients in the mesh use mutual TLS for the traffic to the service
+func (i *Istio) createDestinationRule(service irtypes.Service) *k8sschema.DestinationRule {
+	return &k8sschema.DestinationRule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.DestinationRuleKind,
+			APIVersion: k8sschema.IstioNetworkingSchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: k8sschema.DestinationRuleSpec{
+			Host: service.Name,
+			TrafficPolicy: &k8sschema.IstioTrafficPolicy{
+				TLS: &k8sschema.IstioClientTLSSettings{Mode: istioMutualTLSMode},
+			},
+		},
+	}
+}
+
+// createVirtualService routes the traffic in the mesh to the service
+func (i *Istio) createVirtualService(service irtypes.Service) *k8sschema.VirtualService {
+	return &k8sschema.VirtualService{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.VirtualServiceKind,
+			APIVersion: k8sschema.IstioNetworkingSchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: k8sschema.VirtualServiceSpec{
+			Hosts:    []string{service.Name},
+			Gateways: []string{istioMeshGateway},
+			HTTP:     []k8sschema.IstioHTTPRoute{{Route: []k8sschema.IstioRouteDestination{{Destination: k8sschema.IstioDestination{Host: service.Name}}}}},
+		},
+	}
+}
+
+// createAuthorizationPolicy allows only the principals to connect to the pods of the service
+func (i *Istio) createAuthorizationPolicy(service irtypes.Service, principals []string) *k8sschema.AuthorizationPolicy {
+	return &k8sschema.AuthorizationPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.AuthorizationPolicyKind,
+			APIVersion: k8sschema.IstioSecuritySchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: k8sschema.AuthorizationPolicySpec{
+			Selector: &k8sschema.IstioWorkloadSelector{MatchLabels: getServiceLabels(service.Name)},
+			Action:   "ALLOW",
+			Rules:    []k8sschema.IstioRule{{From: []k8sschema.IstioRuleFrom{{Source: k8sschema.IstioSource{Principals: principals}}}}},
+		},
+	}
+}
+
+// createGatewayResources creates a single istio gateway for all exposed services along with the virtual service which routes the traffic from it
+func (i *Istio) createGatewayResources(ir irtypes.EnhancedIR, targetClusterSpec collecttypes.ClusterMetadataSpec) []runtime.Object {
+	d := new(Service)
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	hostnames := []string{}
+	hostTLSs := []hostTLS{}
+	routes := []k8sschema.IstioHTTPRoute{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		backendServiceName := service.BackendServiceName
+		if service.BackendServiceName == "" {
+			backendServiceName = service.Name
+		}
+		servicePorts, hostPrefixes, relPaths, _ := d.getExposeInfo(service)
+		for j, servicePort := range servicePorts {
+			if relPaths[j] == "" {
+				continue
+			}
+			match := k8sschema.IstioHTTPMatch{URI: &k8sschema.IstioStringMatch{Prefix: relPaths[j]}}
+			hostname := d.getIngressHost(ir.Name, targetClusterSpec)
+			if hostPrefixes[j] != "" {
+				hostname = hostPrefixes[j] + "." + hostname
+				match.Authority = &k8sschema.IstioStringMatch{Exact: hostname}
+			}
+			if !common.IsStringPresent(hostnames, hostname) {
+				hostnames = append(hostnames, hostname)
+			}
+			routes = append(routes, k8sschema.IstioHTTPRoute{
+				Match: []k8sschema.IstioHTTPMatch{match},
+				Route: []k8sschema.IstioRouteDestination{{Destination: k8sschema.IstioDestination{Host: backendServiceName, Port: &k8sschema.IstioPortSelector{Number: uint32(servicePort.Port)}}}},
+			})
+		}
+		if serviceTLS := d.getTLSConfig(service, k8sschema.VirtualServiceKind, targetClusterSpec); serviceTLS.secretName != "" {
+			hostTLSs = addHostTLS(hostTLSs, d.getExposedHostnames(service, ir.Name, targetClusterSpec), serviceTLS.secretName)
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+	// The routes are matched in order. The routes of the host prefixes and the longer paths are matched first.
+	sort.SliceStable(routes, func(x, y int) bool {
+		mx, my := routes[x].Match[0], routes[y].Match[0]
+		if (mx.Authority != nil) != (my.Authority != nil) {
+			return mx.Authority != nil
+		}
+		return len(mx.URI.Prefix) > len(my.URI.Prefix)
+	})
+	gatewayName := ir.Name
+	servers := []k8sschema.IstioServer{{Port: k8sschema.IstioPort{Number: 80, Protocol: "HTTP", Name: "http"}, Hosts: hostnames}}
+	for _, h := range hostTLSs {
+		servers = append(servers, k8sschema.IstioServer{
+			Port:  k8sschema.IstioPort{Number: 443, Protocol: "HTTPS", Name: common.MakeStringDNSLabelNameCompliant("https-" + h.hostname)},
+			Hosts: []string{h.hostname},
+			TLS:   &k8sschema.IstioServerTLSSettings{Mode: "SIMPLE", CredentialName: h.secretName},
+		})
+	}
+	gateway := &k8sschema.IstioGateway{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.IstioGatewayKind,
+			APIVersion: k8sschema.IstioNetworkingSchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   gatewayName,
+			Labels: getServiceLabels(gatewayName),
+		},
+		Spec: k8sschema.IstioGatewaySpec{
+			Selector: map[string]string{"istio": "ingressgateway"},
+			Servers:  servers,
+		},
+	}
+	virtualServiceName := common.MakeStringDNSNameCompliant(gatewayName + "-gateway")
+	virtualService := &k8sschema.VirtualService{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.VirtualServiceKind,
+			APIVersion: k8sschema.IstioNetworkingSchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   virtualServiceName,
+			Labels: getServiceLabels(virtualServiceName),
+		},
+		Spec: k8sschema.VirtualServiceSpec{
+			Hosts:    hostnames,
+			Gateways: []string{gatewayName},
+			HTTP:     routes,
+		},
+	}
+	return []runtime.Object{gateway, virtualService}
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (i *Istio) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsStringPresent(i.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
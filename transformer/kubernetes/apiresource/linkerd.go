@@ -0,0 +1,134 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Linkerd handles the linkerd service mesh resources of the services
+type Linkerd struct {
+}
+
+// getSupportedKinds returns supported kinds
+func (l *Linkerd) getSupportedKinds() []string {
+	return []string{k8sschema.LinkerdServerKind, k8sschema.ServerAuthorizationKind, rbacv1.ServiceAccountKind}
+}
+
+// createNewResources converts IR to runtime objects
+func (l *Linkerd) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if getServiceMesh(targetCluster.Spec) != linkerdServiceMesh {
+		return nil
+	}
+	objs := createServiceAccountsForMesh(ir)
+	ingressBackendServiceNames := getIngressBackendServiceNames(ir)
+	for _, serviceName := range getMeshServiceNames(ir) {
+		service := ir.Services[serviceName]
+		if len(service.ServiceToPodPortForwardings) == 0 {
+			continue
+		}
+		if isExposedOutsideMesh(service) || common.IsStringPresent(ingressBackendServiceNames, service.Name) {
+			logrus.Infof("The service %s can be accessed from outside the service mesh. Not creating the %s of the service", service.Name, k8sschema.ServerAuthorizationKind)
+			continue
+		}
+		serviceAccountNames := getLinkingServiceAccountNames(ir, service.Name)
+		if len(serviceAccountNames) == 0 {
+			logrus.Debugf("No links to the service %s were found. Not creating the %s of the service", service.Name, k8sschema.LinkerdServerKind)
+			continue
+		}
+		podPorts := []intstr.IntOrString{}
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			podPort := intstr.FromString(forwarding.PodPort.Name)
+			if forwarding.PodPort.Name == "" {
+				podPort = intstr.FromInt(int(forwarding.PodPort.Number))
+			}
+			found := false
+			for _, p := range podPorts {
+				if p == podPort {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			podPorts = append(podPorts, podPort)
+			server := l.createServer(service, podPort)
+			objs = append(objs, server, l.createServerAuthorization(server.Name, service.Name, serviceAccountNames))
+		}
+	}
+	return objs
+}
+
+// createServer creates the server which selects the port of the pods of the service
+func (l *Linkerd) createServer(service irtypes.Service, podPort intstr.IntOrString) *k8sschema.LinkerdServer {
+	name := common.MakeStringDNSLabelNameCompliant(fmt.Sprintf("%s-%s", service.Name, podPort.String()))
+	return &k8sschema.LinkerdServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.LinkerdServerKind,
+			APIVersion: k8sschema.LinkerdPolicySchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: k8sschema.LinkerdServerSpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: getServiceLabels(service.Name)},
+			Port:        podPort,
+		},
+	}
+}
+
+// createServerAuthorization allows the meshed clients running with the service accounts to connect to the server
+func (l *Linkerd) createServerAuthorization(serverName string, serviceName string, serviceAccountNames []string) *k8sschema.ServerAuthorization {
+	serviceAccounts := []k8sschema.LinkerdServiceAccountName{}
+	for _, serviceAccountName := range serviceAccountNames {
+		serviceAccounts = append(serviceAccounts, k8sschema.LinkerdServiceAccountName{Name: serviceAccountName})
+	}
+	return &k8sschema.ServerAuthorization{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.ServerAuthorizationKind,
+			APIVersion: k8sschema.LinkerdPolicySchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serverName,
+			Labels: getServiceLabels(serviceName),
+		},
+		Spec: k8sschema.ServerAuthorizationSpec{
+			Server: k8sschema.LinkerdServerRef{Name: serverName},
+			Client: k8sschema.LinkerdClient{MeshTLS: &k8sschema.LinkerdMeshTLS{ServiceAccounts: serviceAccounts}},
+		},
+	}
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (l *Linkerd) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsStringPresent(l.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
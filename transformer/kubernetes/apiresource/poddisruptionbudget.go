@@ -0,0 +1,109 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	policy "k8s.io/kubernetes/pkg/apis/policy"
+)
+
+const (
+	podDisruptionBudgetKind = "PodDisruptionBudget"
+	defaultMinAvailable     = "50%"
+)
+
+// PodDisruptionBudget handles PodDisruptionBudget objects
+type PodDisruptionBudget struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (p *PodDisruptionBudget) getSupportedKinds() []string {
+	return []string{podDisruptionBudgetKind}
+}
+
+// createNewResources converts ir to runtime objects
+func (p *PodDisruptionBudget) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	objs := []runtime.Object{}
+	if !common.IsStringPresent(supportedKinds, podDisruptionBudgetKind) {
+		logrus.Debugf("Could not find a valid resource type in cluster to create a PodDisruptionBudget")
+		return nil
+	}
+	for _, service := range ir.Services {
+		if service.OnlyIngress || service.Daemon || service.Schedule != nil || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+			continue
+		}
+		if obj := p.createPodDisruptionBudget(service); obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (p *PodDisruptionBudget) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsStringPresent(p.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// createPodDisruptionBudget creates a PodDisruptionBudget for the service if the user wants one.
+// By default it is created for the services with more than one replica, since a single replica cannot be kept available during disruptions.
+func (p *PodDisruptionBudget) createPodDisruptionBudget(service irtypes.Service) *policy.PodDisruptionBudget {
+	keyPrefix := common.ConfigServicesKey + common.Delim + `"` + service.Name + `"` + common.Delim + common.ConfigPDBForServiceKeySegment + common.Delim
+	if !qaengine.FetchBoolAnswer(keyPrefix+"enable", fmt.Sprintf("Create a PodDisruptionBudget for the service %s?", service.Name), []string{"Voluntary disruptions like node drains do not evict pods if fewer pods than the minimum would remain available"}, service.Replicas > 1) {
+		return nil
+	}
+	minAvailableStr := qaengine.FetchStringAnswer(keyPrefix+"minavailable", fmt.Sprintf("Enter the minimum number of pods of the service %s which have to be available :", service.Name), []string{"Either a number of pods or a percentage like 50%"}, defaultMinAvailable)
+	minAvailable := intstr.Parse(minAvailableStr)
+	if (minAvailable.Type == intstr.Int && minAvailable.IntVal < 0) || (minAvailable.Type == intstr.String && !isPercentage(minAvailable.StrVal)) {
+		logrus.Errorf("%s is not a valid number of pods or percentage for the PodDisruptionBudget of the service %s. Using the default %s", minAvailableStr, service.Name, defaultMinAvailable)
+		minAvailable = intstr.Parse(defaultMinAvailable)
+	}
+	return &policy.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       podDisruptionBudgetKind,
+			APIVersion: policy.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: policy.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getServiceLabels(service.Name),
+			},
+		},
+	}
+}
+
+func isPercentage(s string) bool {
+	var percent int
+	n, err := fmt.Sscanf(s, "%d%%", &percent)
+	return err == nil && n == 1 && percent >= 0 && percent <= 100 && fmt.Sprintf("%d%%", percent) == s
+}
@@ -0,0 +1,79 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	policy "k8s.io/kubernetes/pkg/apis/policy"
+)
+
+func TestCreatePodDisruptionBudget(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+
+	t.Run("single replica", func(t *testing.T) {
+		service := irtypes.NewServiceWithName("web")
+		service.Replicas = 1
+		if pdb := (&PodDisruptionBudget{}).createPodDisruptionBudget(service); pdb != nil {
+			t.Fatalf("expected no PodDisruptionBudget by default for a single replica. Actual: %+v", pdb)
+		}
+	})
+
+	t.Run("multiple replicas", func(t *testing.T) {
+		service := irtypes.NewServiceWithName("web")
+		service.Replicas = 3
+		pdb := (&PodDisruptionBudget{}).createPodDisruptionBudget(service)
+		if pdb == nil {
+			t.Fatalf("expected a PodDisruptionBudget for multiple replicas")
+		}
+		minAvailable := intstr.FromString(defaultMinAvailable)
+		want := policy.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: getServiceLabels("web")},
+		}
+		if diff := cmp.Diff(want, pdb.Spec); diff != "" {
+			t.Fatalf("the PodDisruptionBudget is incorrect. Differences:\n%s", diff)
+		}
+		cluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{podDisruptionBudgetKind: {"policy/v1", "policy/v1beta1"}}}
+		obj, err := k8sschema.ConvertToSupportedVersion(pdb, cluster)
+		if err != nil {
+			t.Fatalf("Failed to convert the PodDisruptionBudget. Error: %q", err)
+		}
+		if _, ok := obj.(*policyv1.PodDisruptionBudget); !ok {
+			t.Fatalf("expected policy/v1 to be used. Actual: %T", obj)
+		}
+	})
+}
+
+func TestIsPercentage(t *testing.T) {
+	testcases := map[string]bool{"50%": true, "0%": true, "100%": true, "101%": false, "-1%": false, "50": false, "50%%": false, "5.5%": false, "abc%": false}
+	for s, want := range testcases {
+		t.Run(s, func(t *testing.T) {
+			if actual := isPercentage(s); actual != want {
+				t.Fatalf("expected isPercentage(%q) to be %t. Actual: %t", s, want, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	inlineSecretsMode      = "inline"
+	externalSecretsMode    = "externalsecret"
+	sealedSecretsMode      = "sealedsecret"
+	placeholderSecretsMode = "placeholder"
+	defaultSecretStoreName = "secretstore"
+	secretStoreKind        = "SecretStore"
+	clusterSecretStoreKind = "ClusterSecretStore"
+	// secretsEnvTemplateFile has the placeholders of the secrets created in the placeholder mode
+	secretsEnvTemplateFile = "secrets.env.template"
+	// sessionKeyBytes is the size of the AES key used by the sealed secrets controller to encrypt the data
+	sessionKeyBytes = 32
+)
+
+// getSecretsMode returns how the content of the secrets should be output.
+// The placeholder mode is used if the cluster does not support the resources of the selected mode.
+func getSecretsMode(targetClusterSpec collecttypes.ClusterMetadataSpec) string {
+	secretsMode := qaengine.FetchSelectAnswer(common.ConfigSecretsModeKey, "How should the content of the secrets be output?", []string{
+		"inline: the content is put in the Secrets",
+		"externalsecret: ExternalSecrets get the content from a secret store of the External Secrets Operator",
+		"sealedsecret: SealedSecrets have the content encrypted with the public cert of the Sealed Secrets controller",
+		"placeholder: the Secrets have placeholders which are filled using " + secretsEnvTemplateFile,
+	}, inlineSecretsMode, []string{inlineSecretsMode, externalSecretsMode, sealedSecretsMode, placeholderSecretsMode})
+	kind := ""
+	switch secretsMode {
+	case externalSecretsMode:
+		kind = k8sschema.ExternalSecretKind
+	case sealedSecretsMode:
+		kind = k8sschema.SealedSecretKind
+	}
+	if kind != "" && len(targetClusterSpec.GetSupportedVersions(kind)) == 0 {
+		logrus.Warnf("The cluster does not support the kind %s. Using placeholders in the Secrets instead", kind)
+		return placeholderSecretsMode
+	}
+	return secretsMode
+}
+
+// createSecretForMode creates the resource which provides the secret in the selected mode
+func (s *Storage) createSecretForMode(st irtypes.Storage, secretsMode string) (runtime.Object, error) {
+	switch secretsMode {
+	case externalSecretsMode:
+		return s.createExternalSecret(st), nil
+	case sealedSecretsMode:
+		sealedSecret, err := s.createSealedSecret(st)
+		if err == nil {
+			return sealedSecret, nil
+		}
+		logrus.Errorf("Unable to seal the secret %s. Using placeholders instead : %s", st.Name, err)
+		return s.createPlaceholderSecret(st), nil
+	case placeholderSecretsMode:
+		return s.createPlaceholderSecret(st), nil
+	}
+	return s.createSecret(st), nil
+}
+
+func (s *Storage) createExternalSecret(st irtypes.Storage) *k8sschema.ExternalSecret {
+	storeName := qaengine.FetchStringAnswer(common.ConfigSecretStoreKey, "Enter the name of the secret store which has the content of the secrets :", []string{"The content of each secret is read from the key with the name of the secret"}, defaultSecretStoreName)
+	storeKind := qaengine.FetchSelectAnswer(common.ConfigSecretStoreKindKey, "Select the kind of the secret store :", []string{"A SecretStore is in the same namespace, a ClusterSecretStore can be used from all namespaces"}, secretStoreKind, []string{secretStoreKind, clusterSecretStoreKind})
+	externalSecret := &k8sschema.ExternalSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.ExternalSecretKind,
+			APIVersion: k8sschema.ExternalSecretsSchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        st.Name,
+			Annotations: st.Annotations,
+		},
+		Spec: k8sschema.ExternalSecretSpec{
+			SecretStoreRef: k8sschema.SecretStoreRef{Name: storeName, Kind: storeKind},
+			Target:         k8sschema.ExternalSecretTarget{Name: st.Name},
+		},
+	}
+	if st.SecretType != "" {
+		externalSecret.Spec.Target.Template = &k8sschema.ExternalSecretTemplate{Type: corev1.SecretType(st.SecretType)}
+	}
+	for _, key := range getSortedKeys(st.Content) {
+		externalSecret.Spec.Data = append(externalSecret.Spec.Data, k8sschema.ExternalSecretData{
+			SecretKey: key,
+			RemoteRef: k8sschema.ExternalSecretDataRemoteRef{Key: st.Name, Property: key},
+		})
+	}
+	return externalSecret
+}
+
+func (s *Storage) createSealedSecret(st irtypes.Storage) (*k8sschema.SealedSecret, error) {
+	if s.sealedSecretsPublicKey == nil {
+		certPath := qaengine.FetchStringAnswer(common.ConfigSealedSecretsCertKey, "Enter the path of the public cert of the Sealed Secrets controller :", []string{"The cert can be fetched using kubeseal --fetch-cert"}, "")
+		publicKey, err := getSealedSecretsPublicKey(certPath)
+		if err != nil {
+			return nil, err
+		}
+		s.sealedSecretsPublicKey = publicKey
+	}
+	secType := corev1.SecretTypeOpaque
+	if st.SecretType != "" {
+		secType = corev1.SecretType(st.SecretType)
+	}
+	annotations := map[string]string{k8sschema.SealedSecretClusterWideAnnotation: "true"}
+	templateAnnotations := map[string]string{k8sschema.SealedSecretClusterWideAnnotation: "true"}
+	for k, v := range st.Annotations {
+		templateAnnotations[k] = v
+	}
+	sealedSecret := &k8sschema.SealedSecret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       k8sschema.SealedSecretKind,
+			APIVersion: k8sschema.SealedSecretsSchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        st.Name,
+			Annotations: annotations,
+		},
+		Spec: k8sschema.SealedSecretSpec{
+			Template: k8sschema.SecretTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        st.Name,
+					Annotations: templateAnnotations,
+				},
+				Type: secType,
+			},
+			EncryptedData: map[string]string{},
+		},
+	}
+	for key, value := range st.Content {
+		// The secret is sealed with the cluster wide scope, which uses an empty label
+		ciphertext, err := hybridEncrypt(rand.Reader, s.sealedSecretsPublicKey, value, []byte{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to encrypt the key %s : %w", key, err)
+		}
+		sealedSecret.Spec.EncryptedData[key] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+	return sealedSecret, nil
+}
+
+// createPlaceholderSecret creates a secret whose content has to be filled using the secrets env template
+func (s *Storage) createPlaceholderSecret(st irtypes.Storage) *corev1.Secret {
+	secType := corev1.SecretTypeOpaque
+	if st.SecretType != "" {
+		secType = corev1.SecretType(st.SecretType)
+	}
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       string(irtypes.SecretKind),
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        st.Name,
+			Annotations: st.Annotations,
+		},
+		Type:       secType,
+		StringData: map[string]string{},
+	}
+	if s.secretPlaceholderSources == nil {
+		s.secretPlaceholderSources = map[string]string{}
+	}
+	for _, key := range getSortedKeys(st.Content) {
+		source := st.Name + "/" + key
+		placeholder := s.getUniqueSecretPlaceholder(st.Name, key)
+		if _, ok := s.secretPlaceholderSources[placeholder]; !ok {
+			s.secretPlaceholderSources[placeholder] = source
+			s.secretPlaceholders = append(s.secretPlaceholders, placeholder)
+		}
+		secret.StringData[key] = "${" + placeholder + "}"
+	}
+	return secret
+}
+
+// getUniqueSecretPlaceholder returns the placeholder of the key of the secret.
+// A numeric suffix is added when the placeholder is already used for another secret or key, like for the secrets db-creds and db_creds.
+func (s *Storage) getUniqueSecretPlaceholder(secretName, key string) string {
+	source := secretName + "/" + key
+	basePlaceholder := getSecretPlaceholder(secretName, key)
+	placeholder := basePlaceholder
+	for i := 2; ; i++ {
+		existingSource, ok := s.secretPlaceholderSources[placeholder]
+		if !ok || existingSource == source {
+			break
+		}
+		placeholder = fmt.Sprintf("%s_%d", basePlaceholder, i)
+	}
+	if placeholder != basePlaceholder {
+		logrus.Warnf("The placeholder %s of the key %s is already used for the key %s. Using the placeholder %s instead", basePlaceholder, source, s.secretPlaceholderSources[basePlaceholder], placeholder)
+	}
+	return placeholder
+}
+
+// writeSecretsEnvTemplate writes the placeholders of the secrets which have to be filled
+func (s *Storage) writeSecretsEnvTemplate(outputPath string) (string, error) {
+	if len(s.secretPlaceholders) == 0 {
+		return "", nil
+	}
+	content := "# Fill in the values of the secrets, save it as secrets.env and replace the placeholders in the Secrets using\n" +
+		"# set -a && source secrets.env && envsubst < <secret yaml> | kubectl apply -f -\n"
+	for _, placeholder := range s.secretPlaceholders {
+		content += placeholder + "=\n"
+	}
+	templatePath := filepath.Join(outputPath, secretsEnvTemplateFile)
+	if err := os.WriteFile(templatePath, []byte(content), common.DefaultFilePermission); err != nil {
+		return "", err
+	}
+	return templatePath, nil
+}
+
+var disallowedPlaceholderCharactersRegex = regexp.MustCompile(`[^A-Z0-9_]`)
+
+func getSecretPlaceholder(secretName, key string) string {
+	return disallowedPlaceholderCharactersRegex.ReplaceAllLiteralString(strings.ToUpper(secretName+"_"+key), "_")
+}
+
+func getSealedSecretsPublicKey(certPath string) (*rsa.PublicKey, error) {
+	if certPath == "" {
+		return nil, fmt.Errorf("the path of the public cert of the sealed secrets controller is empty")
+	}
+	certBytes, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the cert at path %s : %w", certPath, err)
+	}
+	block, _ := pem.Decode(certBytes)
+	if block == nil {
+		return nil, fmt.Errorf("unable to find a PEM encoded cert at path %s", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the cert at path %s : %w", certPath, err)
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("expected the cert at path %s to have an RSA public key. Actual: %T", certPath, cert.PublicKey)
+	}
+	return publicKey, nil
+}
+
+// hybridEncrypt encrypts the data the same way as kubeseal.
+// A random AES-GCM session key encrypts the data and the session key is encrypted with RSA-OAEP.
+func hybridEncrypt(rnd io.Reader, publicKey *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aed, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, publicKey, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, 2)
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+	// The session key is used only once, so a zero nonce is safe
+	zeroNonce := make([]byte, aed.NonceSize())
+	return aed.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
+
+func getSortedKeys(content map[string][]byte) []string {
+	keys := []string{}
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,238 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+const (
+	sealedSecretsCertPath = "testdata/secret/sealedsecrets.crt"
+	sealedSecretsKeyPath  = "testdata/secret/sealedsecrets.key"
+)
+
+func TestHybridEncrypt(t *testing.T) {
+	publicKey, err := getSealedSecretsPublicKey(sealedSecretsCertPath)
+	if err != nil {
+		t.Fatalf("Failed to get the public key from the cert. Error: %q", err)
+	}
+	// The golden ciphertext was created by the HybridEncrypt of github.com/bitnami-labs/sealed-secrets/pkg/crypto
+	// with the same public key, plaintext, empty label and random bytes.
+	golden, err := os.ReadFile("testdata/secret/hunter2.sealed")
+	if err != nil {
+		t.Fatalf("Failed to read the golden ciphertext. Error: %q", err)
+	}
+	random := make([]byte, 64)
+	for i := range random {
+		random[i] = byte(i)
+	}
+	ciphertext, err := hybridEncrypt(bytes.NewReader(random), publicKey, []byte("hunter2"), []byte{})
+	if err != nil {
+		t.Fatalf("Failed to encrypt. Error: %q", err)
+	}
+	if diff := cmp.Diff(strings.TrimSpace(string(golden)), base64.StdEncoding.EncodeToString(ciphertext)); diff != "" {
+		t.Fatalf("The ciphertext does not match the one created by sealed secrets. Differences:\n%s", diff)
+	}
+}
+
+func TestCreateSealedSecret(t *testing.T) {
+	keyBytes, err := os.ReadFile(sealedSecretsKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to read the private key. Error: %q", err)
+	}
+	block, _ := pem.Decode(keyBytes)
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse the private key. Error: %q", err)
+	}
+	privateKey := key.(*rsa.PrivateKey)
+	publicKey, err := getSealedSecretsPublicKey(sealedSecretsCertPath)
+	if err != nil {
+		t.Fatalf("Failed to get the public key from the cert. Error: %q", err)
+	}
+	content := map[string][]byte{"password": []byte("hunter2"), "user": []byte("admin")}
+	s := &Storage{sealedSecretsPublicKey: publicKey}
+	sealedSecret, err := s.createSealedSecret(irtypes.Storage{Name: "db-creds", StorageType: irtypes.SecretKind, Content: content})
+	if err != nil {
+		t.Fatalf("Failed to create the sealed secret. Error: %q", err)
+	}
+	if sealedSecret.Annotations[k8sschema.SealedSecretClusterWideAnnotation] != "true" {
+		t.Fatalf("Expected the sealed secret to be cluster wide. Actual annotations: %+v", sealedSecret.Annotations)
+	}
+	if len(sealedSecret.Spec.EncryptedData) != len(content) {
+		t.Fatalf("Expected %d encrypted keys. Actual: %+v", len(content), sealedSecret.Spec.EncryptedData)
+	}
+	for key, value := range content {
+		ciphertext, err := base64.StdEncoding.DecodeString(sealedSecret.Spec.EncryptedData[key])
+		if err != nil {
+			t.Fatalf("Failed to decode the encrypted data of the key %s. Error: %q", key, err)
+		}
+		rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+		sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, privateKey, ciphertext[2:2+rsaLen], []byte{})
+		if err != nil {
+			t.Fatalf("Failed to decrypt the session key of the key %s. Error: %q", key, err)
+		}
+		block, err := aes.NewCipher(sessionKey)
+		if err != nil {
+			t.Fatalf("Failed to create the AES cipher. Error: %q", err)
+		}
+		aed, err := cipher.NewGCM(block)
+		if err != nil {
+			t.Fatalf("Failed to create the GCM cipher. Error: %q", err)
+		}
+		plaintext, err := aed.Open(nil, make([]byte, aed.NonceSize()), ciphertext[2+rsaLen:], nil)
+		if err != nil {
+			t.Fatalf("Failed to decrypt the key %s. Error: %q", key, err)
+		}
+		if string(plaintext) != string(value) {
+			t.Fatalf("Expected the key %s to decrypt to %q. Actual: %q", key, value, plaintext)
+		}
+	}
+}
+
+func TestCreatePlaceholderSecret(t *testing.T) {
+	getSecret := func(name string, keys ...string) irtypes.Storage {
+		content := map[string][]byte{}
+		for _, key := range keys {
+			content[key] = []byte("hunter2")
+		}
+		return irtypes.Storage{Name: name, StorageType: irtypes.SecretKind, Content: content}
+	}
+	testcases := []struct {
+		name             string
+		secrets          []irtypes.Storage
+		wantStringData   map[string]string
+		wantPlaceholders []string
+	}{
+		{
+			name:             "single secret",
+			secrets:          []irtypes.Storage{getSecret("db-creds", "user", "password")},
+			wantStringData:   map[string]string{"password": "${DB_CREDS_PASSWORD}", "user": "${DB_CREDS_USER}"},
+			wantPlaceholders: []string{"DB_CREDS_PASSWORD", "DB_CREDS_USER"},
+		},
+		{
+			name:             "same secret twice",
+			secrets:          []irtypes.Storage{getSecret("db-creds", "password"), getSecret("db-creds", "password")},
+			wantStringData:   map[string]string{"password": "${DB_CREDS_PASSWORD}"},
+			wantPlaceholders: []string{"DB_CREDS_PASSWORD"},
+		},
+		{
+			name:             "secrets with the same placeholder",
+			secrets:          []irtypes.Storage{getSecret("db-creds", "password"), getSecret("db_creds", "password"), getSecret("db.creds", "password")},
+			wantStringData:   map[string]string{"password": "${DB_CREDS_PASSWORD_3}"},
+			wantPlaceholders: []string{"DB_CREDS_PASSWORD", "DB_CREDS_PASSWORD_2", "DB_CREDS_PASSWORD_3"},
+		},
+		{
+			name:             "keys with the same placeholder",
+			secrets:          []irtypes.Storage{getSecret("db", "pass-word", "pass.word")},
+			wantStringData:   map[string]string{"pass-word": "${DB_PASS_WORD}", "pass.word": "${DB_PASS_WORD_2}"},
+			wantPlaceholders: []string{"DB_PASS_WORD", "DB_PASS_WORD_2"},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Storage{}
+			stringData := map[string]string{}
+			for _, secret := range tc.secrets {
+				placeholderSecret := s.createPlaceholderSecret(secret)
+				if placeholderSecret.Name != secret.Name || len(placeholderSecret.Data) != 0 {
+					t.Fatalf("Expected the secret %s without any data. Actual: %+v", secret.Name, placeholderSecret)
+				}
+				stringData = placeholderSecret.StringData
+			}
+			if diff := cmp.Diff(tc.wantStringData, stringData); diff != "" {
+				t.Fatalf("The placeholders in the secret are incorrect. Differences:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPlaceholders, s.secretPlaceholders); diff != "" {
+				t.Fatalf("The recorded placeholders are incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteSecretsEnvTemplate(t *testing.T) {
+	outputPath := t.TempDir()
+	s := &Storage{}
+	templatePath, err := s.writeSecretsEnvTemplate(outputPath)
+	if err != nil || templatePath != "" {
+		t.Fatalf("Expected no template without placeholders. Actual: %s Error: %q", templatePath, err)
+	}
+	s.createPlaceholderSecret(irtypes.Storage{Name: "db-creds", StorageType: irtypes.SecretKind, Content: map[string][]byte{"password": []byte("hunter2")}})
+	templatePath, err = s.writeSecretsEnvTemplate(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to write the template. Error: %q", err)
+	}
+	if templatePath != filepath.Join(outputPath, secretsEnvTemplateFile) {
+		t.Fatalf("Expected the template at %s. Actual: %s", filepath.Join(outputPath, secretsEnvTemplateFile), templatePath)
+	}
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read the template. Error: %q", err)
+	}
+	if !strings.HasSuffix(string(content), "\nDB_CREDS_PASSWORD=\n") || strings.Contains(string(content), "hunter2") {
+		t.Fatalf("Expected the template to only have the placeholder DB_CREDS_PASSWORD. Actual:\n%s", content)
+	}
+}
+
+func TestGetSecretsMode(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	supportedCluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{
+		k8sschema.ExternalSecretKind: {k8sschema.ExternalSecretsSchemeGroupVersion.String()},
+		k8sschema.SealedSecretKind:   {k8sschema.SealedSecretsSchemeGroupVersion.String()},
+	}}
+	testcases := []struct {
+		name    string
+		answer  string
+		cluster collecttypes.ClusterMetadataSpec
+		want    string
+	}{
+		{name: "inline", answer: inlineSecretsMode, want: inlineSecretsMode},
+		{name: "placeholder", answer: placeholderSecretsMode, want: placeholderSecretsMode},
+		{name: "supported external secrets", answer: externalSecretsMode, cluster: supportedCluster, want: externalSecretsMode},
+		{name: "supported sealed secrets", answer: sealedSecretsMode, cluster: supportedCluster, want: sealedSecretsMode},
+		{name: "unsupported external secrets", answer: externalSecretsMode, want: placeholderSecretsMode},
+		{name: "unsupported sealed secrets", answer: sealedSecretsMode, want: placeholderSecretsMode},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			addTestAnswerEngine(t, map[string]interface{}{common.ConfigSecretsModeKey: tc.answer})
+			if actual := getSecretsMode(tc.cluster); actual != tc.want {
+				t.Fatalf("Expected the secrets mode %s. Actual: %s", tc.want, actual)
+			}
+		})
+	}
+}
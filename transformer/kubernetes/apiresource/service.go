@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
@@ -36,7 +37,10 @@ import (
 )
 
 const (
-	routeKind = "Route"
+	routeKind     = "Route"
+	gatewayKind   = "Gateway"
+	httpRouteKind = "HTTPRoute"
+	grpcRouteKind = "GRPCRoute"
 )
 
 // Service handles all objects related to a service
@@ -45,30 +49,44 @@ type Service struct {
 
 // getSupportedKinds returns supported kinds
 func (d *Service) getSupportedKinds() []string {
-	return []string{common.ServiceKind, common.IngressKind, routeKind}
+	return []string{common.ServiceKind, common.IngressKind, routeKind, gatewayKind, httpRouteKind, grpcRouteKind, cmapi.CertificateKind}
 }
 
 // createNewResources converts IR to runtime objects
 func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
 	ingressEnabled := false
+	gatewayEnabled := false
+	virtualServiceEnabled := false
+	exposeKind := ""
 	for _, service := range ir.Services {
 		exposeobjectcreated := false
 		if _, _, _, st := d.getExposeInfo(service); st != "" || service.OnlyIngress {
+			if exposeKind == "" {
+				exposeKind = d.getExposeKind(supportedKinds, targetCluster.Spec)
+			}
 			// Create services depending on whether the service needs to be externally exposed
-			if common.IsStringPresent(supportedKinds, routeKind) {
+			switch exposeKind {
+			case routeKind:
 				//Create Route
 				routeObjs := d.createRoutes(service, ir, targetCluster.Spec)
 				for _, routeObj := range routeObjs {
 					objs = append(objs, routeObj)
 				}
 				exposeobjectcreated = true
-			} else if common.IsStringPresent(supportedKinds, common.IngressKind) {
+			case common.IngressKind:
 				//Create Ingress
 				// obj := d.createIngress(service)
 				// objs = append(objs, obj)
 				exposeobjectcreated = true
 				ingressEnabled = true
+			case gatewayKind:
+				exposeobjectcreated = true
+				gatewayEnabled = true
+			case k8sschema.VirtualServiceKind:
+				// The istio gateway and virtual service are created by the istio api resource
+				exposeobjectcreated = true
+				virtualServiceEnabled = true
 			}
 		}
 		if service.OnlyIngress {
@@ -79,6 +97,9 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 		}
 		obj := d.createService(service)
 		objs = append(objs, obj)
+		if service.Stateful {
+			objs = append(objs, d.createHeadlessService(service))
+		}
 	}
 
 	// Create one ingress for all services
@@ -89,12 +110,46 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 		}
 	}
 
+	// Create one gateway and the routes of all services
+	if gatewayEnabled {
+		objs = append(objs, d.createGatewayAPIResources(ir, targetCluster.Spec)...)
+	}
+
+	if ingressEnabled || gatewayEnabled || virtualServiceEnabled {
+		objs = append(objs, d.createCertificates(ir, exposeKind, targetCluster.Spec)...)
+	}
+
 	return objs
 }
 
+// getExposeKind returns the kind of resources used to expose the services outside the cluster
+func (d *Service) getExposeKind(supportedKinds []string, targetClusterSpec collecttypes.ClusterMetadataSpec) string {
+	if common.IsStringPresent(supportedKinds, routeKind) {
+		return routeKind
+	}
+	if getServiceMesh(targetClusterSpec) == istioServiceMesh {
+		return k8sschema.VirtualServiceKind
+	}
+	ingressSupported := common.IsStringPresent(supportedKinds, common.IngressKind)
+	gatewaySupported := isGatewayAPISupported(targetClusterSpec, gatewayKind, httpRouteKind)
+	if ingressSupported && gatewaySupported {
+		return qaengine.FetchSelectAnswer(common.ConfigIngressKindKey, "Select the kind of resources to use for exposing the services outside the cluster :", []string{"Gateway creates a Gateway API Gateway with HTTPRoutes and GRPCRoutes for the services"}, common.IngressKind, []string{common.IngressKind, gatewayKind})
+	}
+	if ingressSupported {
+		return common.IngressKind
+	}
+	if gatewaySupported {
+		return gatewayKind
+	}
+	return ""
+}
+
 // convertToClusterSupportedKinds converts kinds to cluster supported kinds
 func (d *Service) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
 	lobj, _ := k8sschema.ConvertToLiasonScheme(obj)
+	if objs, ok := d.convertGatewayAPIToClusterSupportedKinds(obj, supportedKinds, targetCluster.Spec); ok {
+		return objs, true
+	}
 	if common.IsStringPresent(supportedKinds, routeKind) {
 		if _, ok := obj.(*okdroutev1.Route); ok {
 			return []runtime.Object{obj}, true
@@ -115,6 +170,17 @@ func (d *Service) convertToClusterSupportedKinds(obj runtime.Object, supportedKi
 		if _, ok := lobj.(*core.Service); ok {
 			return []runtime.Object{obj}, true
 		}
+	} else if isGatewayAPISupported(targetCluster.Spec, gatewayKind, httpRouteKind) {
+		if route, ok := obj.(*okdroutev1.Route); ok {
+			ingress := d.routeToIngress(*route, ir, targetCluster.Spec)[0].(*networking.Ingress)
+			return d.ingressToGatewayAPI(*ingress), true
+		}
+		if ingress, ok := lobj.(*networking.Ingress); ok {
+			return d.ingressToGatewayAPI(*ingress), true
+		}
+		if _, ok := lobj.(*core.Service); ok {
+			return []runtime.Object{obj}, true
+		}
 	} else {
 		if route, ok := obj.(*okdroutev1.Route); ok {
 			return d.routeToService(*route), true
@@ -271,11 +337,15 @@ func (d *Service) ingressToService(ingress networking.Ingress) []runtime.Object
 func (d *Service) createRoutes(service irtypes.Service, ir irtypes.EnhancedIR, targetClusterSpec collecttypes.ClusterMetadataSpec) [](*okdroutev1.Route) {
 	routes := [](*okdroutev1.Route){}
 	servicePorts, hostPrefixes, relPaths, _ := d.getExposeInfo(service)
+	tls := tlsConfig{}
 	for i, servicePort := range servicePorts {
 		if relPaths[i] == "" {
 			continue
 		}
-		route := d.createRoute(ir.Name, service, servicePort, hostPrefixes[i], relPaths[i], ir, targetClusterSpec)
+		if tls.tlsType == "" {
+			tls = d.getTLSConfig(service, routeKind, targetClusterSpec)
+		}
+		route := d.createRoute(ir.Name, service, servicePort, hostPrefixes[i], relPaths[i], tls, ir, targetClusterSpec)
 		routes = append(routes, route)
 	}
 	return routes
@@ -286,7 +356,7 @@ func (d *Service) createRoutes(service irtypes.Service, ir irtypes.EnhancedIR, t
 //[https://bugzilla.redhat.com/show_bug.cgi?id=1773682]
 // Can't use https because of this https://github.com/openshift/origin/issues/2162
 // When service has multiple ports,the route needs a port name. Port number doesn't seem to work.
-func (d *Service) createRoute(irName string, service irtypes.Service, port core.ServicePort, hostprefix, path string, ir irtypes.EnhancedIR, targetClusterSpec collecttypes.ClusterMetadataSpec) *okdroutev1.Route {
+func (d *Service) createRoute(irName string, service irtypes.Service, port core.ServicePort, hostprefix, path string, tls tlsConfig, ir irtypes.EnhancedIR, targetClusterSpec collecttypes.ClusterMetadataSpec) *okdroutev1.Route {
 	weight := int32(1)                                    //Hard-coded to 1 to avoid Helm v3 errors
 	ingressArray := []okdroutev1.RouteIngress{{Host: ""}} //Hard-coded to empty string to avoid Helm v3 errors
 
@@ -316,6 +386,7 @@ func (d *Service) createRoute(irName string, service irtypes.Service, port core.
 				Weight: &weight,
 			},
 			Port: &okdroutev1.RoutePort{TargetPort: intstr.IntOrString{Type: intstr.String, StrVal: port.Name}},
+			TLS:  getRouteTLSConfig(tls),
 		},
 		Status: okdroutev1.RouteStatus{
 			Ingress: ingressArray,
@@ -363,13 +434,7 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetClusterSpec collect
 
 	// Configure the rule with the above fan-out paths
 	rules := []networking.IngressRule{}
-	host := targetClusterSpec.Host
-	secretName := ""
-	if host == "" {
-		host = commonqa.IngressHost(d.getHostName(ir.Name))
-	}
-	defaultSecretName := ""
-	secretName = qaengine.FetchStringAnswer(common.ConfigIngressTLSKey, "Provide the TLS secret for ingress", []string{"Leave empty to use http"}, defaultSecretName)
+	host := d.getIngressHost(ir.Name, targetClusterSpec)
 	for hostprefix, httpIngressPaths := range hostHTTPIngressPaths {
 		ph := host
 		if hostprefix != "" {
@@ -386,10 +451,18 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetClusterSpec collect
 	}
 
 	tls := []networking.IngressTLS{}
-	if secretName != "" {
-		tls = []networking.IngressTLS{{Hosts: []string{host},
-			SecretName: secretName,
-		}}
+	for _, h := range d.getHostTLSs(ir, common.IngressKind, targetClusterSpec) {
+		found := false
+		for i, t := range tls {
+			if t.SecretName == h.secretName {
+				tls[i].Hosts = append(tls[i].Hosts, h.hostname)
+				found = true
+				break
+			}
+		}
+		if !found {
+			tls = append(tls, networking.IngressTLS{Hosts: []string{h.hostname}, SecretName: h.secretName})
+		}
 	}
 
 	ingressName := ir.Name
@@ -436,6 +509,35 @@ func (d *Service) createService(service irtypes.Service) *core.Service {
 	return svc
 }
 
+// createHeadlessService creates the headless service which gives the pods of a StatefulSet stable network identities
+func (d *Service) createHeadlessService(service irtypes.Service) *core.Service {
+	ports, _, _, _ := d.getExposeInfo(service)
+	svc := &core.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       common.ServiceKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getHeadlessServiceName(service.Name),
+			Labels:      getServiceLabels(service.Name),
+			Annotations: getAnnotations(service),
+		},
+		Spec: core.ServiceSpec{
+			Type:                     core.ServiceTypeClusterIP,
+			ClusterIP:                "None",
+			Selector:                 getServiceLabels(service.Name),
+			Ports:                    ports,
+			PublishNotReadyAddresses: true,
+		},
+	}
+	return svc
+}
+
+// getHeadlessServiceName returns the name of the headless service of a StatefulSet
+func getHeadlessServiceName(serviceName string) string {
+	return serviceName + "-headless"
+}
+
 // GetServicePorts configure the container service ports.
 func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.ServicePort, hostPrefixes []string, relPaths []string, serviceType core.ServiceType) {
 	servicePorts = []core.ServicePort{}
@@ -0,0 +1,164 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// noServiceMesh is used when the services are not added to a service mesh
+	noServiceMesh = "none"
+	// istioServiceMesh is used when the services are added to the istio service mesh
+	istioServiceMesh = "istio"
+	// linkerdServiceMesh is used when the services are added to the linkerd service mesh
+	linkerdServiceMesh = "linkerd"
+	// istioSidecarInjectAnnotation is the pod annotation which enables the istio sidecar injection
+	istioSidecarInjectAnnotation = "sidecar.istio.io/inject"
+	// linkerdInjectAnnotation is the pod annotation which enables the linkerd proxy injection
+	linkerdInjectAnnotation = "linkerd.io/inject"
+)
+
+// getServiceMesh returns the service mesh to which the services are added.
+// A service mesh is available only if the target cluster supports its resources.
+func getServiceMesh(targetClusterSpec collecttypes.ClusterMetadataSpec) string {
+	serviceMeshes := []string{}
+	if targetClusterSpec.GetSupportedVersions(k8sschema.VirtualServiceKind) != nil && targetClusterSpec.GetSupportedVersions(k8sschema.PeerAuthenticationKind) != nil {
+		serviceMeshes = append(serviceMeshes, istioServiceMesh)
+	}
+	if targetClusterSpec.GetSupportedVersions(k8sschema.LinkerdServerKind) != nil && targetClusterSpec.GetSupportedVersions(k8sschema.ServerAuthorizationKind) != nil {
+		serviceMeshes = append(serviceMeshes, linkerdServiceMesh)
+	}
+	if len(serviceMeshes) == 0 {
+		return noServiceMesh
+	}
+	return qaengine.FetchSelectAnswer(common.ConfigServiceMeshTypeKey, "Select the service mesh to add the services to :", []string{"The sidecars are injected into the pods and the traffic between the services is secured using mutual TLS"}, serviceMeshes[0], append([]string{noServiceMesh}, serviceMeshes...))
+}
+
+// addServiceToServiceMesh annotates the pods of the service for the sidecar injection and runs them using the service account of the service
+func addServiceToServiceMesh(service irtypes.Service, serviceMesh string) irtypes.Service {
+	if serviceMesh == noServiceMesh || service.OnlyIngress || len(service.Containers) == 0 {
+		return service
+	}
+	annotations := getAnnotations(service)
+	switch serviceMesh {
+	case istioServiceMesh:
+		annotations[istioSidecarInjectAnnotation] = "true"
+	case linkerdServiceMesh:
+		annotations[linkerdInjectAnnotation] = "enabled"
+	}
+	service.Annotations = annotations
+	service.ServiceAccountName = getServiceAccountNameForMesh(service)
+	return service
+}
+
+// getServiceAccountNameForMesh returns the service account which identifies the pods of the service in the service mesh
+func getServiceAccountNameForMesh(service irtypes.Service) string {
+	if service.ServiceAccountName == "" || service.ServiceAccountName == "default" {
+		return service.Name
+	}
+	return service.ServiceAccountName
+}
+
+// createServiceAccountsForMesh creates the service accounts of the services which do not have one already
+func createServiceAccountsForMesh(ir irtypes.EnhancedIR) []runtime.Object {
+	objs := []runtime.Object{}
+	for _, serviceName := range getMeshServiceNames(ir) {
+		service := ir.Services[serviceName]
+		if getServiceAccountNameForMesh(service) != service.ServiceAccountName {
+			objs = append(objs, &core.ServiceAccount{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       rbacv1.ServiceAccountKind,
+					APIVersion: core.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   service.Name,
+					Labels: getServiceLabels(service.Name),
+				},
+			})
+		}
+	}
+	return objs
+}
+
+// getMeshServiceNames returns the sorted names of the services whose pods run in the service mesh
+func getMeshServiceNames(ir irtypes.EnhancedIR) []string {
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress || len(service.Containers) == 0 {
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
+
+// getLinkingServiceAccountNames returns the sorted service accounts of the services which connect to the service
+func getLinkingServiceAccountNames(ir irtypes.EnhancedIR, serviceName string) []string {
+	serviceAccountNames := []string{}
+	for _, name := range getMeshServiceNames(ir) {
+		linkingService := ir.Services[name]
+		if name == serviceName || !common.IsStringPresent(linkingService.Links, serviceName) {
+			continue
+		}
+		if serviceAccountName := getServiceAccountNameForMesh(linkingService); !common.IsStringPresent(serviceAccountNames, serviceAccountName) {
+			serviceAccountNames = append(serviceAccountNames, serviceAccountName)
+		}
+	}
+	sort.Strings(serviceAccountNames)
+	return serviceAccountNames
+}
+
+// getIngressBackendServiceNames returns the names of the services which receive the traffic from outside the cluster through the ingress
+func getIngressBackendServiceNames(ir irtypes.EnhancedIR) []string {
+	d := new(Service)
+	serviceNames := []string{}
+	for _, service := range ir.Services {
+		_, _, relPaths, _ := d.getExposeInfo(service)
+		for _, relPath := range relPaths {
+			if relPath == "" {
+				continue
+			}
+			backendServiceName := service.BackendServiceName
+			if backendServiceName == "" {
+				backendServiceName = service.Name
+			}
+			if !common.IsStringPresent(serviceNames, backendServiceName) {
+				serviceNames = append(serviceNames, backendServiceName)
+			}
+			break
+		}
+	}
+	return serviceNames
+}
+
+// isExposedOutsideMesh returns true if the clients outside the service mesh can connect to the service directly using a node port or a load balancer
+func isExposedOutsideMesh(service irtypes.Service) bool {
+	_, _, _, serviceType := new(Service).getExposeInfo(service)
+	return serviceType == core.ServiceTypeNodePort || serviceType == core.ServiceTypeLoadBalancer
+}
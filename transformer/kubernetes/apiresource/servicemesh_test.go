@@ -0,0 +1,202 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func getServiceMeshTestIR() irtypes.EnhancedIR {
+	return getServiceMeshTestIRWithLinks(map[string][]string{"frontend": {"api"}, "api": {"db"}, "db": nil}, "frontend")
+}
+
+// getServiceMeshTestIRWithLinks returns an IR with the services and their links, where the exposed service is routed through the ingress
+func getServiceMeshTestIRWithLinks(serviceLinks map[string][]string, exposedServiceName string) irtypes.EnhancedIR {
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	for name, links := range serviceLinks {
+		service := irtypes.NewServiceWithName(name)
+		service.Containers = []core.Container{{Name: name, Image: name}}
+		service.Links = links
+		forwarding := irtypes.ServiceToPodPortForwarding{ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 8080}}
+		if name == exposedServiceName {
+			forwarding.ServiceRelPath = "/"
+		}
+		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{forwarding}
+		ir.Services[name] = service
+	}
+	return irtypes.NewEnhancedIRFromIR(ir)
+}
+
+func getServiceMeshTestCluster(kinds ...string) collecttypes.ClusterMetadata {
+	cluster := collecttypes.ClusterMetadata{Spec: collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"Deployment": {"apps/v1"}, "Service": {"v1"}, "Ingress": {"networking.k8s.io/v1"}}}}
+	for _, kind := range kinds {
+		cluster.Spec.APIKindVersionMap[kind] = []string{"v1beta1"}
+	}
+	return cluster
+}
+
+func TestIstio(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	ir := getServiceMeshTestIR()
+	cluster := getServiceMeshTestCluster(k8sschema.VirtualServiceKind, k8sschema.DestinationRuleKind, k8sschema.IstioGatewayKind, k8sschema.PeerAuthenticationKind, k8sschema.AuthorizationPolicyKind)
+	objs := new(Istio).createNewResources(ir, nil, cluster)
+	principals := map[string][]string{}
+	gatewayFound := false
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *k8sschema.AuthorizationPolicy:
+			principals[o.Name] = nil
+			for _, rule := range o.Spec.Rules {
+				for _, from := range rule.From {
+					principals[o.Name] = append(principals[o.Name], from.Source.Principals...)
+				}
+			}
+		case *k8sschema.IstioGateway:
+			gatewayFound = true
+		case *k8sschema.PeerAuthentication:
+			if o.Spec.MTLS == nil || o.Spec.MTLS.Mode != strictMTLSMode {
+				t.Errorf("Expected the mutual TLS mode to be %s. Actual: %+v", strictMTLSMode, o.Spec.MTLS)
+			}
+		}
+	}
+	expectedPrincipals := map[string][]string{"api": {"*/sa/frontend"}, "db": {"*/sa/api"}}
+	if !reflect.DeepEqual(principals, expectedPrincipals) {
+		t.Errorf("Expected the authorization policies to allow %+v. Actual: %+v", expectedPrincipals, principals)
+	}
+	if !gatewayFound {
+		t.Errorf("Expected an istio gateway for the exposed service frontend. Actual objects: %+v", objs)
+	}
+	for _, obj := range new(Service).createNewResources(ir, []string{"Service", "Ingress"}, cluster) {
+		if _, ok := obj.(*networking.Ingress); ok {
+			t.Errorf("Expected no ingress when the services are exposed using the istio gateway")
+		}
+	}
+	for _, obj := range new(Deployment).createNewResources(ir, []string{"Deployment"}, cluster) {
+		deployment := obj.(*apps.Deployment)
+		if deployment.Spec.Template.Annotations[istioSidecarInjectAnnotation] != "true" || deployment.Spec.Template.Spec.ServiceAccountName != deployment.Name {
+			t.Errorf("Expected the pods of the deployment %s to be injected with the sidecar and run with their own service account. Actual: %+v", deployment.Name, deployment.Spec.Template)
+		}
+	}
+}
+
+func TestLinkerd(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	ir := getServiceMeshTestIR()
+	cluster := getServiceMeshTestCluster(k8sschema.LinkerdServerKind, k8sschema.ServerAuthorizationKind)
+	objs := new(Linkerd).createNewResources(ir, nil, cluster)
+	servers := []string{}
+	clients := map[string][]k8sschema.LinkerdServiceAccountName{}
+	serviceAccounts := []string{}
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *k8sschema.LinkerdServer:
+			servers = append(servers, o.Name)
+		case *k8sschema.ServerAuthorization:
+			clients[o.Spec.Server.Name] = o.Spec.Client.MeshTLS.ServiceAccounts
+		case *core.ServiceAccount:
+			serviceAccounts = append(serviceAccounts, o.Name)
+		}
+	}
+	if expected := []string{"api-8080", "db-8080"}; !reflect.DeepEqual(servers, expected) {
+		t.Errorf("Expected the servers %+v for the services which are not exposed. Actual: %+v", expected, servers)
+	}
+	expectedClients := map[string][]k8sschema.LinkerdServiceAccountName{"api-8080": {{Name: "frontend"}}, "db-8080": {{Name: "api"}}}
+	if !reflect.DeepEqual(clients, expectedClients) {
+		t.Errorf("Expected the server authorizations to allow %+v. Actual: %+v", expectedClients, clients)
+	}
+	if expected := []string{"api", "db", "frontend"}; !reflect.DeepEqual(serviceAccounts, expected) {
+		t.Errorf("Expected the service accounts %+v. Actual: %+v", expected, serviceAccounts)
+	}
+	for _, obj := range new(Deployment).createNewResources(ir, []string{"Deployment"}, cluster) {
+		if deployment := obj.(*apps.Deployment); deployment.Spec.Template.Annotations[linkerdInjectAnnotation] != "enabled" {
+			t.Errorf("Expected the pods of the deployment %s to be injected with the linkerd proxy. Actual: %+v", deployment.Name, deployment.Spec.Template.Annotations)
+		}
+	}
+}
+
+func TestServiceMeshNotSupported(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	ir := getServiceMeshTestIR()
+	cluster := getServiceMeshTestCluster()
+	objs := append(new(Istio).createNewResources(ir, nil, cluster), new(Linkerd).createNewResources(ir, nil, cluster)...)
+	if len(objs) != 0 {
+		t.Errorf("Expected no service mesh resources when the cluster does not support them. Actual: %+v", objs)
+	}
+	for _, obj := range new(Deployment).createNewResources(ir, []string{"Deployment"}, cluster) {
+		if deployment := obj.(*apps.Deployment); len(deployment.Spec.Template.Annotations) != 0 {
+			t.Errorf("Expected no injection annotations when the cluster does not support a service mesh. Actual: %+v", deployment.Spec.Template.Annotations)
+		}
+	}
+}
+
+func TestServiceMeshPartialLinks(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	ingressGatewayPrincipal := "cluster.local/ns/gateways/sa/ingress"
+	addTestAnswerEngine(t, map[string]interface{}{common.ConfigServiceMeshIngressGatewayPrincipalKey: ingressGatewayPrincipal})
+	// Only web and store are linked to. The cache and the batch services are not restricted.
+	ir := getServiceMeshTestIRWithLinks(map[string][]string{"web": nil, "worker": {"web", "store", "unknown"}, "store": nil, "cache": nil, "batch": {"unknown"}}, "web")
+
+	t.Run("istio", func(t *testing.T) {
+		cluster := getServiceMeshTestCluster(k8sschema.VirtualServiceKind, k8sschema.DestinationRuleKind, k8sschema.IstioGatewayKind, k8sschema.PeerAuthenticationKind, k8sschema.AuthorizationPolicyKind)
+		principals := map[string][]string{}
+		for _, obj := range new(Istio).createNewResources(ir, nil, cluster) {
+			if policy, ok := obj.(*k8sschema.AuthorizationPolicy); ok {
+				for _, rule := range policy.Spec.Rules {
+					for _, from := range rule.From {
+						principals[policy.Name] = append(principals[policy.Name], from.Source.Principals...)
+					}
+				}
+			}
+		}
+		want := map[string][]string{"web": {"*/sa/worker", ingressGatewayPrincipal}, "store": {"*/sa/worker"}}
+		if diff := cmp.Diff(want, principals); diff != "" {
+			t.Fatalf("The authorization policies are incorrect. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("linkerd", func(t *testing.T) {
+		cluster := getServiceMeshTestCluster(k8sschema.LinkerdServerKind, k8sschema.ServerAuthorizationKind)
+		servers := []string{}
+		clients := map[string][]k8sschema.LinkerdServiceAccountName{}
+		for _, obj := range new(Linkerd).createNewResources(ir, nil, cluster) {
+			switch o := obj.(type) {
+			case *k8sschema.LinkerdServer:
+				servers = append(servers, o.Name)
+			case *k8sschema.ServerAuthorization:
+				clients[o.Spec.Server.Name] = o.Spec.Client.MeshTLS.ServiceAccounts
+			}
+		}
+		if diff := cmp.Diff([]string{"store-8080"}, servers); diff != "" {
+			t.Fatalf("The servers are incorrect. Differences:\n%s", diff)
+		}
+		if diff := cmp.Diff(map[string][]k8sschema.LinkerdServiceAccountName{"store-8080": {{Name: "worker"}}}, clients); diff != "" {
+			t.Fatalf("The server authorizations are incorrect. Differences:\n%s", diff)
+		}
+	})
+}
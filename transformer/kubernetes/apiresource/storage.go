@@ -17,7 +17,10 @@
 package apiresource
 
 import (
+	"crypto/rsa"
+
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
@@ -28,24 +31,45 @@ import (
 
 // Storage handles all storage objectss
 type Storage struct {
+	sealedSecretsPublicKey *rsa.PublicKey
+	// secretPlaceholders are the placeholders of the secrets in the order they were created
+	secretPlaceholders []string
+	// secretPlaceholderSources has the secret and key of each placeholder
+	secretPlaceholderSources map[string]string
 }
 
 // getSupportedKinds returns cluster supported kinds
 func (s *Storage) getSupportedKinds() []string {
-	return []string{string(irtypes.PVCKind), string(irtypes.ConfigMapKind), string(irtypes.SecretKind)}
+	return []string{string(irtypes.PVCKind), string(irtypes.ConfigMapKind), string(irtypes.SecretKind), k8sschema.ExternalSecretKind, k8sschema.SealedSecretKind}
 }
 
 // createNewResources converts IR objects to runtime objects
 func (s *Storage) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
+	secretsMode := ""
 	for _, stObj := range ir.Storages {
 		if stObj.StorageType == irtypes.ConfigMapKind {
 			objs = append(objs, s.createConfigMap(stObj))
 		}
-		if stObj.StorageType == irtypes.SecretKind || stObj.StorageType == irtypes.PullSecretKind {
+		if stObj.StorageType == irtypes.SecretKind {
+			if secretsMode == "" {
+				secretsMode = getSecretsMode(targetCluster.Spec)
+			}
+			secret, err := s.createSecretForMode(stObj, secretsMode)
+			if err != nil {
+				logrus.Errorf("Unable to create the secret %s : %s", stObj.Name, err)
+				continue
+			}
+			objs = append(objs, secret)
+		}
+		if stObj.StorageType == irtypes.PullSecretKind {
 			objs = append(objs, s.createSecret(stObj))
 		}
 		if stObj.StorageType == irtypes.PVCKind {
+			if isUsedOnlyByStatefulServices(stObj, ir.Services) {
+				logrus.Debugf("PVC %s is created using the volume claim templates of the stateful services", stObj.Name)
+				continue
+			}
 			objs = append(objs, s.createPVC(stObj))
 		}
 	}
@@ -117,6 +141,21 @@ func (s *Storage) createPVC(st irtypes.Storage) *core.PersistentVolumeClaim {
 	return pvc
 }
 
+// isUsedOnlyByStatefulServices returns true if the PVC storage is used by at least one service, and all of them are stateful
+func isUsedOnlyByStatefulServices(st irtypes.Storage, services map[string]irtypes.Service) bool {
+	used := false
+	for _, service := range services {
+		if len(getVolumeClaimStorages(service, []irtypes.Storage{st})) == 0 {
+			continue
+		}
+		if !service.Stateful {
+			return false
+		}
+		used = true
+	}
+	return used
+}
+
 func convertPVCVolumeToEmptyVolume(vPVC core.Volume) *core.Volume {
 	vEmptySrc := &core.VolumeSource{
 		EmptyDir: &core.EmptyDirVolumeSource{},
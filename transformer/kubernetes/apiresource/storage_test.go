@@ -0,0 +1,86 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestIsUsedOnlyByStatefulServices(t *testing.T) {
+	getService := func(name string, stateful bool, claimNames ...string) irtypes.Service {
+		service := irtypes.NewServiceWithName(name)
+		service.Stateful = stateful
+		for _, claimName := range claimNames {
+			service.Volumes = append(service.Volumes, core.Volume{
+				Name:         claimName + "-vol",
+				VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: claimName}},
+			})
+		}
+		return service
+	}
+	testcases := []struct {
+		name     string
+		storage  irtypes.Storage
+		services []irtypes.Service
+		want     bool
+	}{
+		{
+			name:     "used by a stateful service",
+			storage:  irtypes.Storage{Name: "data", StorageType: irtypes.PVCKind},
+			services: []irtypes.Service{getService("db", true, "data"), getService("web", false, "other")},
+			want:     true,
+		},
+		{
+			name:     "used by stateful and stateless services",
+			storage:  irtypes.Storage{Name: "data", StorageType: irtypes.PVCKind},
+			services: []irtypes.Service{getService("db", true, "data"), getService("web", false, "data")},
+			want:     false,
+		},
+		{
+			name:     "used by a stateless service",
+			storage:  irtypes.Storage{Name: "data", StorageType: irtypes.PVCKind},
+			services: []irtypes.Service{getService("web", false, "data")},
+			want:     false,
+		},
+		{
+			name:     "not used",
+			storage:  irtypes.Storage{Name: "data", StorageType: irtypes.PVCKind},
+			services: []irtypes.Service{getService("db", true, "other")},
+			want:     false,
+		},
+		{
+			name:     "not a PVC",
+			storage:  irtypes.Storage{Name: "data", StorageType: irtypes.ConfigMapKind},
+			services: []irtypes.Service{getService("db", true, "data")},
+			want:     false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			services := map[string]irtypes.Service{}
+			for _, service := range tc.services {
+				services[service.Name] = service
+			}
+			if actual := isUsedOnlyByStatefulServices(tc.storage, services); actual != tc.want {
+				t.Fatalf("expected %t. Actual: %t", tc.want, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"sort"
+
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	okdroutev1 "github.com/openshift/api/route/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	noneTLSType        = "none"
+	secretTLSType      = "secret"
+	certManagerTLSType = "certmanager"
+	edgeTLSType        = "edge"
+	reencryptTLSType   = "reencrypt"
+	// defaultIssuerName is the default name of the cert-manager issuer of the certificates
+	defaultIssuerName = "letsencrypt"
+)
+
+// tlsConfig stores how the TLS of an exposed service is configured
+type tlsConfig struct {
+	tlsType    string
+	secretName string
+	issuerName string
+	issuerKind string
+}
+
+// hostTLS stores the TLS secret of a host
+type hostTLS struct {
+	hostname   string
+	secretName string
+}
+
+// getTLSConfig asks the user how to configure the TLS of the exposed service
+func (d *Service) getTLSConfig(service irtypes.Service, exposeKind string, targetClusterSpec collecttypes.ClusterMetadataSpec) tlsConfig {
+	keyPrefix := common.ConfigServicesKey + common.Delim + `"` + service.Name + `"` + common.Delim + common.ConfigTLSForServiceKeySegment + common.Delim
+	if exposeKind == routeKind {
+		tlsType := qaengine.FetchSelectAnswer(keyPrefix+"type", fmt.Sprintf("Select the TLS termination of the route of the service %s :", service.Name), []string{"edge: TLS is terminated at the router, reencrypt: TLS is terminated at the router and the traffic to the service is encrypted again"}, noneTLSType, []string{noneTLSType, edgeTLSType, reencryptTLSType})
+		return tlsConfig{tlsType: tlsType}
+	}
+	defaultSecretName := qaengine.FetchStringAnswer(common.ConfigIngressTLSKey, "Provide the TLS secret for ingress", []string{"Used as the default TLS secret of the services. Leave empty to use http by default"}, "")
+	defaultTLSType := noneTLSType
+	if defaultSecretName != "" {
+		defaultTLSType = secretTLSType
+	}
+	tlsType := qaengine.FetchSelectAnswer(keyPrefix+"type", fmt.Sprintf("Select the TLS configuration of the service %s :", service.Name), []string{"secret: use an existing TLS secret, certmanager: create a cert-manager Certificate, if the cluster supports it"}, defaultTLSType, []string{noneTLSType, secretTLSType, certManagerTLSType})
+	if tlsType == certManagerTLSType && len(targetClusterSpec.GetSupportedVersions(cmapi.CertificateKind)) == 0 {
+		logrus.Warnf("Unable to create a cert-manager Certificate for the service %s since the cluster does not support it. Using a TLS secret instead", service.Name)
+		tlsType = secretTLSType
+	}
+	switch tlsType {
+	case secretTLSType:
+		if defaultSecretName == "" {
+			defaultSecretName = getTLSSecretName(service.Name)
+		}
+		secretName := qaengine.FetchStringAnswer(keyPrefix+"secret", fmt.Sprintf("Provide the TLS secret of the service %s :", service.Name), []string{"The secret should be of type kubernetes.io/tls"}, defaultSecretName)
+		return tlsConfig{tlsType: tlsType, secretName: secretName}
+	case certManagerTLSType:
+		issuerKind := qaengine.FetchSelectAnswer(keyPrefix+"issuerkind", fmt.Sprintf("Select the kind of the cert-manager issuer of the certificate of the service %s :", service.Name), nil, cmapi.ClusterIssuerKind, []string{cmapi.ClusterIssuerKind, cmapi.IssuerKind})
+		issuerName := qaengine.FetchStringAnswer(keyPrefix+"issuer", fmt.Sprintf("Provide the name of the cert-manager %s of the certificate of the service %s :", issuerKind, service.Name), []string{"The issuer should already exist in the cluster"}, defaultIssuerName)
+		return tlsConfig{tlsType: tlsType, secretName: getTLSSecretName(service.Name), issuerName: issuerName, issuerKind: issuerKind}
+	}
+	return tlsConfig{tlsType: noneTLSType}
+}
+
+// getHostTLSs returns the TLS secrets of the hosts on which the services are exposed.
+// The services without a host prefix share a host, in which case the secret of the first service is used for it.
+func (d *Service) getHostTLSs(ir irtypes.EnhancedIR, exposeKind string, targetClusterSpec collecttypes.ClusterMetadataSpec) []hostTLS {
+	hostTLSs := []hostTLS{}
+	for _, serviceName := range getSortedServiceNames(ir) {
+		service := ir.Services[serviceName]
+		hostnames := d.getExposedHostnames(service, ir.Name, targetClusterSpec)
+		if len(hostnames) == 0 {
+			continue
+		}
+		if serviceTLS := d.getTLSConfig(service, exposeKind, targetClusterSpec); serviceTLS.secretName != "" {
+			hostTLSs = addHostTLS(hostTLSs, hostnames, serviceTLS.secretName)
+		}
+	}
+	return hostTLSs
+}
+
+// createCertificates creates the cert-manager certificates of the exposed services.
+// A certificate is created only for the hosts which use its secret.
+func (d *Service) createCertificates(ir irtypes.EnhancedIR, exposeKind string, targetClusterSpec collecttypes.ClusterMetadataSpec) []runtime.Object {
+	hostTLSs := d.getHostTLSs(ir, exposeKind, targetClusterSpec)
+	objs := []runtime.Object{}
+	for _, serviceName := range getSortedServiceNames(ir) {
+		service := ir.Services[serviceName]
+		if len(d.getExposedHostnames(service, ir.Name, targetClusterSpec)) == 0 {
+			continue
+		}
+		tls := d.getTLSConfig(service, exposeKind, targetClusterSpec)
+		if tls.tlsType != certManagerTLSType {
+			continue
+		}
+		hostnames := []string{}
+		for _, h := range hostTLSs {
+			if h.secretName == tls.secretName {
+				hostnames = append(hostnames, h.hostname)
+			}
+		}
+		if len(hostnames) == 0 {
+			logrus.Warnf("The hosts of the service %s use the TLS secrets of other services. Not creating the certificate %s", service.Name, tls.secretName)
+			continue
+		}
+		objs = append(objs, &cmapi.Certificate{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       cmapi.CertificateKind,
+				APIVersion: cmapi.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   tls.secretName,
+				Labels: getServiceLabels(service.Name),
+			},
+			Spec: cmapi.CertificateSpec{
+				SecretName: tls.secretName,
+				DNSNames:   hostnames,
+				IssuerRef: cmmeta.ObjectReference{
+					Name:  tls.issuerName,
+					Kind:  tls.issuerKind,
+					Group: cmapi.SchemeGroupVersion.Group,
+				},
+			},
+		})
+	}
+	return objs
+}
+
+// getSortedServiceNames returns the sorted names of the services
+func getSortedServiceNames(ir irtypes.EnhancedIR) []string {
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
+
+// getExposedHostnames returns the hostnames on which the service is exposed
+func (d *Service) getExposedHostnames(service irtypes.Service, irName string, targetClusterSpec collecttypes.ClusterMetadataSpec) []string {
+	_, hostPrefixes, relPaths, _ := d.getExposeInfo(service)
+	hostnames := []string{}
+	for i, relPath := range relPaths {
+		if relPath == "" {
+			continue
+		}
+		hostname := d.getIngressHost(irName, targetClusterSpec)
+		if hostPrefixes[i] != "" {
+			hostname = hostPrefixes[i] + "." + hostname
+		}
+		if !common.IsStringPresent(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// getIngressHost returns the host domain of the cluster
+func (d *Service) getIngressHost(irName string, targetClusterSpec collecttypes.ClusterMetadataSpec) string {
+	if targetClusterSpec.Host != "" {
+		return targetClusterSpec.Host
+	}
+	return commonqa.IngressHost(d.getHostName(irName))
+}
+
+// getRouteTLSConfig returns the TLS config of the route
+func getRouteTLSConfig(tls tlsConfig) *okdroutev1.TLSConfig {
+	switch tls.tlsType {
+	case edgeTLSType:
+		return &okdroutev1.TLSConfig{Termination: okdroutev1.TLSTerminationEdge, InsecureEdgeTerminationPolicy: okdroutev1.InsecureEdgeTerminationPolicyRedirect}
+	case reencryptTLSType:
+		return &okdroutev1.TLSConfig{Termination: okdroutev1.TLSTerminationReencrypt, InsecureEdgeTerminationPolicy: okdroutev1.InsecureEdgeTerminationPolicyRedirect}
+	}
+	return nil
+}
+
+// addHostTLS adds the TLS secret of the hostnames if it is not already added
+func addHostTLS(hostTLSs []hostTLS, hostnames []string, secretName string) []hostTLS {
+	for _, hostname := range hostnames {
+		found := false
+		for _, h := range hostTLSs {
+			if h.hostname == hostname {
+				if h.secretName != secretName {
+					logrus.Warnf("The host %s already uses the TLS secret %s. Not using the TLS secret %s for it", hostname, h.secretName, secretName)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			hostTLSs = append(hostTLSs, hostTLS{hostname: hostname, secretName: secretName})
+		}
+	}
+	return hostTLSs
+}
+
+// getTLSSecretName returns the name of the TLS secret of the service
+func getTLSSecretName(serviceName string) string {
+	return serviceName + "-tls"
+}
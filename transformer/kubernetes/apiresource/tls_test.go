@@ -0,0 +1,150 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestGetTLSConfig(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	tlsKey := func(service, key string) string {
+		return common.ConfigServicesKey + common.Delim + `"` + service + `"` + common.Delim + common.ConfigTLSForServiceKeySegment + common.Delim + key
+	}
+	addTestAnswerEngine(t, map[string]interface{}{
+		tlsKey("edge", "type"):                   edgeTLSType,
+		tlsKey("secret", "type"):                 secretTLSType,
+		tlsKey("namedsecret", "type"):            secretTLSType,
+		tlsKey("namedsecret", "secret"):          "mysecret",
+		tlsKey("certmanager", "type"):            certManagerTLSType,
+		tlsKey("issuer", "type"):                 certManagerTLSType,
+		tlsKey("issuer", "issuerkind"):           cmapi.IssuerKind,
+		tlsKey("issuer", "issuer"):               "myissuer",
+		tlsKey("unsupportedcertmanager", "type"): certManagerTLSType,
+	})
+	certManagerCluster := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{cmapi.CertificateKind: {cmapi.SchemeGroupVersion.String()}}}
+	testcases := []struct {
+		service    string
+		exposeKind string
+		cluster    collecttypes.ClusterMetadataSpec
+		want       tlsConfig
+	}{
+		{service: "plain", exposeKind: common.IngressKind, want: tlsConfig{tlsType: noneTLSType}},
+		{service: "plainroute", exposeKind: routeKind, want: tlsConfig{tlsType: noneTLSType}},
+		{service: "edge", exposeKind: routeKind, want: tlsConfig{tlsType: edgeTLSType}},
+		{service: "secret", exposeKind: common.IngressKind, want: tlsConfig{tlsType: secretTLSType, secretName: "secret-tls"}},
+		{service: "namedsecret", exposeKind: gatewayKind, want: tlsConfig{tlsType: secretTLSType, secretName: "mysecret"}},
+		{service: "certmanager", exposeKind: common.IngressKind, cluster: certManagerCluster, want: tlsConfig{tlsType: certManagerTLSType, secretName: "certmanager-tls", issuerName: defaultIssuerName, issuerKind: cmapi.ClusterIssuerKind}},
+		{service: "issuer", exposeKind: common.IngressKind, cluster: certManagerCluster, want: tlsConfig{tlsType: certManagerTLSType, secretName: "issuer-tls", issuerName: "myissuer", issuerKind: cmapi.IssuerKind}},
+		{service: "unsupportedcertmanager", exposeKind: common.IngressKind, want: tlsConfig{tlsType: secretTLSType, secretName: "unsupportedcertmanager-tls"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.service, func(t *testing.T) {
+			actual := (&Service{}).getTLSConfig(irtypes.NewServiceWithName(tc.service), tc.exposeKind, tc.cluster)
+			if diff := cmp.Diff(tc.want, actual, cmp.AllowUnexported(tlsConfig{})); diff != "" {
+				t.Fatalf("The TLS config is incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateCertificates(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	tlsKey := func(service string) string {
+		return common.ConfigServicesKey + common.Delim + `"` + service + `"` + common.Delim + common.ConfigTLSForServiceKeySegment + common.Delim + "type"
+	}
+	addTestAnswerEngine(t, map[string]interface{}{tlsKey("web"): certManagerTLSType, tlsKey("api"): certManagerTLSType})
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{ServicePort: networking.ServiceBackendPort{Number: 8080}, ServiceRelPath: "/"}}
+	ir.Services["web"] = web
+	ir.Services["internal"] = irtypes.NewServiceWithName("internal")
+	certManagerCluster := collecttypes.ClusterMetadataSpec{Host: "example.com", APIKindVersionMap: map[string][]string{cmapi.CertificateKind: {cmapi.SchemeGroupVersion.String()}}}
+
+	t.Run("cluster which supports cert-manager", func(t *testing.T) {
+		objs := (&Service{}).createCertificates(irtypes.NewEnhancedIRFromIR(ir), common.IngressKind, certManagerCluster)
+		if len(objs) != 1 {
+			t.Fatalf("Expected a certificate for the service web. Actual: %+v", objs)
+		}
+		certificate, ok := objs[0].(*cmapi.Certificate)
+		if !ok {
+			t.Fatalf("Expected a certificate. Actual: %T", objs[0])
+		}
+		want := cmapi.CertificateSpec{SecretName: "web-tls", DNSNames: []string{"example.com"}}
+		want.IssuerRef.Name = defaultIssuerName
+		want.IssuerRef.Kind = cmapi.ClusterIssuerKind
+		want.IssuerRef.Group = cmapi.SchemeGroupVersion.Group
+		if diff := cmp.Diff(want, certificate.Spec); diff != "" {
+			t.Fatalf("The certificate is incorrect. Differences:\n%s", diff)
+		}
+	})
+
+	t.Run("services which share a host", func(t *testing.T) {
+		sharedHostIR := irtypes.NewIR()
+		sharedHostIR.Name = "myproject"
+		api := irtypes.NewServiceWithName("api")
+		api.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{ServicePort: networking.ServiceBackendPort{Number: 9090}, ServiceRelPath: "/api"}}
+		sharedHostIR.Services["api"] = api
+		sharedHostIR.Services["web"] = web
+		objs := (&Service{}).createCertificates(irtypes.NewEnhancedIRFromIR(sharedHostIR), common.IngressKind, certManagerCluster)
+		if len(objs) != 1 {
+			t.Fatalf("Expected only the certificate used by the shared host. Actual: %+v", objs)
+		}
+		if certificate := objs[0].(*cmapi.Certificate); certificate.Spec.SecretName != "api-tls" {
+			t.Fatalf("Expected the certificate of the first service on the host. Actual: %+v", certificate.Spec)
+		}
+	})
+
+	t.Run("cluster which does not support cert-manager", func(t *testing.T) {
+		cluster := collecttypes.ClusterMetadataSpec{Host: "example.com"}
+		if objs := (&Service{}).createCertificates(irtypes.NewEnhancedIRFromIR(ir), common.IngressKind, cluster); len(objs) != 0 {
+			t.Fatalf("Expected no certificates. Actual: %+v", objs)
+		}
+	})
+}
+
+func TestAddHostTLS(t *testing.T) {
+	testcases := []struct {
+		name       string
+		hostTLSs   []hostTLS
+		hostnames  []string
+		secretName string
+		want       []hostTLS
+	}{
+		{name: "new hosts", hostnames: []string{"a.example.com", "b.example.com"}, secretName: "tls", want: []hostTLS{{hostname: "a.example.com", secretName: "tls"}, {hostname: "b.example.com", secretName: "tls"}}},
+		{name: "same secret", hostTLSs: []hostTLS{{hostname: "example.com", secretName: "tls"}}, hostnames: []string{"example.com"}, secretName: "tls", want: []hostTLS{{hostname: "example.com", secretName: "tls"}}},
+		{name: "host with another secret", hostTLSs: []hostTLS{{hostname: "example.com", secretName: "web-tls"}}, hostnames: []string{"example.com", "api.example.com"}, secretName: "api-tls", want: []hostTLS{{hostname: "example.com", secretName: "web-tls"}, {hostname: "api.example.com", secretName: "api-tls"}}},
+		{name: "no hosts", hostTLSs: []hostTLS{{hostname: "example.com", secretName: "tls"}}, secretName: "other-tls", want: []hostTLS{{hostname: "example.com", secretName: "tls"}}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := addHostTLS(tc.hostTLSs, tc.hostnames, tc.secretName)
+			if diff := cmp.Diff(tc.want, actual, cmp.AllowUnexported(hostTLS{})); diff != "" {
+				t.Fatalf("The host TLS secrets are incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -53,6 +54,16 @@ func TransformIRAndPersist(ir irtypes.EnhancedIR, outputPath string, apis []IAPI
 		logrus.Errorf("Failed to write the transformed objects to the directory at path %s . Error: %q", outputPath, err)
 		return nil, err
 	}
+	for _, apiResource := range apis {
+		if storage, ok := apiResource.(*Storage); ok {
+			templatePath, err := storage.writeSecretsEnvTemplate(outputPath)
+			if err != nil {
+				logrus.Errorf("Unable to write the placeholders of the secrets to the directory at path %s : %s", outputPath, err)
+			} else if templatePath != "" {
+				filesWritten = append(filesWritten, templatePath)
+			}
+		}
+	}
 	return filesWritten, nil
 }
 
@@ -109,6 +120,13 @@ func convertVersion(objs []runtime.Object, clusterSpec collecttypes.ClusterMetad
 	newobjs := []runtime.Object{}
 	for _, obj := range objs {
 		fixedobj := fixer.Fix(obj)
+		if violations := k8sschema.GetPodSecurityViolations(fixedobj, common.PodSecurity); len(violations) != 0 {
+			name := ""
+			if objMeta, err := meta.Accessor(fixedobj); err == nil {
+				name = objMeta.GetName()
+			}
+			logrus.Warnf("The %s %s does not comply with the %s pod security level : %s", fixedobj.GetObjectKind().GroupVersionKind().Kind, name, common.PodSecurity, strings.Join(violations, "; "))
+		}
 		newobj, err := k8sschema.ConvertToSupportedVersion(fixedobj, clusterSpec)
 		if err != nil {
 			logrus.Errorf("Unable to convert to supported version. Writing as is : %s", err)
@@ -0,0 +1,57 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+// testAnswerEngine answers the problems with the given ids
+type testAnswerEngine struct {
+	answers map[string]interface{}
+}
+
+func (*testAnswerEngine) StartEngine() error {
+	return nil
+}
+
+func (*testAnswerEngine) IsInteractiveEngine() bool {
+	return false
+}
+
+func (e *testAnswerEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	answer, ok := e.answers[prob.ID]
+	if !ok {
+		return prob, fmt.Errorf("no answer for %s", prob.ID)
+	}
+	err := prob.SetAnswer(answer)
+	return prob, err
+}
+
+// addTestAnswerEngine answers the problems with the given ids until the end of the test
+func addTestAnswerEngine(t *testing.T, answers map[string]interface{}) {
+	t.Helper()
+	engine := &testAnswerEngine{answers: answers}
+	if err := qaengine.AddEngineHighestPriority(engine); err != nil {
+		t.Fatalf("Failed to add the QA engine. Error: %q", err)
+	}
+	t.Cleanup(func() { qaengine.RemoveEngine(engine) })
+}
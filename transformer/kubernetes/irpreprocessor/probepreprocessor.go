@@ -0,0 +1,190 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	httpProbeType    = "http"
+	tcpProbeType     = "tcp"
+	execProbeType    = "exec"
+	noneProbeType    = "none"
+	defaultProbePath = "/"
+	// defaultProbePeriodSeconds is the period of the startup probes which are created for the start up timeouts
+	defaultProbePeriodSeconds int32 = 10
+)
+
+// probePreprocessor infers the liveness, readiness and startup probes of the services
+type probePreprocessor struct {
+}
+
+func (pp probePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	for k, scObj := range ir.Services {
+		if scObj.OnlyIngress || scObj.Schedule != nil || scObj.RestartPolicy == core.RestartPolicyNever || scObj.RestartPolicy == core.RestartPolicyOnFailure || len(scObj.Containers) == 0 {
+			continue
+		}
+		scObj.Containers[0] = pp.setProbes(scObj, scObj.Containers[0])
+		ir.Services[k] = scObj
+	}
+	return ir, nil
+}
+
+// setProbes sets the probes of the main container of the service using the probe type selected by the user
+func (pp probePreprocessor) setProbes(service irtypes.Service, container core.Container) core.Container {
+	probe, startupTimeout := getInferredProbe(service, container)
+	inferredType := getProbeType(probe)
+	probeTypes := []string{httpProbeType, tcpProbeType}
+	if inferredType == execProbeType {
+		probeTypes = append(probeTypes, execProbeType)
+	}
+	probeTypes = append(probeTypes, noneProbeType)
+	keyPrefix := common.ConfigServicesKey + common.Delim + `"` + service.Name + `"` + common.Delim + common.ConfigProbesForServiceKeySegment + common.Delim
+	probeType := qaengine.FetchSelectAnswer(keyPrefix+"type", fmt.Sprintf("Select the health check to use for the probes of the service %s :", service.Name), []string{"http: HTTP GET request to an endpoint, tcp: TCP connection to the port, none: no probes"}, inferredType, probeTypes)
+	if probeType == noneProbeType {
+		container.LivenessProbe = nil
+		container.ReadinessProbe = nil
+		container.StartupProbe = nil
+		return container
+	}
+	if probeType != inferredType {
+		probe = nil
+	} else if container.LivenessProbe != nil {
+		// The probes specified in the source, like the healthcheck of compose services, are used as is
+		if container.ReadinessProbe == nil {
+			container.ReadinessProbe = getReadinessProbe(keyPrefix, service.Name, *container.LivenessProbe)
+		}
+		return container
+	}
+	if probeType != execProbeType {
+		port := getProbePort(service, container, probe)
+		if port == 0 {
+			logrus.Warnf("Unable to find a port for the %s probes of the service %s. Not creating probes", probeType, service.Name)
+			return container
+		}
+		handler := core.Handler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(int(port))}}
+		if probeType == httpProbeType {
+			path := defaultProbePath
+			if probe != nil && probe.HTTPGet != nil {
+				path = probe.HTTPGet.Path
+			}
+			path = qaengine.FetchStringAnswer(keyPrefix+"path", fmt.Sprintf("Enter the path of the health check endpoint of the service %s :", service.Name), []string{"The endpoint is used for the liveness and startup probes"}, path)
+			handler = core.Handler{HTTPGet: &core.HTTPGetAction{Path: path, Port: intstr.FromInt(int(port)), Scheme: core.URISchemeHTTP}}
+		}
+		if probe == nil {
+			probe = &core.Probe{}
+		}
+		probe.Handler = handler
+	}
+	if startupTimeout > 0 && container.StartupProbe == nil {
+		startupProbe := *probe
+		if startupProbe.PeriodSeconds == 0 {
+			startupProbe.PeriodSeconds = defaultProbePeriodSeconds
+		}
+		startupProbe.InitialDelaySeconds = 0
+		startupProbe.FailureThreshold = (startupTimeout + startupProbe.PeriodSeconds - 1) / startupProbe.PeriodSeconds
+		container.StartupProbe = &startupProbe
+	}
+	liveness := *probe
+	liveness.InitialDelaySeconds = 0
+	if container.StartupProbe == nil {
+		liveness.InitialDelaySeconds = probe.InitialDelaySeconds
+	}
+	container.LivenessProbe = &liveness
+	if container.ReadinessProbe == nil {
+		container.ReadinessProbe = getReadinessProbe(keyPrefix, service.Name, liveness)
+	}
+	return container
+}
+
+// getReadinessProbe returns a readiness probe which uses the same health check as the liveness probe.
+// HTTP readiness probes can use a different endpoint, like /actuator/health/readiness of Spring Boot apps.
+func getReadinessProbe(keyPrefix, serviceName string, liveness core.Probe) *core.Probe {
+	readiness := liveness
+	if liveness.HTTPGet != nil {
+		httpGet := *liveness.HTTPGet
+		httpGet.Path = qaengine.FetchStringAnswer(keyPrefix+"readinesspath", fmt.Sprintf("Enter the path of the readiness endpoint of the service %s :", serviceName), []string{"Pods do not receive traffic while the endpoint is failing"}, liveness.HTTPGet.Path)
+		readiness.HTTPGet = &httpGet
+	}
+	return &readiness
+}
+
+// getInferredProbe returns the probe inferred from the existing liveness probe, the health check of the service or the ports, along with the time the container needs to start
+func getInferredProbe(service irtypes.Service, container core.Container) (*core.Probe, int32) {
+	if container.LivenessProbe != nil {
+		probe := *container.LivenessProbe
+		return &probe, 0
+	}
+	if service.HealthCheck != nil {
+		switch service.HealthCheck.Type {
+		case irtypes.HTTPHealthCheckType:
+			path := service.HealthCheck.Path
+			if path == "" {
+				path = defaultProbePath
+			}
+			return &core.Probe{Handler: core.Handler{HTTPGet: &core.HTTPGetAction{Path: path, Port: intstr.FromInt(int(service.HealthCheck.Port)), Scheme: core.URISchemeHTTP}}}, service.HealthCheck.StartupTimeout
+		case irtypes.ProcessHealthCheckType:
+			return nil, 0
+		default:
+			return &core.Probe{Handler: core.Handler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(int(service.HealthCheck.Port))}}}, service.HealthCheck.StartupTimeout
+		}
+	}
+	if getProbePort(service, container, nil) != 0 {
+		return &core.Probe{Handler: core.Handler{TCPSocket: &core.TCPSocketAction{}}}, 0
+	}
+	return nil, 0
+}
+
+// getProbeType returns the type of the probe
+func getProbeType(probe *core.Probe) string {
+	switch {
+	case probe == nil:
+		return noneProbeType
+	case probe.HTTPGet != nil:
+		return httpProbeType
+	case probe.TCPSocket != nil:
+		return tcpProbeType
+	case probe.Exec != nil:
+		return execProbeType
+	}
+	return noneProbeType
+}
+
+// getProbePort returns the port used by the probe, or the first port of the container or the service if the probe does not specify one
+func getProbePort(service irtypes.Service, container core.Container, probe *core.Probe) int32 {
+	if probe != nil && probe.HTTPGet != nil && probe.HTTPGet.Port.IntValue() != 0 {
+		return int32(probe.HTTPGet.Port.IntValue())
+	}
+	if probe != nil && probe.TCPSocket != nil && probe.TCPSocket.Port.IntValue() != 0 {
+		return int32(probe.TCPSocket.Port.IntValue())
+	}
+	if len(container.Ports) != 0 {
+		return container.Ports[0].ContainerPort
+	}
+	if len(service.ServiceToPodPortForwardings) != 0 {
+		return service.ServiceToPodPortForwardings[0].PodPort.Number
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestProbePreprocessor(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	probesKey := func(service, key string) string {
+		return common.ConfigServicesKey + common.Delim + `"` + service + `"` + common.Delim + common.ConfigProbesForServiceKeySegment + common.Delim + key
+	}
+	addTestAnswerEngine(t, map[string]interface{}{
+		probesKey("readiness", "readinesspath"): "/actuator/health/readiness",
+		probesKey("disabled", "type"):           noneProbeType,
+	})
+	getContainer := func(port int32) core.Container {
+		container := core.Container{Name: "app"}
+		if port != 0 {
+			container.Ports = []core.ContainerPort{{ContainerPort: port}}
+		}
+		return container
+	}
+	tcpProbe := func(port int) *core.Probe {
+		return &core.Probe{Handler: core.Handler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(port)}}}
+	}
+	httpProbe := func(path string, port int) *core.Probe {
+		return &core.Probe{Handler: core.Handler{HTTPGet: &core.HTTPGetAction{Path: path, Port: intstr.FromInt(port), Scheme: core.URISchemeHTTP}}}
+	}
+	execProbe := &core.Probe{Handler: core.Handler{Exec: &core.ExecAction{Command: []string{"healthcheck"}}}, InitialDelaySeconds: 30, PeriodSeconds: 5}
+	actuatorHealthCheck := &irtypes.HealthCheck{Type: irtypes.HTTPHealthCheckType, Path: "/actuator/health", StartupTimeout: 120}
+	actuatorStartupProbe := httpProbe("/actuator/health", 8080)
+	actuatorStartupProbe.PeriodSeconds = defaultProbePeriodSeconds
+	actuatorStartupProbe.FailureThreshold = 12
+
+	testcases := []struct {
+		name          string
+		container     core.Container
+		healthCheck   *irtypes.HealthCheck
+		restartPolicy core.RestartPolicy
+		want          func(core.Container) core.Container
+	}{
+		{
+			name:      "tcp probe on the first port",
+			container: getContainer(8080),
+			want: func(c core.Container) core.Container {
+				c.LivenessProbe, c.ReadinessProbe = tcpProbe(8080), tcpProbe(8080)
+				return c
+			},
+		},
+		{
+			name:      "no port",
+			container: getContainer(0),
+			want:      func(c core.Container) core.Container { return c },
+		},
+		{
+			name:        "process health check",
+			container:   getContainer(8080),
+			healthCheck: &irtypes.HealthCheck{Type: irtypes.ProcessHealthCheckType},
+			want:        func(c core.Container) core.Container { return c },
+		},
+		{
+			name:          "job",
+			container:     getContainer(8080),
+			restartPolicy: core.RestartPolicyOnFailure,
+			want:          func(c core.Container) core.Container { return c },
+		},
+		{
+			name:        "http health check with a startup timeout",
+			container:   getContainer(8080),
+			healthCheck: actuatorHealthCheck,
+			want: func(c core.Container) core.Container {
+				c.LivenessProbe, c.ReadinessProbe, c.StartupProbe = httpProbe("/actuator/health", 8080), httpProbe("/actuator/health", 8080), actuatorStartupProbe
+				return c
+			},
+		},
+		{
+			name:        "readiness",
+			container:   getContainer(8080),
+			healthCheck: actuatorHealthCheck,
+			want: func(c core.Container) core.Container {
+				c.LivenessProbe, c.ReadinessProbe, c.StartupProbe = httpProbe("/actuator/health", 8080), httpProbe("/actuator/health/readiness", 8080), actuatorStartupProbe
+				return c
+			},
+		},
+		{
+			name: "liveness probe from the source",
+			container: func() core.Container {
+				c := getContainer(0)
+				c.LivenessProbe = execProbe
+				return c
+			}(),
+			want: func(c core.Container) core.Container {
+				c.ReadinessProbe = execProbe
+				return c
+			},
+		},
+		{
+			name: "liveness and readiness probes from the source",
+			container: func() core.Container {
+				c := getContainer(8080)
+				c.LivenessProbe, c.ReadinessProbe = httpProbe("/healthz", 8080), httpProbe("/ready", 8080)
+				return c
+			}(),
+			want: func(c core.Container) core.Container { return c },
+		},
+		{
+			name:        "disabled",
+			container:   getContainer(8080),
+			healthCheck: actuatorHealthCheck,
+			want:        func(c core.Container) core.Container { return c },
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ir := irtypes.NewIR()
+			service := irtypes.Service{Name: tc.name, HealthCheck: tc.healthCheck}
+			service.Containers = []core.Container{tc.container}
+			service.RestartPolicy = tc.restartPolicy
+			ir.Services[service.Name] = service
+			want := tc.want(tc.container)
+
+			actual, err := probePreprocessor{}.preprocess(ir)
+			if err != nil {
+				t.Fatalf("Failed to preprocess the IR. Error: %q", err)
+			}
+			if diff := cmp.Diff(want, actual.Services[service.Name].Containers[0]); diff != "" {
+				t.Fatalf("The probes are incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
@@ -25,7 +25,7 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// replicaOptimizer sets the minimum number of replicas
+// replicaOptimizer sets the minimum number of replicas. Stateful services are not scaled since their replicas do not share the data.
 type replicaPreprocessor struct {
 }
 
@@ -41,7 +41,7 @@ func (ep replicaPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 		replicaCount = minReplicas
 	}
 	for k, scObj := range ir.Services {
-		if scObj.Replicas < replicaCount {
+		if !scObj.Stateful && scObj.Replicas < replicaCount {
 			scObj.Replicas = replicaCount
 		}
 		ir.Services[k] = scObj
@@ -0,0 +1,312 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// SmallResourceProfile is the resource profile for services with light load
+	SmallResourceProfile = "small"
+	// MediumResourceProfile is the resource profile for services with moderate load
+	MediumResourceProfile = "medium"
+	// LargeResourceProfile is the resource profile for services with heavy load
+	LargeResourceProfile = "large"
+	// CustomResourceProfile is the resource profile whose resources are configured by the user
+	CustomResourceProfile = "custom"
+
+	defaultRuntime = "default"
+	javaRuntime    = "java"
+	nodejsRuntime  = "nodejs"
+	pythonRuntime  = "python"
+	rubyRuntime    = "ruby"
+	phpRuntime     = "php"
+	dotnetRuntime  = "dotnet"
+	golangRuntime  = "golang"
+
+	cpuRequestKeySegment    = "cpurequest"
+	cpuLimitKeySegment      = "cpulimit"
+	memoryRequestKeySegment = "memoryrequest"
+	memoryLimitKeySegment   = "memorylimit"
+
+	// heapOverheadPercent is the memory used by the JVM as a percentage of the max heap size
+	heapOverheadPercent = 150
+)
+
+// ResourceProfiles are the resource profiles that can be used for the default resources of the containers
+var ResourceProfiles = []string{SmallResourceProfile, MediumResourceProfile, LargeResourceProfile, CustomResourceProfile}
+
+// resourceSpec stores the resource requests and limits of a container
+type resourceSpec struct {
+	cpuRequest, cpuLimit, memoryRequest, memoryLimit string
+}
+
+// smallProfileResources are the resources of the small profile for each runtime. The medium and large profiles scale them.
+var smallProfileResources = map[string]resourceSpec{
+	defaultRuntime: {"100m", "500m", "128Mi", "256Mi"},
+	javaRuntime:    {"250m", "1000m", "512Mi", "768Mi"},
+	nodejsRuntime:  {"100m", "500m", "128Mi", "256Mi"},
+	pythonRuntime:  {"100m", "500m", "128Mi", "256Mi"},
+	rubyRuntime:    {"100m", "500m", "256Mi", "512Mi"},
+	phpRuntime:     {"100m", "500m", "128Mi", "256Mi"},
+	dotnetRuntime:  {"100m", "500m", "256Mi", "512Mi"},
+	golangRuntime:  {"50m", "250m", "64Mi", "128Mi"},
+}
+
+// resourceProfileMultipliers scale the resources of the small profile
+var resourceProfileMultipliers = map[string]int64{
+	SmallResourceProfile:  1,
+	MediumResourceProfile: 2,
+	LargeResourceProfile:  4,
+	CustomResourceProfile: 2,
+}
+
+// runtimeImageKeywords are used to infer the runtime from the name of the image
+var runtimeImageKeywords = []struct {
+	runtime  string
+	keywords []string
+}{
+	{javaRuntime, []string{"openjdk", "adoptopenjdk", "jdk", "jre", "java", "temurin", "tomcat", "jboss", "wildfly", "liberty", "websphere", "maven", "gradle"}},
+	{nodejsRuntime, []string{"node", "nodejs"}},
+	{pythonRuntime, []string{"python"}},
+	{rubyRuntime, []string{"ruby"}},
+	{phpRuntime, []string{"php"}},
+	{dotnetRuntime, []string{"dotnet", "aspnet"}},
+	{golangRuntime, []string{"golang"}},
+}
+
+// resourcePreprocessor infers the resource requests and limits of the containers
+type resourcePreprocessor struct {
+}
+
+func (rp resourcePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	for k, scObj := range ir.Services {
+		if scObj.OnlyIngress {
+			continue
+		}
+		for i, container := range scObj.Containers {
+			resources := rp.getInferredResources(ir, container)
+			if i == 0 {
+				resources = rp.getResourcesFromUser(scObj.Name, resources)
+			}
+			if len(resources.Requests) == 0 {
+				resources.Requests = nil
+			}
+			if len(resources.Limits) == 0 {
+				resources.Limits = nil
+			}
+			scObj.Containers[i].Resources = resources
+		}
+		ir.Services[k] = scObj
+	}
+	return ir, nil
+}
+
+// getInferredResources fills the missing resources of the container using the JVM max heap size and the selected resource profile
+func (rp resourcePreprocessor) getInferredResources(ir irtypes.IR, container core.Container) core.ResourceRequirements {
+	resources := core.ResourceRequirements{Requests: core.ResourceList{}, Limits: core.ResourceList{}}
+	for name, quantity := range container.Resources.Requests {
+		resources.Requests[name] = quantity
+	}
+	for name, quantity := range container.Resources.Limits {
+		resources.Limits[name] = quantity
+	}
+	containerImage := ir.ContainerImages[container.Image]
+	maxHeapSize := getContainerMaxHeapSize(container)
+	if maxHeapSize == 0 {
+		maxHeapSize = containerImage.MaxHeapSize
+	}
+	if _, ok := resources.Limits[core.ResourceMemory]; !ok && maxHeapSize > 0 {
+		memory := maxHeapSize * heapOverheadPercent / 100
+		memory = (memory + 1024*1024 - 1) / (1024 * 1024) * 1024 * 1024
+		resources.Limits[core.ResourceMemory] = *resource.NewQuantity(memory, resource.BinarySI)
+		if _, ok := resources.Requests[core.ResourceMemory]; !ok {
+			resources.Requests[core.ResourceMemory] = *resource.NewQuantity(memory, resource.BinarySI)
+		}
+	}
+	if common.ResourceProfile != "" {
+		runtime := getRuntime(containerImage.BaseImage)
+		if runtime == defaultRuntime {
+			runtime = getRuntime(container.Image)
+		}
+		if runtime == defaultRuntime && maxHeapSize > 0 {
+			runtime = javaRuntime
+		}
+		profileResources := getProfileResources(common.ResourceProfile, runtime)
+		setDefaultQuantity(resources.Requests, core.ResourceCPU, profileResources.cpuRequest)
+		setDefaultQuantity(resources.Limits, core.ResourceCPU, profileResources.cpuLimit)
+		setDefaultQuantity(resources.Requests, core.ResourceMemory, profileResources.memoryRequest)
+		setDefaultQuantity(resources.Limits, core.ResourceMemory, profileResources.memoryLimit)
+	}
+	capRequest(resources, core.ResourceCPU)
+	capRequest(resources, core.ResourceMemory)
+	return resources
+}
+
+// getResourcesFromUser asks the user for the resources of the main container of the service
+func (rp resourcePreprocessor) getResourcesFromUser(serviceName string, resources core.ResourceRequirements) core.ResourceRequirements {
+	if common.ResourceProfile == "" && len(resources.Requests) == 0 && len(resources.Limits) == 0 {
+		return resources
+	}
+	keyPrefix := common.ConfigServicesKey + common.Delim + `"` + serviceName + `"` + common.Delim + common.ConfigResourcesForServiceKeySegment + common.Delim
+	context := []string{"Leave it empty to not set it"}
+	askQuantity(resources.Requests, core.ResourceCPU, keyPrefix+cpuRequestKeySegment, fmt.Sprintf("Enter the CPU request of the service %s :", serviceName), context)
+	askQuantity(resources.Limits, core.ResourceCPU, keyPrefix+cpuLimitKeySegment, fmt.Sprintf("Enter the CPU limit of the service %s :", serviceName), context)
+	askQuantity(resources.Requests, core.ResourceMemory, keyPrefix+memoryRequestKeySegment, fmt.Sprintf("Enter the memory request of the service %s :", serviceName), context)
+	askQuantity(resources.Limits, core.ResourceMemory, keyPrefix+memoryLimitKeySegment, fmt.Sprintf("Enter the memory limit of the service %s :", serviceName), context)
+	capRequest(resources, core.ResourceCPU)
+	capRequest(resources, core.ResourceMemory)
+	return resources
+}
+
+// getProfileResources returns the resources of the runtime in the resource profile
+func getProfileResources(profile, runtime string) resourceSpec {
+	smallResources, ok := smallProfileResources[runtime]
+	if !ok {
+		smallResources = smallProfileResources[defaultRuntime]
+	}
+	multiplier, ok := resourceProfileMultipliers[profile]
+	if !ok {
+		logrus.Errorf("Unknown resource profile %s. Using the %s profile", profile, MediumResourceProfile)
+		multiplier = resourceProfileMultipliers[MediumResourceProfile]
+	}
+	profileResources := resourceSpec{
+		cpuRequest:    scaleQuantity(smallResources.cpuRequest, multiplier, true),
+		cpuLimit:      scaleQuantity(smallResources.cpuLimit, multiplier, true),
+		memoryRequest: scaleQuantity(smallResources.memoryRequest, multiplier, false),
+		memoryLimit:   scaleQuantity(smallResources.memoryLimit, multiplier, false),
+	}
+	if profile != CustomResourceProfile {
+		return profileResources
+	}
+	keyPrefix := common.ConfigResourceProfileKey + common.Delim + `"` + runtime + `"` + common.Delim
+	context := []string{fmt.Sprintf("Used for the containers of the %s runtime which do not specify it", runtime)}
+	profileResources.cpuRequest = qaengine.FetchStringAnswer(keyPrefix+cpuRequestKeySegment, fmt.Sprintf("Enter the default CPU request for %s :", runtime), context, profileResources.cpuRequest)
+	profileResources.cpuLimit = qaengine.FetchStringAnswer(keyPrefix+cpuLimitKeySegment, fmt.Sprintf("Enter the default CPU limit for %s :", runtime), context, profileResources.cpuLimit)
+	profileResources.memoryRequest = qaengine.FetchStringAnswer(keyPrefix+memoryRequestKeySegment, fmt.Sprintf("Enter the default memory request for %s :", runtime), context, profileResources.memoryRequest)
+	profileResources.memoryLimit = qaengine.FetchStringAnswer(keyPrefix+memoryLimitKeySegment, fmt.Sprintf("Enter the default memory limit for %s :", runtime), context, profileResources.memoryLimit)
+	return profileResources
+}
+
+// getRuntime infers the language runtime from the last path segment of the repository of the image.
+// The segment is split at hyphens, underscores and dots, so that images like ubi8/openjdk-11 and python3.9 are matched.
+func getRuntime(image string) string {
+	image = strings.ToLower(image)
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image = image[:idx]
+	}
+	name := path.Base(image)
+	if idx := strings.Index(name, ":"); idx != -1 {
+		name = name[:idx]
+	}
+	if name == "" || name == "." || name == "/" {
+		return defaultRuntime
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	for _, runtimeKeywords := range runtimeImageKeywords {
+		for _, word := range words {
+			if common.IsStringPresent(runtimeKeywords.keywords, word) || common.IsStringPresent(runtimeKeywords.keywords, strings.TrimRight(word, "0123456789")) {
+				return runtimeKeywords.runtime
+			}
+		}
+	}
+	return defaultRuntime
+}
+
+// getContainerMaxHeapSize returns the max JVM heap size set in the command, arguments or environment variables of the container
+func getContainerMaxHeapSize(container core.Container) int64 {
+	maxHeapSize := common.GetJavaMaxHeapSize(strings.Join(append(append([]string{}, container.Command...), container.Args...), " "))
+	for _, env := range container.Env {
+		if size := common.GetJavaMaxHeapSize(env.Value); size != 0 {
+			maxHeapSize = size
+		}
+	}
+	return maxHeapSize
+}
+
+// scaleQuantity multiplies the quantity and formats the CPU quantities in millicores
+func scaleQuantity(value string, multiplier int64, cpu bool) string {
+	quantity := resource.MustParse(value)
+	if cpu {
+		return fmt.Sprintf("%dm", quantity.MilliValue()*multiplier)
+	}
+	return resource.NewQuantity(quantity.Value()*multiplier, resource.BinarySI).String()
+}
+
+// formatQuantity formats the quantity for the questions, using millicores for the CPU so that the answers are not read as numbers
+func formatQuantity(name core.ResourceName, quantity resource.Quantity) string {
+	if name == core.ResourceCPU {
+		return fmt.Sprintf("%dm", quantity.MilliValue())
+	}
+	return quantity.String()
+}
+
+// setDefaultQuantity sets the resource if it is not already set
+func setDefaultQuantity(resources core.ResourceList, name core.ResourceName, value string) {
+	if _, ok := resources[name]; ok || value == "" {
+		return
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		logrus.Errorf("Unable to parse the %s quantity %s : %s", name, value, err)
+		return
+	}
+	resources[name] = quantity
+}
+
+// askQuantity asks the user for the resource, using the current value as the default
+func askQuantity(resources core.ResourceList, name core.ResourceName, key, desc string, context []string) {
+	def := ""
+	if quantity, ok := resources[name]; ok {
+		def = formatQuantity(name, quantity)
+	}
+	answer := strings.TrimSpace(qaengine.FetchStringAnswer(key, desc, context, def))
+	if answer == "" {
+		delete(resources, name)
+		return
+	}
+	quantity, err := resource.ParseQuantity(answer)
+	if err != nil {
+		logrus.Errorf("Unable to parse the %s quantity %s. Using %s : %s", name, answer, def, err)
+		return
+	}
+	resources[name] = quantity
+}
+
+// capRequest lowers the request of the resource to its limit since the request can not be more than the limit
+func capRequest(resources core.ResourceRequirements, name core.ResourceName) {
+	request, ok := resources.Requests[name]
+	if !ok {
+		return
+	}
+	limit, ok := resources.Limits[name]
+	if !ok || request.Cmp(limit) <= 0 {
+		return
+	}
+	logrus.Debugf("The %s request %s is more than the limit %s. Using the limit as the request", name, request.String(), limit.String())
+	resources.Requests[name] = limit
+}
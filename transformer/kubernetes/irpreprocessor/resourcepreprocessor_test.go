@@ -0,0 +1,129 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestResourcePreprocessor(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	defer func() { common.ResourceProfile = "" }()
+	getResources := func(cpuRequest, cpuLimit, memoryRequest, memoryLimit string) core.ResourceRequirements {
+		resources := core.ResourceRequirements{}
+		for _, r := range []struct {
+			list     *core.ResourceList
+			name     core.ResourceName
+			quantity string
+		}{
+			{&resources.Requests, core.ResourceCPU, cpuRequest},
+			{&resources.Limits, core.ResourceCPU, cpuLimit},
+			{&resources.Requests, core.ResourceMemory, memoryRequest},
+			{&resources.Limits, core.ResourceMemory, memoryLimit},
+		} {
+			if r.quantity == "" {
+				continue
+			}
+			if *r.list == nil {
+				*r.list = core.ResourceList{}
+			}
+			(*r.list)[r.name] = resource.MustParse(r.quantity)
+		}
+		return resources
+	}
+	nodeContainer := core.Container{Name: "node", Image: "node:14"}
+	javaOptsContainer := core.Container{Name: "javaopts", Image: "myjavaopts", Env: []core.EnvVar{{Name: "JAVA_OPTS", Value: "-Xms256m -Xmx1g"}}}
+	dockerfileContainer := core.Container{Name: "dockerfile", Image: "mydockerfile"}
+	dockerfileImages := map[string]irtypes.ContainerImage{"mydockerfile": {BaseImage: "registry.access.redhat.com/ubi8/openjdk-11", MaxHeapSize: 512 * 1024 * 1024}}
+	cfContainer := core.Container{Name: "cf", Image: "mycf", Resources: getResources("", "", "1Gi", "1Gi")}
+	misleadingContainer := core.Container{Name: "misleading", Image: "quay.io/java-team/nginx:1.21"}
+
+	testcases := []struct {
+		name      string
+		profile   string
+		container core.Container
+		images    map[string]irtypes.ContainerImage
+		want      core.ResourceRequirements
+	}{
+		{name: "node without a profile", container: nodeContainer, want: core.ResourceRequirements{}},
+		{name: "JAVA_OPTS without a profile", container: javaOptsContainer, want: getResources("", "", "1536Mi", "1536Mi")},
+		{name: "dockerfile heap size without a profile", container: dockerfileContainer, images: dockerfileImages, want: getResources("", "", "768Mi", "768Mi")},
+		{name: "cloud foundry memory without a profile", container: cfContainer, want: getResources("", "", "1Gi", "1Gi")},
+		{name: "node with the small profile", profile: SmallResourceProfile, container: nodeContainer, want: getResources("100m", "500m", "128Mi", "256Mi")},
+		{name: "JAVA_OPTS with the small profile", profile: SmallResourceProfile, container: javaOptsContainer, want: getResources("250m", "1", "1536Mi", "1536Mi")},
+		{name: "dockerfile heap size with the small profile", profile: SmallResourceProfile, container: dockerfileContainer, images: dockerfileImages, want: getResources("250m", "1", "768Mi", "768Mi")},
+		{name: "cloud foundry memory with the small profile", profile: SmallResourceProfile, container: cfContainer, want: getResources("100m", "500m", "1Gi", "1Gi")},
+		{name: "image in a namespace named like a runtime", profile: SmallResourceProfile, container: misleadingContainer, want: getResources("100m", "500m", "128Mi", "256Mi")},
+		{name: "node with the large profile", profile: LargeResourceProfile, container: nodeContainer, want: getResources("400m", "2", "512Mi", "1Gi")},
+		{name: "JAVA_OPTS with the large profile", profile: LargeResourceProfile, container: javaOptsContainer, want: getResources("1", "4", "1536Mi", "1536Mi")},
+		{name: "dockerfile heap size with the large profile", profile: LargeResourceProfile, container: dockerfileContainer, images: dockerfileImages, want: getResources("1", "4", "768Mi", "768Mi")},
+		{name: "cloud foundry memory with the large profile", profile: LargeResourceProfile, container: cfContainer, want: getResources("400m", "2", "1Gi", "1Gi")},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			common.ResourceProfile = tc.profile
+			ir := irtypes.NewIR()
+			for name, image := range tc.images {
+				ir.ContainerImages[name] = image
+			}
+			service := irtypes.Service{Name: tc.container.Name}
+			service.Containers = []core.Container{tc.container}
+			ir.Services[service.Name] = service
+
+			actual, err := resourcePreprocessor{}.preprocess(ir)
+			if err != nil {
+				t.Fatalf("Failed to preprocess the IR. Error: %q", err)
+			}
+			if diff := cmp.Diff(tc.want, actual.Services[service.Name].Containers[0].Resources, cmp.Comparer(func(x, y resource.Quantity) bool { return x.Cmp(y) == 0 })); diff != "" {
+				t.Fatalf("The resources are incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRuntime(t *testing.T) {
+	testcases := map[string]string{
+		"":        defaultRuntime,
+		"node:14": nodejsRuntime,
+		"registry.access.redhat.com/ubi8/nodejs-14":  nodejsRuntime,
+		"registry.access.redhat.com/ubi8/openjdk-11": javaRuntime,
+		"eclipse-temurin:17-jre":                     javaRuntime,
+		"python3.9":                                  pythonRuntime,
+		"python:3.9-slim@sha256:abcd":                pythonRuntime,
+		"mcr.microsoft.com/dotnet/aspnet:6.0":        dotnetRuntime,
+		"localhost:5000/golang:1.17":                 golangRuntime,
+		"php:8-apache":                               phpRuntime,
+		"quay.io/java-team/nginx:1.21":               defaultRuntime,
+		"myregistry:5000/nodeapps/web":               defaultRuntime,
+		"nginx:node-14":                              defaultRuntime,
+		"mongodb":                                    defaultRuntime,
+	}
+	for image, want := range testcases {
+		t.Run(image, func(t *testing.T) {
+			if actual := getRuntime(image); actual != want {
+				t.Fatalf("Expected the runtime of the image %q to be %s. Actual: %s", image, want, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// tmpDir is always writable when the root filesystem is read only
+	tmpDir = "/tmp"
+)
+
+// securityContextPreprocessor hardens the security context of the services based on the pod security level
+type securityContextPreprocessor struct {
+}
+
+func (sp securityContextPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	hardened := common.PodSecurity == common.PodSecurityBaseline || common.PodSecurity == common.PodSecurityRestricted
+	restricted := common.PodSecurity == common.PodSecurityRestricted
+	for k, scObj := range ir.Services {
+		if len(scObj.Containers) == 0 {
+			continue
+		}
+		readOnlyRootFilesystem := false
+		writableDirs := []string{}
+		if hardened {
+			keyPrefix := common.ConfigServicesKey + common.Delim + `"` + scObj.Name + `"` + common.Delim + common.ConfigSecurityContextForServiceKeySegment + common.Delim
+			readOnlyRootFilesystem = qaengine.FetchBoolAnswer(keyPrefix+"readonlyrootfilesystem", fmt.Sprintf("Should the root filesystem of the containers of the service %s be read only?", scObj.Name), []string{"/tmp is mounted as an emptyDir volume"}, restricted)
+			if readOnlyRootFilesystem {
+				writableDirs = sp.getWritableDirs(keyPrefix, scObj, ir.ContainerImages)
+			}
+			if scObj.SecurityContext == nil {
+				scObj.SecurityContext = &core.PodSecurityContext{}
+			}
+			if scObj.SecurityContext.SeccompProfile == nil {
+				scObj.SecurityContext.SeccompProfile = &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault}
+			}
+		}
+		for i, container := range scObj.Containers {
+			containerImage, ok := ir.ContainerImages[container.Image]
+			if !ok {
+				containerImage = irtypes.NewContainer()
+			}
+			if container.SecurityContext == nil {
+				container.SecurityContext = &core.SecurityContext{}
+			}
+			if hardened {
+				sp.setUser(scObj.Name, container.SecurityContext, containerImage.UserID, restricted)
+			}
+			if restricted {
+				if container.SecurityContext.AllowPrivilegeEscalation == nil {
+					container.SecurityContext.AllowPrivilegeEscalation = new(bool)
+				}
+				if container.SecurityContext.Capabilities == nil {
+					container.SecurityContext.Capabilities = &core.Capabilities{}
+				}
+				if !isCapabilityPresent(container.SecurityContext.Capabilities.Drop, k8sschema.AllCapabilities) {
+					container.SecurityContext.Capabilities.Drop = []core.Capability{k8sschema.AllCapabilities}
+				}
+			}
+			if readOnlyRootFilesystem {
+				readOnly := true
+				container.SecurityContext.ReadOnlyRootFilesystem = &readOnly
+				for _, dir := range writableDirs {
+					container = sp.addWritableDir(&scObj, container, dir)
+				}
+			}
+			if *container.SecurityContext == (core.SecurityContext{}) {
+				container.SecurityContext = nil
+			}
+			scObj.Containers[i] = container
+		}
+		ir.Services[k] = scObj
+	}
+	return ir, nil
+}
+
+// getWritableDirs asks the user for the directories the containers of the service write to.
+// The directories accessed by the images of the containers, like their working directories, are suggested.
+func (sp securityContextPreprocessor) getWritableDirs(keyPrefix string, service irtypes.Service, containerImages map[string]irtypes.ContainerImage) []string {
+	serviceName := service.Name
+	accessedDirs := []string{}
+	for _, container := range service.Containers {
+		if containerImage, ok := containerImages[container.Image]; ok {
+			accessedDirs = common.MergeStringSlices(accessedDirs, containerImage.AccessedDirs...)
+		}
+	}
+	answer := qaengine.FetchMultilineInputAnswer(keyPrefix+"writabledirs", fmt.Sprintf("Provide the directories the containers of the service %s write to, other than /tmp :", serviceName), []string{"Each absolute path on a new line is mounted as an emptyDir volume"}, strings.Join(accessedDirs, "\n"))
+	writableDirs := []string{tmpDir}
+	for _, dir := range strings.Split(answer, "\n") {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+		if !path.IsAbs(dir) {
+			logrus.Warnf("Unable to make the directory %s of the service %s writable since it is not an absolute path", dir, serviceName)
+			continue
+		}
+		writableDirs = append(writableDirs, dir)
+	}
+	return writableDirs
+}
+
+// setUser makes the container run as the non root user of its image
+func (sp securityContextPreprocessor) setUser(serviceName string, securityContext *core.SecurityContext, userID int, restricted bool) {
+	if securityContext.RunAsUser != nil || securityContext.RunAsNonRoot != nil {
+		return
+	}
+	runAsNonRoot := true
+	switch {
+	case userID > 0:
+		uid := int64(userID)
+		securityContext.RunAsUser = &uid
+		securityContext.RunAsNonRoot = &runAsNonRoot
+	case userID == 0:
+		if restricted {
+			logrus.Warnf("The image of the service %s runs as root. The service does not comply with the %s pod security level", serviceName, common.PodSecurityRestricted)
+		}
+	case restricted:
+		securityContext.RunAsNonRoot = &runAsNonRoot
+	}
+}
+
+// addWritableDir mounts an emptyDir volume at the directory unless something is already mounted there
+func (sp securityContextPreprocessor) addWritableDir(service *irtypes.Service, container core.Container, dir string) core.Container {
+	dir = path.Clean(dir)
+	for _, volumeMount := range container.VolumeMounts {
+		if path.Clean(volumeMount.MountPath) == dir {
+			return container
+		}
+	}
+	volumeName := common.MakeStringDNSLabelNameCompliant(strings.ReplaceAll("writable"+dir, ".", "-"))
+	service.AddVolume(core.Volume{Name: volumeName, VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}})
+	container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{Name: volumeName, MountPath: dir})
+	return container
+}
+
+func isCapabilityPresent(capabilities []core.Capability, capability core.Capability) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestSecurityContextPreprocessor(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	securityContextKey := func(service, key string) string {
+		return common.ConfigServicesKey + common.Delim + `"` + service + `"` + common.Delim + common.ConfigSecurityContextForServiceKeySegment + common.Delim + key
+	}
+	addTestAnswerEngine(t, map[string]interface{}{
+		securityContextKey("readonly", "readonlyrootfilesystem"):        true,
+		securityContextKey("readonly", "writabledirs"):                  "/app/data/\nrelative/dir\n/tmp\n",
+		securityContextKey("readonlydefault", "readonlyrootfilesystem"): true,
+	})
+	defer func() { common.PodSecurity = "" }()
+	nonRootImage := irtypes.NewContainer()
+	nonRootImage.UserID = 1001
+	nonRootImage.AccessedDirs = []string{"/app"}
+	trueValue, falseValue := true, false
+	uid := int64(1001)
+	runtimeDefault := &core.PodSecurityContext{SeccompProfile: &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault}}
+	emptyDir := func(name string) core.Volume {
+		return core.Volume{Name: name, VolumeSource: core.VolumeSource{EmptyDir: &core.EmptyDirVolumeSource{}}}
+	}
+	restrictedSecurityContext := func() *core.SecurityContext {
+		return &core.SecurityContext{
+			RunAsNonRoot:             &trueValue,
+			AllowPrivilegeEscalation: &falseValue,
+			Capabilities:             &core.Capabilities{Drop: []core.Capability{k8sschema.AllCapabilities}},
+			ReadOnlyRootFilesystem:   &trueValue,
+		}
+	}
+	testcases := []struct {
+		name         string
+		level        string
+		image        *irtypes.ContainerImage
+		volumeMounts []core.VolumeMount
+		want         core.PodSpec
+	}{
+		{
+			name:  "plain",
+			image: &nonRootImage,
+			want:  core.PodSpec{Containers: []core.Container{{Name: "plain", Image: "plain"}}},
+		},
+		{
+			name:  "privileged",
+			level: common.PodSecurityPrivileged,
+			image: &nonRootImage,
+			want:  core.PodSpec{Containers: []core.Container{{Name: "privileged", Image: "privileged"}}},
+		},
+		{
+			name:  "baseline",
+			level: common.PodSecurityBaseline,
+			image: &nonRootImage,
+			want: core.PodSpec{
+				SecurityContext: runtimeDefault,
+				Containers:      []core.Container{{Name: "baseline", Image: "baseline", SecurityContext: &core.SecurityContext{RunAsUser: &uid, RunAsNonRoot: &trueValue}}},
+			},
+		},
+		{
+			name:  "readonly",
+			level: common.PodSecurityBaseline,
+			image: &nonRootImage,
+			want: core.PodSpec{
+				SecurityContext: runtimeDefault,
+				Volumes:         []core.Volume{emptyDir("writable-tmp"), emptyDir("writable-app-data")},
+				Containers: []core.Container{{
+					Name:            "readonly",
+					Image:           "readonly",
+					SecurityContext: &core.SecurityContext{RunAsUser: &uid, RunAsNonRoot: &trueValue, ReadOnlyRootFilesystem: &trueValue},
+					VolumeMounts:    []core.VolumeMount{{Name: "writable-tmp", MountPath: "/tmp"}, {Name: "writable-app-data", MountPath: "/app/data"}},
+				}},
+			},
+		},
+		{
+			name:  "readonlydefault",
+			level: common.PodSecurityBaseline,
+			image: &nonRootImage,
+			want: core.PodSpec{
+				SecurityContext: runtimeDefault,
+				Volumes:         []core.Volume{emptyDir("writable-tmp"), emptyDir("writable-app")},
+				Containers: []core.Container{{
+					Name:            "readonlydefault",
+					Image:           "readonlydefault",
+					SecurityContext: &core.SecurityContext{RunAsUser: &uid, RunAsNonRoot: &trueValue, ReadOnlyRootFilesystem: &trueValue},
+					VolumeMounts:    []core.VolumeMount{{Name: "writable-tmp", MountPath: "/tmp"}, {Name: "writable-app", MountPath: "/app"}},
+				}},
+			},
+		},
+		{
+			name:  "restricted",
+			level: common.PodSecurityRestricted,
+			want: core.PodSpec{
+				SecurityContext: runtimeDefault,
+				Volumes:         []core.Volume{emptyDir("writable-tmp")},
+				Containers: []core.Container{{
+					Name:            "restricted",
+					Image:           "restricted",
+					SecurityContext: restrictedSecurityContext(),
+					VolumeMounts:    []core.VolumeMount{{Name: "writable-tmp", MountPath: "/tmp"}},
+				}},
+			},
+		},
+		{
+			name:         "restrictedmounted",
+			level:        common.PodSecurityRestricted,
+			volumeMounts: []core.VolumeMount{{Name: "cache", MountPath: "/tmp/"}},
+			want: core.PodSpec{
+				SecurityContext: runtimeDefault,
+				Containers: []core.Container{{
+					Name:            "restrictedmounted",
+					Image:           "restrictedmounted",
+					SecurityContext: restrictedSecurityContext(),
+					VolumeMounts:    []core.VolumeMount{{Name: "cache", MountPath: "/tmp/"}},
+				}},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			common.PodSecurity = tc.level
+			ir := irtypes.NewIR()
+			service := irtypes.NewServiceWithName(tc.name)
+			service.Containers = []core.Container{{Name: tc.name, Image: tc.name, VolumeMounts: tc.volumeMounts}}
+			ir.Services[tc.name] = service
+			if tc.image != nil {
+				ir.ContainerImages[tc.name] = *tc.image
+			}
+			actual, err := securityContextPreprocessor{}.preprocess(ir)
+			if err != nil {
+				t.Fatalf("Failed to preprocess the IR. Error: %q", err)
+			}
+			actualService := actual.Services[tc.name]
+			if diff := cmp.Diff(tc.want, actualService.PodSpec); diff != "" {
+				t.Fatalf("The pod spec is incorrect. Differences:\n%s", diff)
+			}
+			if violations := getViolations(actualService, tc.level); len(violations) != 0 {
+				t.Fatalf("Expected the service to comply with the %s pod security level. Actual violations: %+v", tc.level, violations)
+			}
+		})
+	}
+}
+
+func getViolations(svc irtypes.Service, level string) []string {
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: corev1.SchemeGroupVersion.String()},
+		Spec:     k8sschema.ConvertToV1PodSpec(&svc.PodSpec),
+	}
+	return k8sschema.GetPodSecurityViolations(pod, level)
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"path"
+	"strings"
+
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// knownStatefulImages are the names of the images of databases, queues and caches which store their data in volumes
+var knownStatefulImages = []string{"postgres", "postgresql", "mysql", "mariadb", "mongo", "mongodb", "redis", "kafka", "zookeeper", "elasticsearch", "opensearch", "cassandra", "rabbitmq", "etcd", "couchdb", "couchbase", "neo4j", "influxdb", "minio", "nats"}
+
+// statefulPreprocessor marks the services which use known stateful images with persistent volumes as stateful
+type statefulPreprocessor struct {
+}
+
+func (sp statefulPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	for k, scObj := range ir.Services {
+		if scObj.Stateful || scObj.Daemon || scObj.Schedule != nil || scObj.RestartPolicy == core.RestartPolicyNever || scObj.RestartPolicy == core.RestartPolicyOnFailure {
+			continue
+		}
+		if !hasPVCVolume(scObj, ir.Storages) {
+			continue
+		}
+		for _, container := range scObj.Containers {
+			if isKnownStatefulImage(container.Image) {
+				logrus.Debugf("Service %s uses the stateful image %s with persistent volumes. Marking it as stateful", scObj.Name, container.Image)
+				scObj.Stateful = true
+				break
+			}
+		}
+		ir.Services[k] = scObj
+	}
+	return ir, nil
+}
+
+// hasPVCVolume returns true if the service has a volume backed by a PVC storage
+func hasPVCVolume(service irtypes.Service, storages []irtypes.Storage) bool {
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		for _, st := range storages {
+			if st.StorageType == irtypes.PVCKind && st.Name == volume.PersistentVolumeClaim.ClaimName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isKnownStatefulImage returns true if the name of the image, without the registry, namespace and tag, matches a known stateful image.
+// Variants like bitnami/postgresql and confluentinc/cp-kafka are also matched.
+func isKnownStatefulImage(image string) bool {
+	if idx := strings.Index(image, "@"); idx != -1 {
+		image = image[:idx]
+	}
+	name := path.Base(image)
+	if idx := strings.Index(name, ":"); idx != -1 {
+		name = name[:idx]
+	}
+	for _, statefulImage := range knownStatefulImages {
+		if name == statefulImage || strings.HasPrefix(name, statefulImage+"-") || strings.HasSuffix(name, "-"+statefulImage) {
+			return true
+		}
+	}
+	return false
+}
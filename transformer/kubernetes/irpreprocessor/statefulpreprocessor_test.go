@@ -0,0 +1,91 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestStatefulPreprocessor(t *testing.T) {
+	getService := func(image, claimName string) irtypes.Service {
+		svc := irtypes.Service{Name: "svc"}
+		svc.Containers = []core.Container{{Name: "svc", Image: image}}
+		if claimName != "" {
+			svc.Volumes = []core.Volume{{
+				Name:         "data",
+				VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: claimName}},
+			}}
+		}
+		return svc
+	}
+	storages := []irtypes.Storage{{Name: "data", StorageType: irtypes.PVCKind}, {Name: "config", StorageType: irtypes.ConfigMapKind}}
+	testcases := []struct {
+		name     string
+		service  func() irtypes.Service
+		stateful bool
+	}{
+		{name: "known image with a PVC", service: func() irtypes.Service { return getService("docker.io/library/postgres:13", "data") }, stateful: true},
+		{name: "known image variant with a digest", service: func() irtypes.Service { return getService("confluentinc/cp-kafka@sha256:abcd", "data") }, stateful: true},
+		{name: "known image with a registry port", service: func() irtypes.Service { return getService("localhost:5000/bitnami/postgresql:13", "data") }, stateful: true},
+		{name: "known image without volumes", service: func() irtypes.Service { return getService("redis:6", "") }, stateful: false},
+		{name: "known image with a claim that is not a PVC storage", service: func() irtypes.Service { return getService("mysql", "config") }, stateful: false},
+		{name: "known image with an unknown claim", service: func() irtypes.Service { return getService("mysql", "missing") }, stateful: false},
+		{name: "unknown image with a PVC", service: func() irtypes.Service { return getService("nginx:latest", "data") }, stateful: false},
+		{
+			name: "job with a known image",
+			service: func() irtypes.Service {
+				svc := getService("postgres", "data")
+				svc.RestartPolicy = core.RestartPolicyOnFailure
+				return svc
+			},
+			stateful: false,
+		},
+		{
+			name: "daemon with a known image",
+			service: func() irtypes.Service {
+				svc := getService("postgres", "data")
+				svc.Daemon = true
+				return svc
+			},
+			stateful: false,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ir := irtypes.NewIR()
+			ir.Storages = storages
+			ir.Services["svc"] = tc.service()
+			want := irtypes.NewIR()
+			want.Storages = storages
+			wantService := tc.service()
+			wantService.Stateful = tc.stateful
+			want.Services["svc"] = wantService
+
+			actual, err := statefulPreprocessor{}.preprocess(ir)
+			if err != nil {
+				t.Fatalf("Failed to preprocess the IR. Error: %q", err)
+			}
+			if diff := cmp.Diff(want, actual); diff != "" {
+				t.Fatalf("Failed to get the intermediate representation properly. Differences:\n%s", diff)
+			}
+		})
+	}
+}
@@ -19,9 +19,11 @@ package k8sschema
 import (
 	"fmt"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
 	core "k8s.io/kubernetes/pkg/apis/core"
 	knativev1 "knative.dev/serving/pkg/apis/serving/v1"
 
@@ -59,6 +61,7 @@ func convertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.Clus
 	if len(versions) == 0 {
 		return nil, fmt.Errorf("kind %s unsupported in target cluster : %+v", kind, obj.GetObjectKind())
 	}
+	versions = getLosslessVersionsFirst(obj, versions)
 	logrus.Debugf("Supported Versions : %+v", versions)
 	if kind == common.ServiceKind && objgv.Group == knativev1.SchemeGroupVersion.Group {
 		return obj, nil
@@ -84,6 +87,39 @@ func convertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.Clus
 	return obj, fmt.Errorf("unable to convert to a supported version : %+v", obj.GetObjectKind())
 }
 
+// getLosslessVersionsFirst moves the versions which cannot represent the object without losing fields to the end.
+// autoscaling/v1 HorizontalPodAutoscalers only support a cpu utilization target.
+func getLosslessVersionsFirst(obj runtime.Object, versions []string) []string {
+	hpa, ok := obj.(*autoscaling.HorizontalPodAutoscaler)
+	if !ok || !needsAutoscalingV2(hpa) {
+		return versions
+	}
+	autoscalingV1 := autoscalingv1.SchemeGroupVersion.String()
+	ordered := []string{}
+	for _, v := range versions {
+		if v != autoscalingV1 {
+			ordered = append(ordered, v)
+		}
+	}
+	if len(ordered) == len(versions) {
+		return versions
+	}
+	logrus.Debugf("The HorizontalPodAutoscaler %s has metrics which are not supported by %s. Preferring the other versions", hpa.Name, autoscalingV1)
+	return append(ordered, autoscalingV1)
+}
+
+func needsAutoscalingV2(hpa *autoscaling.HorizontalPodAutoscaler) bool {
+	if len(hpa.Spec.Metrics) > 1 || hpa.Spec.Behavior != nil {
+		return true
+	}
+	for _, metric := range hpa.Spec.Metrics {
+		if metric.Type != autoscaling.ResourceMetricSourceType || metric.Resource == nil || metric.Resource.Name != core.ResourceCPU || metric.Resource.Target.Type != autoscaling.UtilizationMetricType {
+			return true
+		}
+	}
+	return false
+}
+
 // ConvertToPreferredVersion converts obj to a preferred Version
 func ConvertToPreferredVersion(obj runtime.Object, clusterSpec collecttypes.ClusterMetadataSpec) (newobj runtime.Object, err error) {
 	objgvk := obj.GetObjectKind().GroupVersionKind()
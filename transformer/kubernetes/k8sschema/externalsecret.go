@@ -0,0 +1,133 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ExternalSecretKind is the kind of the external secrets operator resource which syncs a secret from an external secret store
+	ExternalSecretKind = "ExternalSecret"
+)
+
+var (
+	// ExternalSecretsSchemeGroupVersion is the group version of the external secrets operator resources
+	ExternalSecretsSchemeGroupVersion = schema.GroupVersion{Group: "external-secrets.io", Version: "v1beta1"}
+)
+
+// ExternalSecret creates a secret using the data in an external secret store.
+// Only the fields used by move2kube are defined.
+type ExternalSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ExternalSecretSpec `json:"spec,omitempty"`
+}
+
+// ExternalSecretList contains a list of ExternalSecret
+type ExternalSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExternalSecret `json:"items"`
+}
+
+// ExternalSecretSpec defines the desired state of ExternalSecret
+type ExternalSecretSpec struct {
+	SecretStoreRef SecretStoreRef       `json:"secretStoreRef"`
+	Target         ExternalSecretTarget `json:"target,omitempty"`
+	Data           []ExternalSecretData `json:"data,omitempty"`
+}
+
+// SecretStoreRef refers to the secret store which has the data
+type SecretStoreRef struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// ExternalSecretTarget defines the secret which is created
+type ExternalSecretTarget struct {
+	Name     string                  `json:"name,omitempty"`
+	Template *ExternalSecretTemplate `json:"template,omitempty"`
+}
+
+// ExternalSecretTemplate defines the type of the secret which is created
+type ExternalSecretTemplate struct {
+	Type corev1.SecretType `json:"type,omitempty"`
+}
+
+// ExternalSecretData defines the key of the secret and the data in the secret store it is filled from
+type ExternalSecretData struct {
+	SecretKey string                      `json:"secretKey"`
+	RemoteRef ExternalSecretDataRemoteRef `json:"remoteRef"`
+}
+
+// ExternalSecretDataRemoteRef refers to the data in the secret store
+type ExternalSecretDataRemoteRef struct {
+	Key      string `json:"key"`
+	Property string `json:"property,omitempty"`
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *ExternalSecret) DeepCopyInto(out *ExternalSecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Target.Template != nil {
+		template := *in.Spec.Target.Template
+		out.Spec.Target.Template = &template
+	}
+	if in.Spec.Data != nil {
+		out.Spec.Data = make([]ExternalSecretData, len(in.Spec.Data))
+		copy(out.Spec.Data, in.Spec.Data)
+	}
+}
+
+// DeepCopy copies the receiver into a new ExternalSecret
+func (in *ExternalSecret) DeepCopy() *ExternalSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *ExternalSecret) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *ExternalSecretList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretList)
+	*out = *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]ExternalSecret, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+	return out
+}
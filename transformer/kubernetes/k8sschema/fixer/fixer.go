@@ -17,10 +17,14 @@
 package fixer
 
 import (
+	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
 // fixer can be used to fix K8s resources
@@ -30,13 +34,31 @@ type fixer interface {
 }
 
 var (
-	fixers = []fixer{deploymentFixer{}, ingressFixer{}}
+	fixers = []fixer{deploymentFixer{}, ingressFixer{}, httpRouteFixer{}}
+	// podSecurityFixers are used only when the pods should comply with the restricted pod security level
+	podSecurityFixers = []fixer{
+		podSecurityFixer{gvk: core.SchemeGroupVersion.WithKind("Pod")},
+		podSecurityFixer{gvk: core.SchemeGroupVersion.WithKind("ReplicationController")},
+		podSecurityFixer{gvk: apps.SchemeGroupVersion.WithKind(common.DeploymentKind)},
+		podSecurityFixer{gvk: apps.SchemeGroupVersion.WithKind("StatefulSet")},
+		podSecurityFixer{gvk: apps.SchemeGroupVersion.WithKind("DaemonSet")},
+		podSecurityFixer{gvk: apps.SchemeGroupVersion.WithKind("ReplicaSet")},
+		podSecurityFixer{gvk: batch.SchemeGroupVersion.WithKind("Job")},
+		podSecurityFixer{gvk: batch.SchemeGroupVersion.WithKind("CronJob")},
+	}
 )
 
+func getFixers() []fixer {
+	if common.PodSecurity == common.PodSecurityRestricted {
+		return append(append([]fixer{}, fixers...), podSecurityFixers...)
+	}
+	return fixers
+}
+
 // Fix fixes kubernetes objects
 func Fix(obj runtime.Object) runtime.Object {
 	objgv := obj.GetObjectKind().GroupVersionKind().GroupVersion()
-	for _, fixer := range fixers {
+	for _, fixer := range getFixers() {
 		fgvk := fixer.getGroupVersionKind()
 		if fgvk.Kind == obj.GetObjectKind().GroupVersionKind().Kind {
 			logrus.Debugf("Running fixer %T", fixer)
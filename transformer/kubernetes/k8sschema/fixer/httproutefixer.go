@@ -0,0 +1,49 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+type httpRouteFixer struct {
+}
+
+func (f httpRouteFixer) getGroupVersionKind() schema.GroupVersionKind {
+	return gatewayv1beta1.SchemeGroupVersion.WithKind("HTTPRoute")
+}
+
+func (f httpRouteFixer) fix(obj runtime.Object) (runtime.Object, error) {
+	ptf := gatewayv1beta1.PathMatchPathPrefix
+	r, ok := obj.(*gatewayv1beta1.HTTPRoute)
+	if !ok {
+		return obj, fmt.Errorf("non Matching type. Expected HTTPRoute : Got %T", obj)
+	}
+	for ri, rule := range r.Spec.Rules {
+		for mi, m := range rule.Matches {
+			if m.Path != nil && m.Path.Type == nil {
+				r.Spec.Rules[ri].Matches[mi].Path.Type = &ptf
+			}
+		}
+	}
+	obj = r
+	return obj, nil
+}
@@ -0,0 +1,80 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// podSecurityFixer hardens the pods of a workload to comply with the restricted pod security level
+type podSecurityFixer struct {
+	gvk schema.GroupVersionKind
+}
+
+func (f podSecurityFixer) getGroupVersionKind() schema.GroupVersionKind {
+	return f.gvk
+}
+
+func (f podSecurityFixer) fix(obj runtime.Object) (runtime.Object, error) {
+	podSpec := k8sschema.GetPodSpec(obj)
+	if podSpec == nil {
+		return obj, fmt.Errorf("non Matching type. Expected %s : Got %T", f.gvk.Kind, obj)
+	}
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &core.PodSecurityContext{}
+	}
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext.SeccompProfile == nil {
+		podSecurityContext.SeccompProfile = &core.SeccompProfile{Type: core.SeccompProfileTypeRuntimeDefault}
+	}
+	podRunAsNonRoot := podSecurityContext.RunAsNonRoot != nil && *podSecurityContext.RunAsNonRoot
+	podRunAsRoot := podSecurityContext.RunAsUser != nil && *podSecurityContext.RunAsUser == 0
+	for _, containers := range [][]core.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			if containers[i].SecurityContext == nil {
+				containers[i].SecurityContext = &core.SecurityContext{}
+			}
+			securityContext := containers[i].SecurityContext
+			if securityContext.AllowPrivilegeEscalation == nil {
+				securityContext.AllowPrivilegeEscalation = new(bool)
+			}
+			if securityContext.Capabilities == nil {
+				securityContext.Capabilities = &core.Capabilities{}
+			}
+			dropsAll := false
+			for _, capability := range securityContext.Capabilities.Drop {
+				if capability == k8sschema.AllCapabilities {
+					dropsAll = true
+				}
+			}
+			if !dropsAll {
+				securityContext.Capabilities.Drop = []core.Capability{k8sschema.AllCapabilities}
+			}
+			runAsRoot := securityContext.RunAsUser != nil && *securityContext.RunAsUser == 0 || securityContext.RunAsUser == nil && podRunAsRoot
+			if securityContext.RunAsNonRoot == nil && !podRunAsNonRoot && !runAsRoot {
+				runAsNonRoot := true
+				securityContext.RunAsNonRoot = &runAsNonRoot
+			}
+		}
+	}
+	return obj, nil
+}
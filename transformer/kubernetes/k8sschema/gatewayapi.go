@@ -0,0 +1,51 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// addGatewayAPIConversionFuncs adds the conversions between the v1alpha2 and v1beta1 Gateways and HTTPRoutes.
+// The v1alpha2 kinds are defined using the v1beta1 types, so the conversions do not lose any fields.
+func addGatewayAPIConversionFuncs(s *runtime.Scheme) error {
+	if err := s.AddConversionFunc((*gatewayv1alpha2.Gateway)(nil), (*gatewayv1beta1.Gateway)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		*b.(*gatewayv1beta1.Gateway) = gatewayv1beta1.Gateway(*a.(*gatewayv1alpha2.Gateway))
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*gatewayv1beta1.Gateway)(nil), (*gatewayv1alpha2.Gateway)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		*b.(*gatewayv1alpha2.Gateway) = gatewayv1alpha2.Gateway(*a.(*gatewayv1beta1.Gateway))
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := s.AddConversionFunc((*gatewayv1alpha2.HTTPRoute)(nil), (*gatewayv1beta1.HTTPRoute)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		*b.(*gatewayv1beta1.HTTPRoute) = gatewayv1beta1.HTTPRoute(*a.(*gatewayv1alpha2.HTTPRoute))
+		return nil
+	}); err != nil {
+		return err
+	}
+	return s.AddConversionFunc((*gatewayv1beta1.HTTPRoute)(nil), (*gatewayv1alpha2.HTTPRoute)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		*b.(*gatewayv1alpha2.HTTPRoute) = gatewayv1alpha2.HTTPRoute(*a.(*gatewayv1beta1.HTTPRoute))
+		return nil
+	})
+}
@@ -0,0 +1,242 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"github.com/konveyor/move2kube/common/deepcopy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The istio resources used by move2kube. Only the fields used by move2kube are defined.
+
+const (
+	// VirtualServiceKind is the kind of the istio resource which routes the requests to a host
+	VirtualServiceKind = "VirtualService"
+	// DestinationRuleKind is the kind of the istio resource which configures the traffic to a host
+	DestinationRuleKind = "DestinationRule"
+	// IstioGatewayKind is the kind of the istio resource which configures the ingress gateway
+	IstioGatewayKind = "Gateway"
+	// PeerAuthenticationKind is the kind of the istio resource which configures mutual TLS
+	PeerAuthenticationKind = "PeerAuthentication"
+	// AuthorizationPolicyKind is the kind of the istio resource which allows or denies the requests to workloads
+	AuthorizationPolicyKind = "AuthorizationPolicy"
+)
+
+var (
+	// IstioNetworkingSchemeGroupVersion is the group version of the istio networking resources
+	IstioNetworkingSchemeGroupVersion = schema.GroupVersion{Group: "networking.istio.io", Version: "v1beta1"}
+	// IstioSecuritySchemeGroupVersion is the group version of the istio security resources
+	IstioSecuritySchemeGroupVersion = schema.GroupVersion{Group: "security.istio.io", Version: "v1beta1"}
+)
+
+// VirtualService routes the requests to a host
+type VirtualService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VirtualServiceSpec `json:"spec,omitempty"`
+}
+
+// VirtualServiceSpec defines the hosts and the routes of a VirtualService
+type VirtualServiceSpec struct {
+	Hosts    []string         `json:"hosts,omitempty"`
+	Gateways []string         `json:"gateways,omitempty"`
+	HTTP     []IstioHTTPRoute `json:"http,omitempty"`
+}
+
+// IstioHTTPRoute routes the matching HTTP requests to the destinations
+type IstioHTTPRoute struct {
+	Name  string                  `json:"name,omitempty"`
+	Match []IstioHTTPMatch        `json:"match,omitempty"`
+	Route []IstioRouteDestination `json:"route,omitempty"`
+}
+
+// IstioHTTPMatch matches the HTTP requests
+type IstioHTTPMatch struct {
+	URI       *IstioStringMatch `json:"uri,omitempty"`
+	Authority *IstioStringMatch `json:"authority,omitempty"`
+}
+
+// IstioStringMatch matches a string
+type IstioStringMatch struct {
+	Exact  string `json:"exact,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// IstioRouteDestination is the destination of the routed requests
+type IstioRouteDestination struct {
+	Destination IstioDestination `json:"destination"`
+}
+
+// IstioDestination is a port of a host
+type IstioDestination struct {
+	Host string             `json:"host"`
+	Port *IstioPortSelector `json:"port,omitempty"`
+}
+
+// IstioPortSelector selects a port by number
+type IstioPortSelector struct {
+	Number uint32 `json:"number,omitempty"`
+}
+
+// DestinationRule configures the traffic to a host
+type DestinationRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DestinationRuleSpec `json:"spec,omitempty"`
+}
+
+// DestinationRuleSpec defines the traffic policy of a host
+type DestinationRuleSpec struct {
+	Host          string              `json:"host"`
+	TrafficPolicy *IstioTrafficPolicy `json:"trafficPolicy,omitempty"`
+}
+
+// IstioTrafficPolicy defines the TLS used by the traffic
+type IstioTrafficPolicy struct {
+	TLS *IstioClientTLSSettings `json:"tls,omitempty"`
+}
+
+// IstioClientTLSSettings defines the TLS mode of the client
+type IstioClientTLSSettings struct {
+	Mode string `json:"mode,omitempty"`
+}
+
+// IstioGateway configures the istio ingress gateway
+type IstioGateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IstioGatewaySpec `json:"spec,omitempty"`
+}
+
+// IstioGatewaySpec defines the gateway pods and the servers on them
+type IstioGatewaySpec struct {
+	Selector map[string]string `json:"selector,omitempty"`
+	Servers  []IstioServer     `json:"servers,omitempty"`
+}
+
+// IstioServer defines a port of the gateway and the hosts served on it
+type IstioServer struct {
+	Port  IstioPort               `json:"port"`
+	Hosts []string                `json:"hosts"`
+	TLS   *IstioServerTLSSettings `json:"tls,omitempty"`
+}
+
+// IstioServerTLSSettings defines the TLS of a port of the gateway
+type IstioServerTLSSettings struct {
+	Mode           string `json:"mode,omitempty"`
+	CredentialName string `json:"credentialName,omitempty"`
+}
+
+// IstioPort defines a port of the gateway
+type IstioPort struct {
+	Number   uint32 `json:"number"`
+	Protocol string `json:"protocol"`
+	Name     string `json:"name,omitempty"`
+}
+
+// PeerAuthentication configures mutual TLS
+type PeerAuthentication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PeerAuthenticationSpec `json:"spec,omitempty"`
+}
+
+// PeerAuthenticationSpec defines the mutual TLS mode
+type PeerAuthenticationSpec struct {
+	MTLS *IstioPeerAuthenticationMTLS `json:"mtls,omitempty"`
+}
+
+// IstioPeerAuthenticationMTLS defines the mutual TLS mode
+type IstioPeerAuthenticationMTLS struct {
+	Mode string `json:"mode,omitempty"`
+}
+
+// AuthorizationPolicy allows or denies the requests to workloads
+type AuthorizationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AuthorizationPolicySpec `json:"spec,omitempty"`
+}
+
+// AuthorizationPolicySpec defines the workloads and the rules of the requests
+type AuthorizationPolicySpec struct {
+	Selector *IstioWorkloadSelector `json:"selector,omitempty"`
+	Action   string                 `json:"action,omitempty"`
+	Rules    []IstioRule            `json:"rules,omitempty"`
+}
+
+// IstioWorkloadSelector selects the workloads using their labels
+type IstioWorkloadSelector struct {
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// IstioRule matches the requests from the sources
+type IstioRule struct {
+	From []IstioRuleFrom `json:"from,omitempty"`
+}
+
+// IstioRuleFrom defines the source of the requests
+type IstioRuleFrom struct {
+	Source IstioSource `json:"source"`
+}
+
+// IstioSource matches the identities of the sources
+type IstioSource struct {
+	Principals []string `json:"principals,omitempty"`
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *VirtualService) DeepCopyObject() runtime.Object {
+	out := &VirtualService{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(VirtualServiceSpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *DestinationRule) DeepCopyObject() runtime.Object {
+	out := &DestinationRule{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(DestinationRuleSpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *IstioGateway) DeepCopyObject() runtime.Object {
+	out := &IstioGateway{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(IstioGatewaySpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *PeerAuthentication) DeepCopyObject() runtime.Object {
+	out := &PeerAuthentication{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(PeerAuthenticationSpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *AuthorizationPolicy) DeepCopyObject() runtime.Object {
+	out := &AuthorizationPolicy{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(AuthorizationPolicySpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
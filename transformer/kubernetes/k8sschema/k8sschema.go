@@ -17,7 +17,10 @@
 package k8sschema
 
 import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	admissionregistration "k8s.io/kubernetes/pkg/apis/admissionregistration"
 	apps "k8s.io/kubernetes/pkg/apis/apps"
@@ -58,9 +61,12 @@ import (
 	schedulinginstall "k8s.io/kubernetes/pkg/apis/scheduling/install"
 	storageinstall "k8s.io/kubernetes/pkg/apis/storage/install"
 
+	cmapi "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	okdapi "github.com/openshift/api"
 	tektonscheme "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
 	k8sapischeme "k8s.io/client-go/kubernetes/scheme"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 // K8sResourceT represents type used to process K8s objects. Not using type alias breaks parameterizer currently.
@@ -77,6 +83,22 @@ func init() {
 
 	must(k8sapischeme.AddToScheme(scheme))
 	must(tektonscheme.AddToScheme(scheme))
+	must(gatewayv1alpha2.AddToScheme(scheme))
+	must(gatewayv1beta1.AddToScheme(scheme))
+	must(addGatewayAPIConversionFuncs(scheme))
+	must(cmapi.AddToScheme(scheme))
+	scheme.AddKnownTypes(ExternalSecretsSchemeGroupVersion, &ExternalSecret{}, &ExternalSecretList{})
+	metav1.AddToGroupVersion(scheme, ExternalSecretsSchemeGroupVersion)
+	scheme.AddKnownTypes(SealedSecretsSchemeGroupVersion, &SealedSecret{}, &SealedSecretList{})
+	metav1.AddToGroupVersion(scheme, SealedSecretsSchemeGroupVersion)
+	scheme.AddKnownTypes(IstioNetworkingSchemeGroupVersion, &VirtualService{}, &DestinationRule{})
+	scheme.AddKnownTypeWithName(IstioNetworkingSchemeGroupVersion.WithKind(IstioGatewayKind), &IstioGateway{})
+	metav1.AddToGroupVersion(scheme, IstioNetworkingSchemeGroupVersion)
+	scheme.AddKnownTypes(IstioSecuritySchemeGroupVersion, &PeerAuthentication{}, &AuthorizationPolicy{})
+	metav1.AddToGroupVersion(scheme, IstioSecuritySchemeGroupVersion)
+	scheme.AddKnownTypeWithName(LinkerdPolicySchemeGroupVersion.WithKind(LinkerdServerKind), &LinkerdServer{})
+	scheme.AddKnownTypes(LinkerdPolicySchemeGroupVersion, &ServerAuthorization{})
+	metav1.AddToGroupVersion(scheme, LinkerdPolicySchemeGroupVersion)
 
 	appsinstall.Install(scheme)
 	admissionregistrationinstall.Install(scheme)
@@ -97,6 +119,10 @@ func init() {
 	rbacinstall.Install(scheme)
 	schedulinginstall.Install(scheme)
 	storageinstall.Install(scheme)
+	// autoscaling/v2 is not available in the kubernetes version in use. It has the same schema as autoscaling/v2beta2.
+	autoscalingv2 := schema.GroupVersion{Group: autoscalingv2beta2.GroupName, Version: "v2"}
+	scheme.AddKnownTypeWithName(autoscalingv2.WithKind("HorizontalPodAutoscaler"), &autoscalingv2beta2.HorizontalPodAutoscaler{})
+	scheme.AddKnownTypeWithName(autoscalingv2.WithKind("HorizontalPodAutoscalerList"), &autoscalingv2beta2.HorizontalPodAutoscalerList{})
 
 	must(apps.AddToScheme(liasonscheme))
 	must(admissionregistration.AddToScheme(liasonscheme))
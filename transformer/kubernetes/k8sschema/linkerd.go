@@ -0,0 +1,103 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"github.com/konveyor/move2kube/common/deepcopy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// The linkerd resources used by move2kube. Only the fields used by move2kube are defined.
+
+const (
+	// LinkerdServerKind is the kind of the linkerd resource which selects a port of the pods
+	LinkerdServerKind = "Server"
+	// ServerAuthorizationKind is the kind of the linkerd resource which allows the clients of a Server
+	ServerAuthorizationKind = "ServerAuthorization"
+)
+
+var (
+	// LinkerdPolicySchemeGroupVersion is the group version of the linkerd policy resources
+	LinkerdPolicySchemeGroupVersion = schema.GroupVersion{Group: "policy.linkerd.io", Version: "v1beta1"}
+)
+
+// LinkerdServer selects a port of the pods to which the policies are applied
+type LinkerdServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LinkerdServerSpec `json:"spec,omitempty"`
+}
+
+// LinkerdServerSpec defines the pods and their port
+type LinkerdServerSpec struct {
+	PodSelector   *metav1.LabelSelector `json:"podSelector"`
+	Port          intstr.IntOrString    `json:"port"`
+	ProxyProtocol string                `json:"proxyProtocol,omitempty"`
+}
+
+// ServerAuthorization allows the clients of a Server
+type ServerAuthorization struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServerAuthorizationSpec `json:"spec,omitempty"`
+}
+
+// ServerAuthorizationSpec defines the Server and its clients
+type ServerAuthorizationSpec struct {
+	Server LinkerdServerRef `json:"server"`
+	Client LinkerdClient    `json:"client"`
+}
+
+// LinkerdServerRef refers to a Server by name
+type LinkerdServerRef struct {
+	Name string `json:"name,omitempty"`
+}
+
+// LinkerdClient defines the clients which are allowed
+type LinkerdClient struct {
+	MeshTLS *LinkerdMeshTLS `json:"meshTLS,omitempty"`
+}
+
+// LinkerdMeshTLS allows the meshed clients which run with the service accounts
+type LinkerdMeshTLS struct {
+	ServiceAccounts []LinkerdServiceAccountName `json:"serviceAccounts,omitempty"`
+}
+
+// LinkerdServiceAccountName refers to a service account
+type LinkerdServiceAccountName struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *LinkerdServer) DeepCopyObject() runtime.Object {
+	out := &LinkerdServer{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(LinkerdServerSpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *ServerAuthorization) DeepCopyObject() runtime.Object {
+	out := &ServerAuthorization{TypeMeta: in.TypeMeta, Spec: deepcopy.DeepCopy(in.Spec).(ServerAuthorizationSpec)}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
@@ -0,0 +1,229 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	okdappsv1 "github.com/openshift/api/apps/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// AllCapabilities is used to drop all the capabilities of a container
+	AllCapabilities core.Capability = "ALL"
+)
+
+var (
+	// baselineCapabilities are the capabilities which can be added in the baseline pod security level
+	baselineCapabilities = []string{"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT"}
+	// baselineSELinuxTypes are the SELinux types which can be used in the baseline pod security level
+	baselineSELinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t"}
+	// baselineSysctls are the safe sysctls which can be used in the baseline pod security level
+	baselineSysctls = []string{"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start", "net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range"}
+)
+
+// GetPodSpec returns the pod spec of a workload in the liason scheme. It returns nil if the object does not have a pod spec.
+func GetPodSpec(obj runtime.Object) *core.PodSpec {
+	switch o := obj.(type) {
+	case *core.Pod:
+		return &o.Spec
+	case *core.ReplicationController:
+		if o.Spec.Template != nil {
+			return &o.Spec.Template.Spec
+		}
+	case *apps.Deployment:
+		return &o.Spec.Template.Spec
+	case *apps.StatefulSet:
+		return &o.Spec.Template.Spec
+	case *apps.DaemonSet:
+		return &o.Spec.Template.Spec
+	case *apps.ReplicaSet:
+		return &o.Spec.Template.Spec
+	case *batch.Job:
+		return &o.Spec.Template.Spec
+	case *batch.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template.Spec
+	}
+	return nil
+}
+
+// GetPodSecurityViolations returns the ways in which the pods of a workload violate the pod security level
+// See https://kubernetes.io/docs/concepts/security/pod-security-standards/
+func GetPodSecurityViolations(obj runtime.Object, level string) []string {
+	if level != common.PodSecurityBaseline && level != common.PodSecurityRestricted {
+		return nil
+	}
+	var podSpec *core.PodSpec
+	if dc, ok := obj.(*okdappsv1.DeploymentConfig); ok {
+		if dc.Spec.Template == nil {
+			return nil
+		}
+		uvPodSpec := ConvertToPodSpec(&dc.Spec.Template.Spec)
+		podSpec = &uvPodSpec
+	} else {
+		iobj, err := ConvertToLiasonScheme(obj)
+		if err != nil {
+			logrus.Debugf("Unable to convert %s to the liason scheme to validate the pod security : %s", obj.GetObjectKind().GroupVersionKind(), err)
+			return nil
+		}
+		podSpec = GetPodSpec(iobj)
+	}
+	if podSpec == nil {
+		return nil
+	}
+	violations := getBaselineViolations(*podSpec)
+	if level == common.PodSecurityRestricted {
+		violations = append(violations, getRestrictedViolations(*podSpec)...)
+	}
+	return violations
+}
+
+func getBaselineViolations(podSpec core.PodSpec) []string {
+	violations := []string{}
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext == nil {
+		podSecurityContext = &core.PodSecurityContext{}
+	}
+	if podSecurityContext.HostNetwork || podSecurityContext.HostPID || podSecurityContext.HostIPC {
+		violations = append(violations, "the host namespaces must not be shared")
+	}
+	if isSeccompUnconfined(podSecurityContext.SeccompProfile) {
+		violations = append(violations, "the seccomp profile must not be Unconfined")
+	}
+	if isSELinuxOptionsForbidden(podSecurityContext.SELinuxOptions) {
+		violations = append(violations, "the SELinux user and role must not be set and the SELinux type must be one of "+strings.Join(baselineSELinuxTypes[1:], ", "))
+	}
+	for _, sysctl := range podSecurityContext.Sysctls {
+		if !common.IsStringPresent(baselineSysctls, sysctl.Name) {
+			violations = append(violations, fmt.Sprintf("the sysctl %s must not be set", sysctl.Name))
+		}
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			violations = append(violations, fmt.Sprintf("the volume %s must not be a hostPath volume", volume.Name))
+		}
+	}
+	for _, container := range getAllContainers(podSpec) {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				violations = append(violations, fmt.Sprintf("the container %s must not use the host port %d", container.Name, port.HostPort))
+			}
+		}
+		securityContext := container.SecurityContext
+		if securityContext == nil {
+			continue
+		}
+		if securityContext.Privileged != nil && *securityContext.Privileged {
+			violations = append(violations, fmt.Sprintf("the container %s must not be privileged", container.Name))
+		}
+		if securityContext.Capabilities != nil {
+			for _, capability := range securityContext.Capabilities.Add {
+				if !common.IsStringPresent(baselineCapabilities, string(capability)) {
+					violations = append(violations, fmt.Sprintf("the container %s must not add the capability %s", container.Name, capability))
+				}
+			}
+		}
+		if isSeccompUnconfined(securityContext.SeccompProfile) {
+			violations = append(violations, fmt.Sprintf("the seccomp profile of the container %s must not be Unconfined", container.Name))
+		}
+		if isSELinuxOptionsForbidden(securityContext.SELinuxOptions) {
+			violations = append(violations, fmt.Sprintf("the SELinux user and role of the container %s must not be set and the SELinux type must be one of %s", container.Name, strings.Join(baselineSELinuxTypes[1:], ", ")))
+		}
+		if securityContext.ProcMount != nil && *securityContext.ProcMount != core.DefaultProcMount {
+			violations = append(violations, fmt.Sprintf("the proc mount of the container %s must be %s", container.Name, core.DefaultProcMount))
+		}
+	}
+	return violations
+}
+
+func getRestrictedViolations(podSpec core.PodSpec) []string {
+	violations := []string{}
+	podSecurityContext := podSpec.SecurityContext
+	if podSecurityContext == nil {
+		podSecurityContext = &core.PodSecurityContext{}
+	}
+	for _, volume := range podSpec.Volumes {
+		vs := volume.VolumeSource
+		if vs.ConfigMap == nil && vs.CSI == nil && vs.DownwardAPI == nil && vs.EmptyDir == nil && vs.Ephemeral == nil && vs.PersistentVolumeClaim == nil && vs.Projected == nil && vs.Secret == nil {
+			violations = append(violations, fmt.Sprintf("the volume %s must be a configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected or secret volume", volume.Name))
+		}
+	}
+	if podSecurityContext.RunAsUser != nil && *podSecurityContext.RunAsUser == 0 {
+		violations = append(violations, "the pods must not run as root")
+	}
+	podRunAsNonRoot := podSecurityContext.RunAsNonRoot != nil && *podSecurityContext.RunAsNonRoot
+	podSeccomp := isSeccompRestricted(podSecurityContext.SeccompProfile)
+	for _, container := range getAllContainers(podSpec) {
+		securityContext := container.SecurityContext
+		if securityContext == nil {
+			securityContext = &core.SecurityContext{}
+		}
+		if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+			violations = append(violations, fmt.Sprintf("the container %s must set allowPrivilegeEscalation to false", container.Name))
+		}
+		if securityContext.RunAsNonRoot != nil && !*securityContext.RunAsNonRoot || securityContext.RunAsNonRoot == nil && !podRunAsNonRoot {
+			violations = append(violations, fmt.Sprintf("the container %s must set runAsNonRoot to true", container.Name))
+		}
+		if securityContext.RunAsUser != nil && *securityContext.RunAsUser == 0 {
+			violations = append(violations, fmt.Sprintf("the container %s must not run as root", container.Name))
+		}
+		if !podSeccomp && !isSeccompRestricted(securityContext.SeccompProfile) {
+			violations = append(violations, fmt.Sprintf("the seccomp profile of the container %s must be RuntimeDefault or Localhost", container.Name))
+		}
+		dropsAll := false
+		if securityContext.Capabilities != nil {
+			for _, capability := range securityContext.Capabilities.Drop {
+				if capability == AllCapabilities {
+					dropsAll = true
+				}
+			}
+			for _, capability := range securityContext.Capabilities.Add {
+				if capability != "NET_BIND_SERVICE" {
+					violations = append(violations, fmt.Sprintf("the container %s must not add any capability other than NET_BIND_SERVICE", container.Name))
+					break
+				}
+			}
+		}
+		if !dropsAll {
+			violations = append(violations, fmt.Sprintf("the container %s must drop ALL capabilities", container.Name))
+		}
+	}
+	return violations
+}
+
+func getAllContainers(podSpec core.PodSpec) []core.Container {
+	return append(append([]core.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+}
+
+func isSeccompUnconfined(seccompProfile *core.SeccompProfile) bool {
+	return seccompProfile != nil && seccompProfile.Type == core.SeccompProfileTypeUnconfined
+}
+
+func isSeccompRestricted(seccompProfile *core.SeccompProfile) bool {
+	return seccompProfile != nil && (seccompProfile.Type == core.SeccompProfileTypeRuntimeDefault || seccompProfile.Type == core.SeccompProfileTypeLocalhost)
+}
+
+func isSELinuxOptionsForbidden(seLinuxOptions *core.SELinuxOptions) bool {
+	return seLinuxOptions != nil && (seLinuxOptions.User != "" || seLinuxOptions.Role != "" || !common.IsStringPresent(baselineSELinuxTypes, seLinuxOptions.Type))
+}
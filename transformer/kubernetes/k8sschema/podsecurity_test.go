@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGetPodSecurityViolations(t *testing.T) {
+	trueValue, falseValue := true, false
+	rootUser := int64(0)
+	getPod := func(podSpec corev1.PodSpec) runtime.Object {
+		return &corev1.Pod{TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: corev1.SchemeGroupVersion.String()}, Spec: podSpec}
+	}
+	hardenedContainer := corev1.Container{
+		Name: "app",
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot:             &trueValue,
+			AllowPrivilegeEscalation: &falseValue,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+	hardenedPodSpec := corev1.PodSpec{
+		SecurityContext: &corev1.PodSecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
+		Containers:      []corev1.Container{hardenedContainer},
+	}
+	testcases := []struct {
+		name  string
+		obj   runtime.Object
+		level string
+		want  []string
+	}{
+		{
+			name:  "no level",
+			obj:   getPod(corev1.PodSpec{HostNetwork: true, Containers: []corev1.Container{{Name: "app"}}}),
+			level: "",
+		},
+		{
+			name:  "privileged level",
+			obj:   getPod(corev1.PodSpec{HostNetwork: true, Containers: []corev1.Container{{Name: "app"}}}),
+			level: common.PodSecurityPrivileged,
+		},
+		{
+			name:  "not a workload",
+			obj:   &corev1.Service{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: corev1.SchemeGroupVersion.String()}},
+			level: common.PodSecurityRestricted,
+		},
+		{
+			name:  "baseline compliant pod",
+			obj:   getPod(corev1.PodSpec{Containers: []corev1.Container{{Name: "app", SecurityContext: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}}}}}}),
+			level: common.PodSecurityBaseline,
+			want:  []string{},
+		},
+		{
+			name: "baseline violations",
+			obj: getPod(corev1.PodSpec{
+				HostNetwork:     true,
+				SecurityContext: &corev1.PodSecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}, Sysctls: []corev1.Sysctl{{Name: "kernel.msgmax", Value: "65536"}}},
+				Volumes:         []corev1.Volume{{Name: "host", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run"}}}},
+				Containers: []corev1.Container{{
+					Name:            "app",
+					Ports:           []corev1.ContainerPort{{ContainerPort: 8080, HostPort: 80}},
+					SecurityContext: &corev1.SecurityContext{Privileged: &trueValue, Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}}},
+				}},
+			}),
+			level: common.PodSecurityBaseline,
+			want: []string{
+				"the host namespaces must not be shared",
+				"the seccomp profile must not be Unconfined",
+				"the sysctl kernel.msgmax must not be set",
+				"the volume host must not be a hostPath volume",
+				"the container app must not use the host port 80",
+				"the container app must not be privileged",
+				"the container app must not add the capability SYS_ADMIN",
+			},
+		},
+		{
+			name:  "restricted compliant pod",
+			obj:   getPod(hardenedPodSpec),
+			level: common.PodSecurityRestricted,
+			want:  []string{},
+		},
+		{
+			name: "restricted compliant deployment",
+			obj: &appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{Kind: common.DeploymentKind, APIVersion: appsv1.SchemeGroupVersion.String()},
+				Spec:     appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: hardenedPodSpec}},
+			},
+			level: common.PodSecurityRestricted,
+			want:  []string{},
+		},
+		{
+			name:  "unhardened pod",
+			obj:   getPod(corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}),
+			level: common.PodSecurityRestricted,
+			want: []string{
+				"the container app must set allowPrivilegeEscalation to false",
+				"the container app must set runAsNonRoot to true",
+				"the seccomp profile of the container app must be RuntimeDefault or Localhost",
+				"the container app must drop ALL capabilities",
+			},
+		},
+		{
+			name: "restricted violations",
+			obj: getPod(corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: &rootUser, RunAsNonRoot: &trueValue, SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
+				Volumes:         []corev1.Volume{{Name: "nfs", VolumeSource: corev1.VolumeSource{NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/data"}}}},
+				InitContainers:  []corev1.Container{{Name: "init", SecurityContext: &corev1.SecurityContext{AllowPrivilegeEscalation: &trueValue, Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}}}},
+				Containers: []corev1.Container{{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						RunAsNonRoot:             &falseValue,
+						AllowPrivilegeEscalation: &falseValue,
+						Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}, Add: []corev1.Capability{"CHOWN"}},
+					},
+				}},
+			}),
+			level: common.PodSecurityRestricted,
+			want: []string{
+				"the volume nfs must be a configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected or secret volume",
+				"the pods must not run as root",
+				"the container init must set allowPrivilegeEscalation to false",
+				"the container app must set runAsNonRoot to true",
+				"the container app must not add any capability other than NET_BIND_SERVICE",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := GetPodSecurityViolations(tc.obj, tc.level)
+			if diff := cmp.Diff(tc.want, actual); diff != "" {
+				t.Fatalf("The pod security violations are incorrect. Differences:\n%s", diff)
+			}
+		})
+	}
+}
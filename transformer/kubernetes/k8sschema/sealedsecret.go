@@ -0,0 +1,110 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// SealedSecretKind is the kind of the sealed secrets resource which has the encrypted data of a secret
+	SealedSecretKind = "SealedSecret"
+	// SealedSecretClusterWideAnnotation allows a sealed secret to be decrypted with any name in any namespace
+	SealedSecretClusterWideAnnotation = "sealedsecrets.bitnami.com/cluster-wide"
+)
+
+var (
+	// SealedSecretsSchemeGroupVersion is the group version of the sealed secrets resources
+	SealedSecretsSchemeGroupVersion = schema.GroupVersion{Group: "bitnami.com", Version: "v1alpha1"}
+)
+
+// SealedSecret has the data of a secret encrypted with the public key of the sealed secrets controller.
+// Only the fields used by move2kube are defined.
+type SealedSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SealedSecretSpec `json:"spec"`
+}
+
+// SealedSecretList contains a list of SealedSecret
+type SealedSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SealedSecret `json:"items"`
+}
+
+// SealedSecretSpec defines the encrypted data and the template of the secret which is created
+type SealedSecretSpec struct {
+	Template      SecretTemplateSpec `json:"template,omitempty"`
+	EncryptedData map[string]string  `json:"encryptedData"`
+}
+
+// SecretTemplateSpec defines the metadata and the type of the secret which is created
+type SecretTemplateSpec struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Type              corev1.SecretType `json:"type,omitempty"`
+}
+
+// DeepCopyInto copies the receiver into out
+func (in *SealedSecret) DeepCopyInto(out *SealedSecret) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.Template.ObjectMeta.DeepCopyInto(&out.Spec.Template.ObjectMeta)
+	if in.Spec.EncryptedData != nil {
+		out.Spec.EncryptedData = make(map[string]string, len(in.Spec.EncryptedData))
+		for k, v := range in.Spec.EncryptedData {
+			out.Spec.EncryptedData[k] = v
+		}
+	}
+}
+
+// DeepCopy copies the receiver into a new SealedSecret
+func (in *SealedSecret) DeepCopy() *SealedSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(SealedSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *SealedSecret) DeepCopyObject() runtime.Object {
+	return in.DeepCopy()
+}
+
+// DeepCopyObject copies the receiver into a new runtime.Object
+func (in *SealedSecretList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(SealedSecretList)
+	*out = *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]SealedSecret, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+	return out
+}
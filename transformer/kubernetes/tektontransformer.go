@@ -126,6 +126,7 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		}
 		apis := []apiresource.IAPIResource{
 			new(apiresource.Service),
+			new(apiresource.Istio),
 			new(apiresource.ServiceAccount),
 			new(apiresource.RoleBinding),
 			new(apiresource.Role),
@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -61,12 +62,19 @@ type Transformer interface {
 	Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
 }
 
+// destroyableTransformer is implemented by the transformers which hold resources that have to be released
+type destroyableTransformer interface {
+	Destroy() error
+}
+
 func init() {
 	transformerObjs := []Transformer{
 		new(external.Starlark),
 		new(external.Executable),
+		new(external.WASM),
 
 		new(Router),
+		new(CronAnalyser),
 
 		new(dockerfile.DockerfileDetector),
 		new(dockerfile.DockerfileParser),
@@ -171,6 +179,7 @@ func InitTransformers(transformerToInit map[string]string, selector labels.Selec
 				Output:          outputPath,
 				Context:         transformerContextPath,
 				RelTemplatesDir: tc.Spec.TemplatesDir,
+				Env:             getTransformerEnv(tc),
 			}
 			for src, dest := range tc.Spec.ExternalFiles {
 				err := filesystem.Replicate(filepath.Join(transformerContextPath, src), filepath.Join(transformerContextPath, dest))
@@ -178,7 +187,7 @@ func InitTransformers(transformerToInit map[string]string, selector labels.Selec
 					logrus.Errorf("Error while copying external files in transformer %s (%s:%s) : %s", tc.Name, src, dest, err)
 				}
 			}
-			env, err := environment.NewEnvironment(envInfo, nil, environmenttypes.Container{})
+			env, err := environment.NewEnvironment(envInfo, nil, environmenttypes.Container{}, environmenttypes.Remote{})
 			if err != nil {
 				logrus.Errorf("Unable to create environment : %s", err)
 				return err
@@ -199,9 +208,54 @@ func InitTransformers(transformerToInit map[string]string, selector labels.Selec
 	return nil
 }
 
+// getTransformerEnv returns the environment variables of the transformer in the form KEY=VALUE.
+// The values fetched from the env sources are redacted from the logs.
+func getTransformerEnv(tc transformertypes.Transformer) []string {
+	env := []string{}
+	for _, envVar := range tc.Spec.Env {
+		env = append(env, envVar.Name+"="+envVar.Value)
+	}
+	for _, envFrom := range tc.Spec.EnvFrom {
+		value := ""
+		switch {
+		case envFrom.HostEnv != "":
+			var ok bool
+			if value, ok = os.LookupEnv(envFrom.HostEnv); !ok {
+				logrus.Warnf("The environment variable %s required by the transformer %s is not set", envFrom.HostEnv, tc.Name)
+				continue
+			}
+		case envFrom.File != "":
+			path := envFrom.File
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(filepath.Dir(tc.Spec.FilePath), path)
+			}
+			valueBytes, err := os.ReadFile(path)
+			if err != nil {
+				logrus.Errorf("Unable to read the value of the environment variable %s of the transformer %s from %s : %s", envFrom.Name, tc.Name, path, err)
+				continue
+			}
+			value = strings.TrimRight(string(valueBytes), "\r\n")
+		case envFrom.Password:
+			qaKey := common.ConfigTransformersKey + common.Delim + `"` + tc.Name + `"` + common.Delim + "env" + common.Delim + `"` + envFrom.Name + `"`
+			value = qaengine.FetchPasswordAnswer(qaKey, fmt.Sprintf("Enter the value of the environment variable %s for the transformer %s :", envFrom.Name, tc.Name), []string{"The value is passed to the commands run by the transformer."})
+		default:
+			logrus.Errorf("No source found for the environment variable %s of the transformer %s", envFrom.Name, tc.Name)
+			continue
+		}
+		common.AddSecretToRedact(value)
+		env = append(env, envFrom.Name+"="+value)
+	}
+	return env
+}
+
 // Destroy destroys the transformers
 func Destroy() {
 	for _, t := range transformers {
+		if dt, ok := t.(destroyableTransformer); ok {
+			if err := dt.Destroy(); err != nil {
+				logrus.Errorf("Unable to destroy transformer : %s", err)
+			}
+		}
 		_, env := t.GetConfig()
 		if err := env.Destroy(); err != nil {
 			logrus.Errorf("Unable to destroy environment : %s", err)
@@ -0,0 +1,136 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// testAnswerEngine answers the problems with the given ids
+type testAnswerEngine struct {
+	answers map[string]interface{}
+}
+
+func (*testAnswerEngine) StartEngine() error {
+	return nil
+}
+
+func (*testAnswerEngine) IsInteractiveEngine() bool {
+	return false
+}
+
+func (e *testAnswerEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	answer, ok := e.answers[prob.ID]
+	if !ok {
+		return prob, fmt.Errorf("no answer for %s", prob.ID)
+	}
+	err := prob.SetAnswer(answer)
+	return prob, err
+}
+
+// addTestAnswerEngine answers the problems with the given ids until the end of the test
+func addTestAnswerEngine(t *testing.T, answers map[string]interface{}) {
+	t.Helper()
+	engine := &testAnswerEngine{answers: answers}
+	if err := qaengine.AddEngineHighestPriority(engine); err != nil {
+		t.Fatalf("failed to add the test answer engine. Error: %q", err)
+	}
+	t.Cleanup(func() { qaengine.RemoveEngine(engine) })
+}
+
+func TestGetTransformerEnv(t *testing.T) {
+	qaengine.StartEngine(true, 0, true, false, 0)
+	transformerDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(transformerDir, "secrets"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the secrets directory. Error: %q", err)
+	}
+	for name, content := range map[string]string{"token": "mytoken\r\n\n", "key": "mykey"} {
+		if err := os.WriteFile(filepath.Join(transformerDir, "secrets", name), []byte(content), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", name, err)
+		}
+	}
+	t.Setenv("M2K_TEST_HOST_ENV", "hostvalue")
+	addTestAnswerEngine(t, map[string]interface{}{`move2kube.transformers."mytransformer".env."PASSWORD"`: "mypassword"})
+
+	testCases := []struct {
+		name    string
+		env     []transformertypes.EnvVar
+		envFrom []transformertypes.EnvFromSource
+		want    []string
+	}{
+		{
+			name: "values",
+			env:  []transformertypes.EnvVar{{Name: "A", Value: "a"}, {Name: "B", Value: ""}},
+			want: []string{"A=a", "B="},
+		},
+		{
+			name:    "host env",
+			envFrom: []transformertypes.EnvFromSource{{Name: "FROM_HOST", HostEnv: "M2K_TEST_HOST_ENV"}},
+			want:    []string{"FROM_HOST=hostvalue"},
+		},
+		{
+			name:    "missing host env",
+			envFrom: []transformertypes.EnvFromSource{{Name: "FROM_HOST", HostEnv: "M2K_TEST_MISSING_HOST_ENV"}},
+			want:    []string{},
+		},
+		{
+			name: "file relative to the transformer yaml and trimmed",
+			envFrom: []transformertypes.EnvFromSource{
+				{Name: "TOKEN", File: filepath.Join("secrets", "token")},
+				{Name: "KEY", File: filepath.Join(transformerDir, "secrets", "key")},
+			},
+			want: []string{"TOKEN=mytoken", "KEY=mykey"},
+		},
+		{
+			name:    "missing file",
+			envFrom: []transformertypes.EnvFromSource{{Name: "TOKEN", File: "missing"}},
+			want:    []string{},
+		},
+		{
+			name:    "password",
+			envFrom: []transformertypes.EnvFromSource{{Name: "PASSWORD", Password: true}},
+			want:    []string{"PASSWORD=mypassword"},
+		},
+		{
+			name:    "missing source",
+			env:     []transformertypes.EnvVar{{Name: "A", Value: "a"}},
+			envFrom: []transformertypes.EnvFromSource{{Name: "NOSOURCE"}},
+			want:    []string{"A=a"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transformer := transformertypes.Transformer{}
+			transformer.Name = "mytransformer"
+			transformer.Spec.FilePath = filepath.Join(transformerDir, "transformer.yaml")
+			transformer.Spec.Env = tc.env
+			transformer.Spec.EnvFrom = tc.envFrom
+			if diff := cmp.Diff(tc.want, getTransformerEnv(transformer)); diff != "" {
+				t.Fatalf("the environment is incorrect. Difference:\n%s", diff)
+			}
+		})
+	}
+}
@@ -41,6 +41,14 @@ type CfApps struct {
 type CfApp struct {
 	Application cfclient.App    `yaml:"application"`
 	Environment cfclient.AppEnv `yaml:"environment"`
+	Tasks       []CfTask        `yaml:"tasks,omitempty"`
+}
+
+// CfTask defines a task of a CfApp which is run by the scheduler
+type CfTask struct {
+	Name      string   `yaml:"name"`
+	Command   string   `yaml:"command"`
+	Schedules []string `yaml:"schedules,omitempty"` // Cron expressions of the enabled schedules of the task
 }
 
 // CfAppsSpec stores the data
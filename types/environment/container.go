@@ -16,11 +16,80 @@
 
 package environment
 
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NetworkMode is the network access of the environment
+type NetworkMode string
+
+const (
+	// NetworkModeNone disables network access
+	NetworkModeNone NetworkMode = "none"
+	// NetworkModeHost shares the network of the host
+	NetworkModeHost NetworkMode = "host"
+	// NetworkModeBridge uses the default bridge network of the container engine
+	NetworkModeBridge NetworkMode = "bridge"
+)
+
 // Container stores container based execution information
 type Container struct {
 	Image          string         `yaml:"image"`
 	WorkingDir     string         `yaml:"workingDir,omitempty"`
 	ContainerBuild ContainerBuild `yaml:"build"`
+	Resources      Resources      `yaml:"resources,omitempty"`
+	Network        NetworkMode    `yaml:"network,omitempty"` // Default : Default network of the container engine
+}
+
+// Resources stores the resource limits of the environment
+type Resources struct {
+	CPU    string `yaml:"cpu,omitempty"`    // Number of cpus, for example 0.5 or 500m
+	Memory string `yaml:"memory,omitempty"` // Memory in bytes, for example 512Mi or 1G
+	PIDs   int64  `yaml:"pids,omitempty"`   // Maximum number of processes
+}
+
+// GetMilliCPUs returns the cpu limit in thousandths of a cpu, or 0 if there is no limit
+func (r Resources) GetMilliCPUs() (int64, error) {
+	if r.CPU == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(r.CPU)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpu limit %s : %w", r.CPU, err)
+	}
+	return q.MilliValue(), nil
+}
+
+// GetMemoryBytes returns the memory limit in bytes, or 0 if there is no limit
+func (r Resources) GetMemoryBytes() (int64, error) {
+	if r.Memory == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(r.Memory)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit %s : %w", r.Memory, err)
+	}
+	return q.Value(), nil
+}
+
+// Validate checks the resource limits and the network mode
+func (c Container) Validate() error {
+	if _, err := c.Resources.GetMilliCPUs(); err != nil {
+		return err
+	}
+	if _, err := c.Resources.GetMemoryBytes(); err != nil {
+		return err
+	}
+	if c.Resources.PIDs < 0 {
+		return fmt.Errorf("invalid pids limit %d", c.Resources.PIDs)
+	}
+	switch c.Network {
+	case "", NetworkModeNone, NetworkModeHost, NetworkModeBridge:
+		return nil
+	}
+	return fmt.Errorf("unsupported network mode %s. Supported modes are %s, %s and %s", c.Network, NetworkModeNone, NetworkModeHost, NetworkModeBridge)
 }
 
 // ContainerBuild stores container build information
@@ -0,0 +1,25 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package environment
+
+// Remote stores the information required to run commands on a remote host over SSH
+type Remote struct {
+	Host       string `yaml:"host"`
+	Port       int    `yaml:"port,omitempty"`       // Default : 22
+	User       string `yaml:"user,omitempty"`       // Default : Current user
+	WorkingDir string `yaml:"workingDir,omitempty"` // Default : /tmp. A new directory is created in it for every environment.
+}
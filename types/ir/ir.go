@@ -27,6 +27,7 @@ import (
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
 	core "k8s.io/kubernetes/pkg/apis/core"
 	networking "k8s.io/kubernetes/pkg/apis/networking"
 
@@ -72,8 +73,40 @@ type Service struct {
 	ServiceToPodPortForwardings []ServiceToPodPortForwarding
 	Replicas                    int
 	Networks                    []string
+	Links                       []string // Names of the services this service connects to
 	OnlyIngress                 bool
-	Daemon                      bool //Gets converted to DaemonSet
+	Daemon                      bool         //Gets converted to DaemonSet
+	Stateful                    bool         //Gets converted to StatefulSet
+	Schedule                    *Schedule    //Gets converted to CronJob
+	HealthCheck                 *HealthCheck //Used to infer the probes of the containers
+}
+
+// Schedule defines when a service is run as a scheduled job
+type Schedule struct {
+	Cron                       string // Schedule in cron format
+	ConcurrencyPolicy          batch.ConcurrencyPolicy
+	SuccessfulJobsHistoryLimit *int32
+	FailedJobsHistoryLimit     *int32
+}
+
+// HealthCheckType defines how the health of a service is checked
+type HealthCheckType string
+
+const (
+	// HTTPHealthCheckType checks the health using an HTTP GET request
+	HTTPHealthCheckType HealthCheckType = "http"
+	// PortHealthCheckType checks the health by opening a TCP connection to the port
+	PortHealthCheckType HealthCheckType = "port"
+	// ProcessHealthCheckType only checks if the process is running
+	ProcessHealthCheckType HealthCheckType = "process"
+)
+
+// HealthCheck defines how the health of a service can be checked
+type HealthCheck struct {
+	Type           HealthCheckType
+	Path           string // Path of the HTTP endpoint
+	Port           int32  // The first port of the container is used if it is 0
+	StartupTimeout int32  // Seconds the service can take to start
 }
 
 // ServiceToPodPortForwarding forwards a k8s service port to a k8s pod port
@@ -94,6 +127,8 @@ type ContainerImage struct {
 	ExposedPorts []int32  `yaml:"ports"`
 	UserID       int      `yaml:"userID"`
 	AccessedDirs []string `yaml:"accessedDirs"`
+	BaseImage    string   `yaml:"baseImage,omitempty"`   //Used to infer the language runtime of the image
+	MaxHeapSize  int64    `yaml:"maxHeapSize,omitempty"` //Max JVM heap size in bytes found in the image
 	Build        ContainerBuild
 }
 
@@ -194,8 +229,16 @@ func (service *Service) merge(nService Service) {
 		service.Replicas = nService.Replicas
 	}
 	service.Networks = common.MergeStringSlices(service.Networks, nService.Networks...)
+	service.Links = common.MergeStringSlices(service.Links, nService.Links...)
 	service.OnlyIngress = service.OnlyIngress && nService.OnlyIngress
 	service.Daemon = service.Daemon && nService.Daemon
+	service.Stateful = service.Stateful || nService.Stateful
+	if nService.Schedule != nil {
+		service.Schedule = nService.Schedule
+	}
+	if nService.HealthCheck != nil {
+		service.HealthCheck = nService.HealthCheck
+	}
 	for _, pf := range nService.ServiceToPodPortForwardings {
 		service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
 	}
@@ -264,6 +307,12 @@ func (c *ContainerImage) Merge(newc ContainerImage) bool {
 	}
 	c.ExposedPorts = common.MergeInt32Slices(c.ExposedPorts, newc.ExposedPorts)
 	c.AccessedDirs = common.MergeStringSlices(c.AccessedDirs, newc.AccessedDirs...)
+	if newc.BaseImage != "" {
+		c.BaseImage = newc.BaseImage
+	}
+	if newc.MaxHeapSize != 0 {
+		c.MaxHeapSize = newc.MaxHeapSize
+	}
 	c.Build.Merge(newc.Build)
 	return true
 }
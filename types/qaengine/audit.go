@@ -0,0 +1,132 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+)
+
+// QAAuditKind defines kind of QA Audit
+const QAAuditKind types.Kind = "QAAudit"
+
+// AnswerSource is the type of the place an answer came from
+type AnswerSource string
+
+const (
+	// DefaultAnswerSource is used when the default answer of the problem was used
+	DefaultAnswerSource AnswerSource = "default"
+	// PredeterminedAnswerSource is used when the problem was already solved when it was asked, for example a select problem with a single option
+	PredeterminedAnswerSource AnswerSource = "predetermined"
+	// PresetAnswerSource is used when the answer came from a preset
+	PresetAnswerSource AnswerSource = "preset"
+	// ProfileAnswerSource is used when the answer came from a config profile
+	ProfileAnswerSource AnswerSource = "profile"
+	// ConfigFileAnswerSource is used when the answer came from a config file
+	ConfigFileAnswerSource AnswerSource = "configfile"
+	// ConfigStringAnswerSource is used when the answer came from a config string given using --set-config
+	ConfigStringAnswerSource AnswerSource = "setconfig"
+	// CacheAnswerSource is used when the answer came from a QA cache file
+	CacheAnswerSource AnswerSource = "cache"
+	// SessionAnswerSource is used when the answer was given earlier in the same session
+	SessionAnswerSource AnswerSource = "session"
+	// InteractiveAnswerSource is used when the answer was given by the user interactively
+	InteractiveAnswerSource AnswerSource = "interactive"
+)
+
+// Audit stores the record of all the answers used during a transformation and where they came from
+type Audit struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             AuditSpec `yaml:"spec,omitempty"`
+}
+
+// AuditSpec stores the audit data
+type AuditSpec struct {
+	file string `yaml:"-"`
+	// Decisions stores the answers in the order the problems were first resolved
+	Decisions []AuditDecision `yaml:"decisions"`
+}
+
+// AuditDecision stores a single resolved problem along with the source of the answer
+type AuditDecision struct {
+	ID      string           `yaml:"id"`
+	Type    SolutionFormType `yaml:"type,omitempty"`
+	Desc    string           `yaml:"description,omitempty"`
+	Default interface{}      `yaml:"default,omitempty"`
+	Answer  interface{}      `yaml:"answer,omitempty"`
+	Source  AnswerSource     `yaml:"source"`
+	// SourceDetail stores the file, config string or engine that provided the answer
+	SourceDetail string `yaml:"sourceDetail,omitempty"`
+	// FellBackToDefault is true when no store or user provided the answer and the default was used
+	FellBackToDefault bool `yaml:"fellBackToDefault,omitempty"`
+}
+
+// NewAudit creates a new audit instance
+func NewAudit(file string) *Audit {
+	return &Audit{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(QAAuditKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		Spec: AuditSpec{
+			file: file,
+		},
+	}
+}
+
+// AddDecision records the source of the answer for a resolved problem.
+// A later decision for the same problem replaces the earlier one, unless it merely reuses an answer from the same session.
+func (audit *Audit) AddDecision(p Problem, source AnswerSource, sourceDetail string) error {
+	decision := AuditDecision{
+		ID:                p.ID,
+		Type:              p.Type,
+		Desc:              p.Desc,
+		Default:           p.Default,
+		Answer:            p.Answer,
+		Source:            source,
+		SourceDetail:      sourceDetail,
+		FellBackToDefault: source == DefaultAnswerSource,
+	}
+	if p.Type == PasswordSolutionFormType {
+		decision.Answer = nil
+	}
+	added := false
+	for i, d := range audit.Spec.Decisions {
+		if d.ID == p.ID {
+			if source != SessionAnswerSource {
+				audit.Spec.Decisions[i] = decision
+			}
+			added = true
+			break
+		}
+	}
+	if !added {
+		audit.Spec.Decisions = append(audit.Spec.Decisions, decision)
+	}
+	return audit.Write()
+}
+
+// Write writes audit to disk
+func (audit *Audit) Write() error {
+	err := common.WriteYaml(audit.Spec.file, audit)
+	if err != nil {
+		logrus.Warnf("Unable to write the QA audit : %s", err)
+	}
+	return err
+}
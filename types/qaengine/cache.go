@@ -107,6 +107,26 @@ func (cache *Cache) AddSolution(p Problem) error {
 	return nil
 }
 
+// RemoveSolution removes the solution for the problem from the cache
+func (cache *Cache) RemoveSolution(p Problem) error {
+	problems := []Problem{}
+	for _, cp := range cache.Spec.Problems {
+		if cp.ID == p.ID {
+			continue
+		}
+		problems = append(problems, cp)
+	}
+	if len(problems) == len(cache.Spec.Problems) {
+		return fmt.Errorf("the problem %+v was not found in the cache", p)
+	}
+	cache.Spec.Problems = problems
+	if err := cache.Write(); err != nil {
+		logrus.Errorf("Failed to write to the cache file. Error: %q", err)
+		return err
+	}
+	return nil
+}
+
 // GetSolution reads a solution for the problem
 func (cache *Cache) GetSolution(p Problem) (Problem, error) {
 	if p.Answer != nil {
@@ -122,6 +142,11 @@ func (cache *Cache) GetSolution(p Problem) (Problem, error) {
 	return p, fmt.Errorf("the problem %+v was not found in the cache", p)
 }
 
+// GetSolutionSource returns the source of the solution along with the path to the cache file
+func (cache *Cache) GetSolutionSource(Problem) (AnswerSource, string) {
+	return CacheAnswerSource, cache.Spec.file
+}
+
 func (cache *Cache) merge(c Cache) {
 	for _, p := range c.Spec.Problems {
 		found := false
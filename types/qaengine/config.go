@@ -20,11 +20,13 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path"
 	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
@@ -39,14 +41,26 @@ type nullLogBackend struct{}
 
 // Config stores the answers in a yaml file
 type Config struct {
+	presetFiles      []string
+	profiles         []ConfigProfile
 	configFiles      []string
 	configStrings    []string
 	yamlMap          mapT
 	writeYamlMap     mapT
+	loadedYamlMap    mapT // merged config before any answers were added in the session
+	layers           []configLayer
 	OutputPath       string
 	persistPasswords bool
 }
 
+// configLayer stores the contents of a single preset, config file or config string.
+// It is used to find out where an answer came from.
+type configLayer struct {
+	source  AnswerSource
+	detail  string
+	yamlMap mapT
+}
+
 var arrayIndexRegex = regexp.MustCompile(`^\[(\d+)\]$`)
 
 // Implement the Store interface
@@ -55,6 +69,27 @@ var arrayIndexRegex = regexp.MustCompile(`^\[(\d+)\]$`)
 func (c *Config) Load() (err error) {
 	logrus.Debugf("Config.Load")
 	yamlDatas := []string{}
+	c.layers = []configLayer{}
+	// presets are overridden by config profiles which are overridden by config files
+	for _, presetFile := range c.presetFiles {
+		yamlData, err := os.ReadFile(presetFile)
+		if err != nil {
+			logrus.Errorf("Failed to read the preset file %s Error: %q", presetFile, err)
+			continue
+		}
+		yamlDatas = append(yamlDatas, string(yamlData))
+		c.addLayer(PresetAnswerSource, presetFile, string(yamlData))
+	}
+	// profiles are ordered such that later profiles override earlier profiles
+	for _, profile := range c.profiles {
+		yamlData, err := profile.getConfigYAML()
+		if err != nil {
+			logrus.Errorf("Failed to read the config in the profile %s Error: %q", profile.Name, err)
+			continue
+		}
+		yamlDatas = append(yamlDatas, yamlData)
+		c.addLayer(ProfileAnswerSource, profile.Name, yamlData)
+	}
 	// config files specified later override earlier config files
 	for _, configFile := range c.configFiles {
 		yamlData, err := os.ReadFile(configFile)
@@ -63,6 +98,7 @@ func (c *Config) Load() (err error) {
 			continue
 		}
 		yamlDatas = append(yamlDatas, string(yamlData))
+		c.addLayer(ConfigFileAnswerSource, configFile, string(yamlData))
 	}
 	// config strings override config files
 	// config strings specified later override earlier config strings
@@ -83,36 +119,119 @@ func (c *Config) Load() (err error) {
 		}
 		logrus.Debugf("after parsing the yamlData is:\n%s", yamlData)
 		yamlDatas = append(yamlDatas, yamlData)
+		c.addLayer(ConfigStringAnswerSource, configString, yamlData)
 	}
 	c.yamlMap, err = MergeYAMLDatasIntoMap(yamlDatas)
 	c.writeYamlMap = mapT{}
+	if err != nil {
+		return err
+	}
+	c.loadedYamlMap, err = MergeYAMLDatasIntoMap(yamlDatas)
 	return err
 }
 
+func (c *Config) addLayer(source AnswerSource, detail, yamlData string) {
+	layerMap := mapT{}
+	if err := yaml.Unmarshal([]byte(yamlData), &layerMap); err != nil {
+		logrus.Debugf("Failed to parse the config %s Error: %q", detail, err)
+		return
+	}
+	c.layers = append(c.layers, configLayer{source: source, detail: detail, yamlMap: layerMap})
+}
+
+// GetSolutionSource returns the preset, config file or config string that provided the solution for the problem.
+// If none of them did, the solution was added to the config earlier in the same session.
+func (c *Config) GetSolutionSource(p Problem) (AnswerSource, string) {
+	keys := [][]string{}
+	if idx := strings.LastIndex(p.ID, common.Special); idx >= 0 && idx >= len(common.Delim) {
+		baseKey, lastKeySegment := p.ID[:idx-len(common.Delim)], p.ID[idx+len(common.Special):]
+		for _, option := range p.Options {
+			if _, subKeys, ok := c.getScoped(baseKey + common.Delim + option + lastKeySegment); ok {
+				keys = append(keys, subKeys)
+			}
+		}
+	} else if _, subKeys, ok := c.getScoped(p.ID); ok {
+		keys = append(keys, subKeys)
+	}
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		for _, subKeys := range keys {
+			if _, ok := getBySubKeys(subKeys, c.layers[i].yamlMap); ok {
+				return c.layers[i].source, c.layers[i].detail
+			}
+		}
+	}
+	return SessionAnswerSource, ""
+}
+
 func (c *Config) convertAnswer(p Problem, value interface{}) (Problem, error) {
 	p.Answer = value
 	return p, nil
 }
 
 func (c *Config) normalGetSolution(p Problem) (Problem, error) {
-	key := p.ID
-	value, ok := c.Get(key)
+	value, _, ok := c.getScoped(p.ID)
 	if ok {
 		return c.convertAnswer(p, value)
 	}
+	return p, fmt.Errorf("no answer found in the config for the problem:%+v", p)
+}
+
+// getScoped returns the value at the position given by the key in the config.
+// If the key is not present, it falls back to keys where one of the sub keys is replaced with a glob pattern
+// and finally to keys where one of the sub keys is replaced with the match all selector *
+// Precedence: exact key > glob pattern > match all selector
+// Example: Given move2kube.services."api-1".ports this tries move2kube.services."api-1".ports
+// then move2kube.services."api-*".ports and then move2kube.services.*.ports
+// When multiple glob patterns match, the most specific one (with the most non wildcard characters) is used.
+// The sub keys of the key that matched are also returned.
+func (c *Config) getScoped(key string) (value interface{}, matchedSubKeys []string, ok bool) {
+	subKeys := getSubKeys(key)
+	if value, ok := getBySubKeys(subKeys, c.yamlMap); ok {
+		return value, subKeys, true
+	}
+	// starting from 2nd last subkey replace with matching glob patterns
+	// Example: Given a.b.c.d.e this matches a.b.c.<glob>.e, then a.b.<glob>.d.e, then a.<glob>.c.d.e
+	for idx := len(subKeys) - 2; idx > 0; idx-- {
+		baseValue, ok := getBySubKeys(subKeys[:idx], c.yamlMap)
+		if !ok {
+			continue
+		}
+		baseValueMap, ok := baseValue.(mapT)
+		if !ok {
+			continue
+		}
+		bestPattern := ""
+		var bestValue interface{}
+		for pattern := range baseValueMap {
+			if !isGlobPattern(pattern) {
+				continue
+			}
+			if matched, err := path.Match(pattern, subKeys[idx]); err != nil || !matched {
+				continue
+			}
+			v, ok := getBySubKeys(replaceSubKey(subKeys, idx, pattern), c.yamlMap)
+			if !ok {
+				continue
+			}
+			if bestPattern == "" || isMoreSpecificGlobPattern(pattern, bestPattern) {
+				bestPattern = pattern
+				bestValue = v
+			}
+		}
+		if bestPattern != "" {
+			logrus.Debugf("Using the glob pattern %s to answer the key %s", bestPattern, key)
+			return bestValue, replaceSubKey(subKeys, idx, bestPattern), true
+		}
+	}
 	// starting from 2nd last subkey replace with match all selector *
 	// Example: Given a.b.c.d.e this matches a.b.c.*.e, then a.b.*.d.e, then a.*.c.d.e
-	subKeys := getSubKeys(key)
 	for idx := len(subKeys) - 2; idx > 0; idx-- {
-		baseKey := strings.Join(subKeys[:idx], common.Delim)
-		lastKeySegment := strings.Join(subKeys[idx+1:], common.Delim)
-		newKey := baseKey + common.Delim + common.MatchAll + common.Delim + lastKeySegment
-		v, ok := c.Get(newKey)
-		if ok {
-			return c.convertAnswer(p, v)
+		newSubKeys := replaceSubKey(subKeys, idx, common.MatchAll)
+		if v, ok := getBySubKeys(newSubKeys, c.yamlMap); ok {
+			return v, newSubKeys, true
 		}
 	}
-	return p, fmt.Errorf("no answer found in the config for the problem:%+v", p)
+	return nil, nil, false
 }
 
 func (c *Config) specialGetSolution(p Problem) (Problem, error) {
@@ -157,7 +276,7 @@ func (c *Config) specialGetSolution(p Problem) (Problem, error) {
 	for _, option := range p.Options {
 		isOptionSelected := true
 		newKey := baseKey + common.Delim + option + common.Delim + lastKeySegment
-		if newValue, ok := c.Get(newKey); ok {
+		if newValue, _, ok := c.getScoped(newKey); ok {
 			isOptionSelected, ok = newValue.(bool)
 			if !ok {
 				return p, fmt.Errorf("error occurred in special case for multiselect problems. Expected key %s to have boolean value. Actual value is %v of type %T", newKey, newValue, newValue)
@@ -251,15 +370,59 @@ func (c *Config) AddSolution(p Problem) error {
 	return err
 }
 
+// RemoveSolution removes the answer for the problem from the config written out.
+// The presets, profiles, config files and config strings are not changed, so the answer
+// falls back to the one loaded from them, if any.
+func (c *Config) RemoveSolution(p Problem) error {
+	logrus.Debugf("Config.RemoveSolution the problem is:\n%+v", p)
+	key := p.ID
+	idx := strings.LastIndex(key, common.Special)
+	if idx < 0 {
+		c.removeSessionAnswer(key)
+	} else {
+		baseKey, lastKeySegment := key[:idx-len(common.Delim)], key[idx+len(common.Special)+len(common.Delim):]
+		if baseKey == "" {
+			return fmt.Errorf("failed to remove the problem\n%+v\nfrom the config. The base key is empty", p)
+		}
+		for _, option := range p.Options {
+			newKey := baseKey + common.Delim + option + common.Delim + lastKeySegment
+			c.removeSessionAnswer(newKey)
+		}
+	}
+	err := c.Write()
+	if err != nil {
+		logrus.Errorf("Failed to write to the config file. Error: %q", err)
+	}
+	return err
+}
+
+// removeSessionAnswer removes the answer added in the session and restores the loaded answer in the merged config
+func (c *Config) removeSessionAnswer(key string) {
+	del(key, c.writeYamlMap)
+	if value, ok := get(key, c.loadedYamlMap); ok {
+		set(key, deepcopy.DeepCopy(value), c.yamlMap)
+		return
+	}
+	del(key, c.yamlMap)
+}
+
+// GetMergedConfig returns the config after merging all the presets, profiles, config files and config strings
+func (c *Config) GetMergedConfig() map[string]interface{} {
+	return c.yamlMap
+}
+
 // Get returns the value at the position given by the key in the config
 func (c *Config) Get(key string) (value interface{}, ok bool) {
 	return get(key, c.yamlMap)
 }
 
-// NewConfig creates a new config instance given config strings and paths to config files
-func NewConfig(outputPath string, configStrings, configFiles []string, persistPasswords bool) (config *Config) {
+// NewConfig creates a new config instance given config strings, paths to preset and config files and config profiles.
+// The profiles should be ordered from the lowest to the highest precedence.
+func NewConfig(outputPath string, configStrings, presetFiles []string, profiles []ConfigProfile, configFiles []string, persistPasswords bool) (config *Config) {
 	logrus.Debug("NewConfig create a new config")
 	return &Config{
+		presetFiles:      presetFiles,
+		profiles:         profiles,
 		configFiles:      configFiles,
 		configStrings:    configStrings,
 		OutputPath:       outputPath,
@@ -282,7 +445,8 @@ func getPrinterAndEvaluator(buffer *bytes.Buffer) (yqlib.Printer, yqlib.StreamEv
 // GenerateYAMLFromExpression generates yaml string from yq syntax expression
 // Example: The expression .foo.bar="abc" gives:
 // foo:
-//   bar: abc
+//
+//	bar: abc
 func GenerateYAMLFromExpression(expr string) (string, error) {
 	logrus.Debugf("GenerateYAMLFromExpression parsing the string [%s]", expr)
 	logging.SetBackend(new(nullLogBackend))
@@ -365,7 +529,10 @@ func getIndex(key string) (int, bool) {
 }
 
 func get(key string, config interface{}) (value interface{}, ok bool) {
-	subKeys := getSubKeys(key)
+	return getBySubKeys(getSubKeys(key), config)
+}
+
+func getBySubKeys(subKeys []string, config interface{}) (value interface{}, ok bool) {
 	value = config
 	for _, subKey := range subKeys {
 		valueMap, ok := value.(mapT)
@@ -421,6 +588,42 @@ func set(key string, newValue interface{}, config mapT) {
 	config[lastSubKey] = newValue
 }
 
+func del(key string, config mapT) {
+	subKeys := getSubKeys(key)
+	lastIdx := len(subKeys) - 1
+	for _, subKey := range subKeys[:lastIdx] {
+		valueMap, ok := config[subKey].(mapT)
+		if !ok {
+			return
+		}
+		config = valueMap
+	}
+	delete(config, subKeys[lastIdx])
+}
+
+// isGlobPattern returns true if the sub key is a glob pattern like api-* other than the match all selector *
+func isGlobPattern(subKey string) bool {
+	return subKey != common.MatchAll && strings.ContainsAny(subKey, "*?[")
+}
+
+// isMoreSpecificGlobPattern returns true if pattern1 has more non wildcard characters than pattern2.
+// Ties are broken lexicographically to keep the result deterministic.
+func isMoreSpecificGlobPattern(pattern1, pattern2 string) bool {
+	literalLen := func(pattern string) int {
+		return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+	}
+	if literalLen(pattern1) != literalLen(pattern2) {
+		return literalLen(pattern1) > literalLen(pattern2)
+	}
+	return pattern1 < pattern2
+}
+
+func replaceSubKey(subKeys []string, idx int, subKey string) []string {
+	newSubKeys := append([]string{}, subKeys...)
+	newSubKeys[idx] = subKey
+	return newSubKeys
+}
+
 func getSubKeys(key string) []string {
 	unStrippedSubKeys := common.SplitOnDotExpectInsideQuotes(key) // assuming delimiter is dot
 	subKeys := []string{}
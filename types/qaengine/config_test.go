@@ -0,0 +1,177 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestConfigScopedAnswers(t *testing.T) {
+	configData := `move2kube:
+  services:
+    "*":
+      replicas: 1
+      enable: false
+    "api-*":
+      replicas: 2
+      enable: true
+    "api-v*":
+      replicas: 3
+    api-exact:
+      replicas: 4
+`
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(configData), 0644); err != nil {
+		t.Fatalf("Failed to write the config file. Error: %q", err)
+	}
+	config := qaengine.NewConfig("", nil, nil, nil, []string{configPath}, false)
+	if err := config.Load(); err != nil {
+		t.Fatalf("Failed to load the config. Error: %q", err)
+	}
+	testcases := []struct {
+		service string
+		want    int
+	}{
+		{service: "api-exact", want: 4},
+		{service: "api-v1", want: 3},
+		{service: "api-1", want: 2},
+		{service: "web", want: 1},
+	}
+	for _, testcase := range testcases {
+		t.Run("replicas for "+testcase.service, func(t *testing.T) {
+			prob, err := qaengine.NewInputProblem(`move2kube.services."`+testcase.service+`".replicas`, "Replicas", nil, "")
+			if err != nil {
+				t.Fatalf("Failed to create the problem. Error: %q", err)
+			}
+			prob, err = config.GetSolution(prob)
+			if err != nil {
+				t.Fatalf("Failed to get the solution. Error: %q", err)
+			}
+			if prob.Answer != testcase.want {
+				t.Fatalf("Expected the answer %d. Actual: %+v", testcase.want, prob.Answer)
+			}
+		})
+	}
+	t.Run("services to enable", func(t *testing.T) {
+		prob, err := qaengine.NewMultiSelectProblem("move2kube.services.[].enable", "Services", nil, nil, []string{"api-1", "web", "api-exact"})
+		if err != nil {
+			t.Fatalf("Failed to create the problem. Error: %q", err)
+		}
+		prob, err = config.GetSolution(prob)
+		if err != nil {
+			t.Fatalf("Failed to get the solution. Error: %q", err)
+		}
+		answer, ok := prob.Answer.([]string)
+		if !ok || len(answer) != 2 || answer[0] != "api-1" || answer[1] != "api-exact" {
+			t.Fatalf("Expected the services api-1 and api-exact to be selected. Actual: %+v", prob.Answer)
+		}
+	})
+}
+
+func TestConfigSolutionSource(t *testing.T) {
+	tempDir := t.TempDir()
+	presetPath := filepath.Join(tempDir, "preset.yaml")
+	if err := os.WriteFile(presetPath, []byte("move2kube:\n  key1: preset\n  key2: preset\n"), 0644); err != nil {
+		t.Fatalf("Failed to write the preset file. Error: %q", err)
+	}
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("move2kube:\n  key2: file\n  key3: file\n"), 0644); err != nil {
+		t.Fatalf("Failed to write the config file. Error: %q", err)
+	}
+	configString := `move2kube.key3="string"`
+	config := qaengine.NewConfig("", []string{configString}, []string{presetPath}, nil, []string{configPath}, false)
+	if err := config.Load(); err != nil {
+		t.Fatalf("Failed to load the config. Error: %q", err)
+	}
+	testcases := []struct {
+		key        string
+		wantSource qaengine.AnswerSource
+		wantDetail string
+	}{
+		{key: "move2kube.key1", wantSource: qaengine.PresetAnswerSource, wantDetail: presetPath},
+		{key: "move2kube.key2", wantSource: qaengine.ConfigFileAnswerSource, wantDetail: configPath},
+		{key: "move2kube.key3", wantSource: qaengine.ConfigStringAnswerSource, wantDetail: "." + configString},
+		{key: "move2kube.key4", wantSource: qaengine.SessionAnswerSource, wantDetail: ""},
+	}
+	for _, testcase := range testcases {
+		prob, err := qaengine.NewInputProblem(testcase.key, "Test", nil, "")
+		if err != nil {
+			t.Fatalf("Failed to create the problem. Error: %q", err)
+		}
+		source, detail := config.GetSolutionSource(prob)
+		if source != testcase.wantSource || detail != testcase.wantDetail {
+			t.Fatalf("Expected the source of %s to be %s %s . Actual: %s %s", testcase.key, testcase.wantSource, testcase.wantDetail, source, detail)
+		}
+	}
+}
+
+func TestConfigRemoveSolution(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("move2kube:\n  key1: file\n"), 0644); err != nil {
+		t.Fatalf("Failed to write the config file. Error: %q", err)
+	}
+	outputPath := filepath.Join(tempDir, "m2kconfig.yaml")
+	config := qaengine.NewConfig(outputPath, nil, nil, nil, []string{configPath}, false)
+	if err := config.Load(); err != nil {
+		t.Fatalf("Failed to load the config. Error: %q", err)
+	}
+	probs := []qaengine.Problem{}
+	for _, key := range []string{"move2kube.key1", "move2kube.key2"} {
+		prob, err := qaengine.NewInputProblem(key, "Test", nil, "")
+		if err != nil {
+			t.Fatalf("Failed to create the problem. Error: %q", err)
+		}
+		prob.Answer = "session"
+		if err := config.AddSolution(prob); err != nil {
+			t.Fatalf("Failed to add the solution. Error: %q", err)
+		}
+		if err := config.RemoveSolution(prob); err != nil {
+			t.Fatalf("Failed to remove the solution. Error: %q", err)
+		}
+		prob.Answer = nil
+		probs = append(probs, prob)
+	}
+	t.Run("answer from a config file", func(t *testing.T) {
+		prob, err := config.GetSolution(probs[0])
+		if err != nil {
+			t.Fatalf("Failed to get the solution. Error: %q", err)
+		}
+		if prob.Answer != "file" {
+			t.Fatalf("Expected the answer from the config file. Actual: %+v", prob.Answer)
+		}
+	})
+	t.Run("answer added in the session", func(t *testing.T) {
+		if prob, err := config.GetSolution(probs[1]); err == nil {
+			t.Fatalf("Expected the answer to be removed. Actual: %+v", prob.Answer)
+		}
+	})
+	t.Run("written config", func(t *testing.T) {
+		written, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Failed to read the written config. Error: %q", err)
+		}
+		if strings.Contains(string(written), "key") {
+			t.Fatalf("Expected the answers to be removed from the written config. Actual:\n%s", written)
+		}
+	})
+}
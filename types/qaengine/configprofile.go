@@ -0,0 +1,125 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigProfileKind defines kind of config profile
+const ConfigProfileKind types.Kind = "ConfigProfile"
+
+// ConfigProfile is a named set of answers that can build on other profiles.
+// Example: an app profile can extend a team profile which in turn extends an org wide profile.
+type ConfigProfile struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             ConfigProfileSpec `yaml:"spec,omitempty"`
+}
+
+// ConfigProfileSpec stores the config profile data
+type ConfigProfileSpec struct {
+	// Extends contains the names of the profiles this profile builds on.
+	// Later profiles override earlier ones and this profile overrides all of them.
+	Extends []string `yaml:"extends,omitempty"`
+	// Config contains the answers in the same format as a config file
+	Config mapT `yaml:"config,omitempty"`
+}
+
+// IsConfigProfileFile returns true if the yaml file at the path is a config profile
+func IsConfigProfileFile(path string) bool {
+	preamble := types.TypeMeta{}
+	return common.ReadYaml(path, &preamble) == nil && preamble.Kind == string(ConfigProfileKind)
+}
+
+// ReadConfigProfiles reads the config profiles from the given files and directories.
+// Profiles read later replace earlier profiles with the same name.
+func ReadConfigProfiles(paths ...string) (map[string]ConfigProfile, error) {
+	profiles := map[string]ConfigProfile{}
+	for _, path := range paths {
+		profilePaths := []string{path}
+		if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+			profilePaths, err = common.GetYamlsWithTypeMeta(path, string(ConfigProfileKind))
+			if err != nil {
+				return profiles, fmt.Errorf("failed to look for config profiles in %s . Error: %q", path, err)
+			}
+		}
+		for _, profilePath := range profilePaths {
+			profile := ConfigProfile{}
+			if err := common.ReadMove2KubeYaml(profilePath, &profile); err != nil {
+				logrus.Errorf("Failed to read the config profile at path %s Error: %q", profilePath, err)
+				continue
+			}
+			if profile.Name == "" {
+				logrus.Errorf("Ignoring the config profile at path %s since it has no name", profilePath)
+				continue
+			}
+			if _, ok := profiles[profile.Name]; ok {
+				logrus.Warnf("The config profile %s at path %s replaces an earlier profile with the same name", profile.Name, profilePath)
+			}
+			profiles[profile.Name] = profile
+		}
+	}
+	return profiles, nil
+}
+
+// ResolveConfigProfile returns the profile along with all the profiles it extends.
+// They are ordered from the lowest to the highest precedence, so the named profile is the last one.
+func ResolveConfigProfile(name string, profiles map[string]ConfigProfile) ([]ConfigProfile, error) {
+	resolved := []ConfigProfile{}
+	visited := map[string]bool{}
+	var resolve func(name string, path []string) error
+	resolve = func(name string, path []string) error {
+		if common.IsStringPresent(path, name) {
+			return fmt.Errorf("the config profiles have a cycle: %v", append(path, name))
+		}
+		if visited[name] {
+			return nil
+		}
+		profile, ok := profiles[name]
+		if !ok {
+			return fmt.Errorf("the config profile %s was not found", name)
+		}
+		for _, parent := range profile.Spec.Extends {
+			if err := resolve(parent, append(path, name)); err != nil {
+				return err
+			}
+		}
+		visited[name] = true
+		resolved = append(resolved, profile)
+		return nil
+	}
+	if err := resolve(name, []string{}); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// getConfigYAML returns the answers in the profile as a yaml string
+func (profile *ConfigProfile) getConfigYAML() (string, error) {
+	if profile.Spec.Config == nil {
+		return "", nil
+	}
+	yamlBytes, err := yaml.Marshal(profile.Spec.Config)
+	return string(yamlBytes), err
+}
@@ -0,0 +1,70 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine_test
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/types"
+	"github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestResolveConfigProfile(t *testing.T) {
+	newProfile := func(name string, extends ...string) qaengine.ConfigProfile {
+		return qaengine.ConfigProfile{
+			ObjectMeta: types.ObjectMeta{Name: name},
+			Spec:       qaengine.ConfigProfileSpec{Extends: extends},
+		}
+	}
+	profiles := map[string]qaengine.ConfigProfile{
+		"org":      newProfile("org"),
+		"security": newProfile("security", "org"),
+		"team":     newProfile("team", "org"),
+		"app":      newProfile("app", "team", "security"),
+		"cycle1":   newProfile("cycle1", "cycle2"),
+		"cycle2":   newProfile("cycle2", "cycle1"),
+		"missing":  newProfile("missing", "unknown"),
+	}
+
+	t.Run("parents come before children and are included only once", func(t *testing.T) {
+		resolved, err := qaengine.ResolveConfigProfile("app", profiles)
+		if err != nil {
+			t.Fatalf("Failed to resolve the profile. Error: %q", err)
+		}
+		want := []string{"org", "team", "security", "app"}
+		if len(resolved) != len(want) {
+			t.Fatalf("Expected %d profiles. Actual: %+v", len(want), resolved)
+		}
+		for i, name := range want {
+			if resolved[i].Name != name {
+				t.Fatalf("Expected the profile %s at index %d . Actual: %s", name, i, resolved[i].Name)
+			}
+		}
+	})
+
+	t.Run("cycles are reported", func(t *testing.T) {
+		if _, err := qaengine.ResolveConfigProfile("cycle1", profiles); err == nil {
+			t.Fatalf("Expected an error for profiles that extend each other")
+		}
+	})
+
+	t.Run("missing profiles are reported", func(t *testing.T) {
+		if _, err := qaengine.ResolveConfigProfile("missing", profiles); err == nil {
+			t.Fatalf("Expected an error for a profile that extends an unknown profile")
+		}
+	})
+}
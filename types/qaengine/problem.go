@@ -109,7 +109,7 @@ func InterfaceToArray(ansI interface{}, problemType SolutionFormType) (ans []str
 
 // ArrayToInterface converts the answer array to interface
 func ArrayToInterface(ans []string, problemType SolutionFormType) (ansI interface{}, err error) {
-	if ansI == nil {
+	if ans == nil {
 		return nil, nil
 	}
 	switch problemType {
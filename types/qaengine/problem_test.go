@@ -0,0 +1,61 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestArrayToInterface(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ans         []string
+		problemType qaengine.SolutionFormType
+		want        interface{}
+		wantErr     bool
+	}{
+		{name: "nil answer", ans: nil, problemType: qaengine.InputSolutionFormType, want: nil},
+		{name: "input", ans: []string{"foo"}, problemType: qaengine.InputSolutionFormType, want: "foo"},
+		{name: "empty input", ans: []string{}, problemType: qaengine.InputSolutionFormType, want: ""},
+		{name: "select", ans: []string{"bar"}, problemType: qaengine.SelectSolutionFormType, want: "bar"},
+		{name: "confirm", ans: []string{"true"}, problemType: qaengine.ConfirmSolutionFormType, want: true},
+		{name: "empty confirm", ans: []string{}, problemType: qaengine.ConfirmSolutionFormType, want: false},
+		{name: "invalid confirm", ans: []string{"maybe"}, problemType: qaengine.ConfirmSolutionFormType, wantErr: true},
+		{name: "multi select", ans: []string{"a", "b"}, problemType: qaengine.MultiSelectSolutionFormType, want: []string{"a", "b"}},
+		{name: "unsupported type", ans: []string{"a"}, problemType: qaengine.SolutionFormType("Unknown"), wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := qaengine.ArrayToInterface(tc.ans, tc.problemType)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error. Actual answer: %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to convert the answer. Error: %q", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("Wrong answer. Difference:\n%s", diff)
+			}
+		})
+	}
+}
@@ -23,7 +23,9 @@ package qaengine
 type Store interface {
 	Load() error
 	GetSolution(Problem) (Problem, error)
+	GetSolutionSource(Problem) (AnswerSource, string)
 
 	Write() error
 	AddSolution(p Problem) error
+	RemoveSolution(p Problem) error
 }
@@ -14,6 +14,7 @@
  *  limitations under the License.
  */
 
+
 //
 //Copyright IBM Corporation 2021
 //
@@ -21,7 +22,7 @@
 //you may not use this file except in compliance with the License.
 //You may obtain a copy of the License at
 //
-//http://www.apache.org/licenses/LICENSE-2.0
+// http://www.apache.org/licenses/LICENSE-2.0
 //
 //Unless required by applicable law or agreed to in writing, software
 //distributed under the License is distributed on an "AS IS" BASIS,
@@ -29,11 +30,12 @@
 //See the License for the specific language governing permissions and
 //limitations under the License.
 
+// If this file is updated, protoc needs to be installed and the following command needs to be executed again in this directory
 // protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative fetchanswer.proto
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
+// 	protoc-gen-go v1.27.1
 // 	protoc        v3.17.3
 // source: fetchanswer.proto
 
@@ -187,6 +189,126 @@ func (x *Answer) GetAnswer() []string {
 	return nil
 }
 
+type PendingProblem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Problem *Problem `protobuf:"bytes,1,opt,name=problem,proto3" json:"problem,omitempty"`
+	// deadline is the time in unix seconds before which the solution has to be sent. 0 means there is no deadline.
+	Deadline int64 `protobuf:"varint,2,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	// error contains the reason the previous solution for this problem was rejected, if any.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *PendingProblem) Reset() {
+	*x = PendingProblem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fetchanswer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PendingProblem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingProblem) ProtoMessage() {}
+
+func (x *PendingProblem) ProtoReflect() protoreflect.Message {
+	mi := &file_fetchanswer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingProblem.ProtoReflect.Descriptor instead.
+func (*PendingProblem) Descriptor() ([]byte, []int) {
+	return file_fetchanswer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PendingProblem) GetProblem() *Problem {
+	if x != nil {
+		return x.Problem
+	}
+	return nil
+}
+
+func (x *PendingProblem) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *PendingProblem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Solution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Answer []string `protobuf:"bytes,2,rep,name=answer,proto3" json:"answer,omitempty"`
+}
+
+func (x *Solution) Reset() {
+	*x = Solution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fetchanswer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Solution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Solution) ProtoMessage() {}
+
+func (x *Solution) ProtoReflect() protoreflect.Message {
+	mi := &file_fetchanswer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Solution.ProtoReflect.Descriptor instead.
+func (*Solution) Descriptor() ([]byte, []int) {
+	return file_fetchanswer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Solution) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Solution) GetAnswer() []string {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
 var File_fetchanswer_proto protoreflect.FileDescriptor
 
 var file_fetchanswer_proto_rawDesc = []byte{
@@ -203,15 +325,30 @@ var file_fetchanswer_proto_rawDesc = []byte{
 	0x07, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
 	0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x22, 0x20, 0x0a, 0x06, 0x41, 0x6e, 0x73, 0x77, 0x65,
 	0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x32, 0x3c, 0x0a, 0x08, 0x51, 0x41, 0x45,
-	0x6e, 0x67, 0x69, 0x6e, 0x65, 0x12, 0x30, 0x0a, 0x0b, 0x46, 0x65, 0x74, 0x63, 0x68, 0x41, 0x6e,
-	0x73, 0x77, 0x65, 0x72, 0x12, 0x0f, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x72,
-	0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x1a, 0x0e, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x41,
-	0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x00, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x6f, 0x6e, 0x76, 0x65, 0x79, 0x6f, 0x72, 0x2f, 0x6d,
-	0x6f, 0x76, 0x65, 0x32, 0x6b, 0x75, 0x62, 0x65, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2f, 0x71,
-	0x61, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2f, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x6d, 0x0a, 0x0e, 0x50, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x29, 0x0a, 0x07, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x71,
+	0x61, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x52, 0x07, 0x70,
+	0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69,
+	0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69,
+	0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x32, 0x0a, 0x08, 0x53, 0x6f, 0x6c, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x32, 0x3c, 0x0a, 0x08,
+	0x51, 0x41, 0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x12, 0x30, 0x0a, 0x0b, 0x46, 0x65, 0x74, 0x63,
+	0x68, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x12, 0x0f, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63,
+	0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x1a, 0x0e, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70,
+	0x63, 0x2e, 0x41, 0x6e, 0x73, 0x77, 0x65, 0x72, 0x22, 0x00, 0x32, 0x52, 0x0a, 0x0e, 0x51, 0x41,
+	0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x40, 0x0a, 0x0e,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x10,
+	0x2e, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e,
+	0x1a, 0x16, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x2e, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x35,
+	0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x6f, 0x6e,
+	0x76, 0x65, 0x79, 0x6f, 0x72, 0x2f, 0x6d, 0x6f, 0x76, 0x65, 0x32, 0x6b, 0x75, 0x62, 0x65, 0x2f,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x2f, 0x71, 0x61, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2f, 0x71,
+	0x61, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -226,19 +363,24 @@ func file_fetchanswer_proto_rawDescGZIP() []byte {
 	return file_fetchanswer_proto_rawDescData
 }
 
-var file_fetchanswer_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_fetchanswer_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_fetchanswer_proto_goTypes = []interface{}{
-	(*Problem)(nil), // 0: qagrpc.Problem
-	(*Answer)(nil),  // 1: qagrpc.Answer
+	(*Problem)(nil),        // 0: qagrpc.Problem
+	(*Answer)(nil),         // 1: qagrpc.Answer
+	(*PendingProblem)(nil), // 2: qagrpc.PendingProblem
+	(*Solution)(nil),       // 3: qagrpc.Solution
 }
 var file_fetchanswer_proto_depIdxs = []int32{
-	0, // 0: qagrpc.QAEngine.FetchAnswer:input_type -> qagrpc.Problem
-	1, // 1: qagrpc.QAEngine.FetchAnswer:output_type -> qagrpc.Answer
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: qagrpc.PendingProblem.problem:type_name -> qagrpc.Problem
+	0, // 1: qagrpc.QAEngine.FetchAnswer:input_type -> qagrpc.Problem
+	3, // 2: qagrpc.QAEngineStream.StreamProblems:input_type -> qagrpc.Solution
+	1, // 3: qagrpc.QAEngine.FetchAnswer:output_type -> qagrpc.Answer
+	2, // 4: qagrpc.QAEngineStream.StreamProblems:output_type -> qagrpc.PendingProblem
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_fetchanswer_proto_init() }
@@ -271,6 +413,30 @@ func file_fetchanswer_proto_init() {
 				return nil
 			}
 		}
+		file_fetchanswer_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PendingProblem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fetchanswer_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Solution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -278,9 +444,9 @@ func file_fetchanswer_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_fetchanswer_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_fetchanswer_proto_goTypes,
 		DependencyIndexes: file_fetchanswer_proto_depIdxs,
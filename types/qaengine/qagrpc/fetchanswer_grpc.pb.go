@@ -14,6 +14,7 @@
  *  limitations under the License.
  */
 
+
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 
 package qagrpc
@@ -115,3 +116,121 @@ var QAEngine_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "fetchanswer.proto",
 }
+
+// QAEngineStreamClient is the client API for QAEngineStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QAEngineStreamClient interface {
+	StreamProblems(ctx context.Context, opts ...grpc.CallOption) (QAEngineStream_StreamProblemsClient, error)
+}
+
+type qAEngineStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQAEngineStreamClient(cc grpc.ClientConnInterface) QAEngineStreamClient {
+	return &qAEngineStreamClient{cc}
+}
+
+func (c *qAEngineStreamClient) StreamProblems(ctx context.Context, opts ...grpc.CallOption) (QAEngineStream_StreamProblemsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &QAEngineStream_ServiceDesc.Streams[0], "/qagrpc.QAEngineStream/StreamProblems", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &qAEngineStreamStreamProblemsClient{stream}
+	return x, nil
+}
+
+type QAEngineStream_StreamProblemsClient interface {
+	Send(*Solution) error
+	Recv() (*PendingProblem, error)
+	grpc.ClientStream
+}
+
+type qAEngineStreamStreamProblemsClient struct {
+	grpc.ClientStream
+}
+
+func (x *qAEngineStreamStreamProblemsClient) Send(m *Solution) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *qAEngineStreamStreamProblemsClient) Recv() (*PendingProblem, error) {
+	m := new(PendingProblem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QAEngineStreamServer is the server API for QAEngineStream service.
+// All implementations must embed UnimplementedQAEngineStreamServer
+// for forward compatibility
+type QAEngineStreamServer interface {
+	StreamProblems(QAEngineStream_StreamProblemsServer) error
+	mustEmbedUnimplementedQAEngineStreamServer()
+}
+
+// UnimplementedQAEngineStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedQAEngineStreamServer struct {
+}
+
+func (UnimplementedQAEngineStreamServer) StreamProblems(QAEngineStream_StreamProblemsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProblems not implemented")
+}
+func (UnimplementedQAEngineStreamServer) mustEmbedUnimplementedQAEngineStreamServer() {}
+
+// UnsafeQAEngineStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QAEngineStreamServer will
+// result in compilation errors.
+type UnsafeQAEngineStreamServer interface {
+	mustEmbedUnimplementedQAEngineStreamServer()
+}
+
+func RegisterQAEngineStreamServer(s grpc.ServiceRegistrar, srv QAEngineStreamServer) {
+	s.RegisterService(&QAEngineStream_ServiceDesc, srv)
+}
+
+func _QAEngineStream_StreamProblems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(QAEngineStreamServer).StreamProblems(&qAEngineStreamStreamProblemsServer{stream})
+}
+
+type QAEngineStream_StreamProblemsServer interface {
+	Send(*PendingProblem) error
+	Recv() (*Solution, error)
+	grpc.ServerStream
+}
+
+type qAEngineStreamStreamProblemsServer struct {
+	grpc.ServerStream
+}
+
+func (x *qAEngineStreamStreamProblemsServer) Send(m *PendingProblem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *qAEngineStreamStreamProblemsServer) Recv() (*Solution, error) {
+	m := new(Solution)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QAEngineStream_ServiceDesc is the grpc.ServiceDesc for QAEngineStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QAEngineStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "qagrpc.QAEngineStream",
+	HandlerType: (*QAEngineStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProblems",
+			Handler:       _QAEngineStream_StreamProblems_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "fetchanswer.proto",
+}
@@ -27,6 +27,7 @@ type SpringBootConfig struct {
 	SpringBootVersion  string    `yaml:"springBootVersion,omitempty" json:"springBootVersion,omitempty"`
 	SpringBootAppName  string    `yaml:"springBootAppName,omitempty" json:"springBootAppName,omitempty"`
 	SpringBootProfiles *[]string `yaml:"springBootProfiles,omitempty" json:"springBootProfiles,omitempty"`
+	SpringBootActuator bool      `yaml:"springBootActuator,omitempty" json:"springBootActuator,omitempty"`
 }
 
 const (
@@ -54,6 +55,7 @@ func (sb *SpringBootConfig) Merge(newsbobj interface{}) bool {
 	if sb.SpringBootVersion != newsbptr.SpringBootVersion {
 		logrus.Errorf("Incompatible springboot version found during merge for app %s", sb.SpringBootAppName)
 	}
+	sb.SpringBootActuator = sb.SpringBootActuator || newsbptr.SpringBootActuator
 	*sb.SpringBootProfiles = common.MergeStringSlices(*sb.SpringBootProfiles, *newsbptr.SpringBootProfiles...)
 	return true
 }
@@ -46,6 +46,23 @@ type TransformerSpec struct {
 	OverrideSelector   labels.Selector                        `yaml:"-" json:"-"`
 	TemplatesDir       string                                 `yaml:"templates" json:"templates"` // Relative to yaml directory or working directory in image
 	Config             interface{}                            `yaml:"config" json:"config"`
+	Env                []EnvVar                               `yaml:"env,omitempty" json:"env,omitempty"`
+	EnvFrom            []EnvFromSource                        `yaml:"envFrom,omitempty" json:"envFrom,omitempty"`
+}
+
+// EnvVar stores an environment variable set in the environment of the transformer
+type EnvVar struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// EnvFromSource stores an environment variable whose value is fetched when the transformer is initialized.
+// Exactly one of the sources has to be set. The value is treated as a secret and is redacted from the logs.
+type EnvFromSource struct {
+	Name     string `yaml:"name" json:"name"`
+	HostEnv  string `yaml:"hostEnv,omitempty" json:"hostEnv,omitempty"`   // Name of the environment variable of move2kube which has the value
+	File     string `yaml:"file,omitempty" json:"file,omitempty"`         // Path of the file with the value, relative to the transformer yaml
+	Password bool   `yaml:"password,omitempty" json:"password,omitempty"` // Ask for the value as a password
 }
 
 // DirectoryDetect stores the config on how to iterate over the directories